@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+// checkFlag verifies the output directory is up to date instead of writing to it
+const checkFlag = "--check"
+
+// runCheck compiles in memory and compares the result with the output directory without
+// writing anything, listing the differing and missing files. It returns the exit code.
+func runCheck(config compile.MorpheCompileConfig, stdout, stderr io.Writer) int {
+	stale, err := compile.StaleOutputFiles(config)
+	if err != nil {
+		fmt.Fprintln(stderr, "Compilation failed:", err)
+		return compileExitCode(err)
+	}
+	if len(stale) == 0 {
+		fmt.Fprintln(stdout, "Generated files are up to date")
+		return ExitSuccess
+	}
+
+	fmt.Fprintf(stderr, "Generated files are out of date in %s:\n", config.OutputPath)
+	for _, file := range stale {
+		fmt.Fprintf(stderr, "  - %s\n", file)
+	}
+	return ExitStale
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// configFileFlag names a JSON file holding the config, merged under any inline config
+const configFileFlag = "--config-file"
+
+// configFileKey is the inline config key naming a config file, as an alternative to the flag
+const configFileKey = "configFile"
+
+// ConfigFileError is returned when the config file can't be read or parsed
+type ConfigFileError struct {
+	Path string // Absolute path of the config file
+	Err  error
+}
+
+func (e *ConfigFileError) Error() string {
+	return fmt.Sprintf("failed to load config file %s: %v", e.Path, e.Err)
+}
+
+func (e *ConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+// resolveConfigFile loads the config file named by the --config-file flag or the inline
+// configFile key and merges the inline config over it, returning the merged JSON config. Relative
+// paths resolve against the working directory. Without a config file the inline config is
+// returned unchanged.
+func resolveConfigFile(rawConfig string, configFile string) (string, error) {
+	inline := map[string]any{}
+	var inlineErr error
+	if rawConfig != "" {
+		inlineErr = decodeConfigJSON([]byte(rawConfig), &inline)
+	}
+	if configFile == "" {
+		if inlineErr != nil {
+			// Reported with the expected format when the config is parsed
+			return rawConfig, nil
+		}
+		configFile, _ = inline[configFileKey].(string)
+	}
+	if configFile == "" {
+		return rawConfig, nil
+	}
+	delete(inline, configFileKey)
+
+	path, err := filepath.Abs(configFile)
+	if err != nil {
+		return "", &ConfigFileError{Path: configFile, Err: err}
+	}
+	if inlineErr != nil {
+		return "", &ConfigFileError{Path: path, Err: fmt.Errorf("invalid inline config JSON: %w", inlineErr)}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", &ConfigFileError{Path: path, Err: err}
+	}
+	var fileConfig map[string]any
+	if err := decodeConfigJSON(content, &fileConfig); err != nil {
+		return "", &ConfigFileError{Path: path, Err: fmt.Errorf("invalid config JSON: %w", err)}
+	}
+
+	merged, err := json.Marshal(mergeConfigValues(fileConfig, inline))
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
+
+// mergeConfigValues merges the overrides into the base config: nested objects are merged key by
+// key, any other override value replaces the base value
+func mergeConfigValues(base map[string]any, overrides map[string]any) map[string]any {
+	if base == nil {
+		base = map[string]any{}
+	}
+	for key, override := range overrides {
+		baseObject, baseIsObject := base[key].(map[string]any)
+		overrideObject, overrideIsObject := override.(map[string]any)
+		if baseIsObject && overrideIsObject {
+			base[key] = mergeConfigValues(baseObject, overrideObject)
+			continue
+		}
+		base[key] = override
+	}
+	return base
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a JSON config file into a temporary directory and returns its path
+func writeConfigFile(t *testing.T, config map[string]any) string {
+	content, err := json.Marshal(config)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "pydantic.json")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	return path
+}
+
+func TestResolveConfigFile_InlineOverridesFile(t *testing.T) {
+	path := writeConfigFile(t, map[string]any{
+		"inputPath":  "./morphe",
+		"outputPath": "./gen",
+		"config":     map[string]any{"pythonVersion": "3.9", "docstringStyle": "google"},
+	})
+
+	merged, err := resolveConfigFile(`{"outputPath":"./out","config":{"pythonVersion":"3.12"}}`, path)
+	require.NoError(t, err)
+
+	var compileConfig CompileConfig
+	require.NoError(t, json.Unmarshal([]byte(merged), &compileConfig))
+	assert.Equal(t, InputPaths{"./morphe"}, compileConfig.InputPath)
+	assert.Equal(t, "./out", compileConfig.OutputPath)
+	assert.Equal(t, "3.12", compileConfig.Config.PythonVersion)
+	assert.Equal(t, "google", compileConfig.Config.DocstringStyle)
+}
+
+func TestResolveConfigFile_RelativeToWorkingDirectory(t *testing.T) {
+	path := writeConfigFile(t, map[string]any{"inputPath": "./morphe", "outputPath": "./gen"})
+	workingDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Dir(path)))
+	t.Cleanup(func() { os.Chdir(workingDir) })
+
+	merged, err := resolveConfigFile(`{"configFile":"./pydantic.json"}`, "")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"inputPath":"./morphe","outputPath":"./gen"}`, merged)
+}
+
+func TestResolveConfigFile_WithoutConfigFile(t *testing.T) {
+	rawConfig := `{"inputPath":"./morphe","outputPath":"./gen"}`
+
+	merged, err := resolveConfigFile(rawConfig, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, rawConfig, merged)
+}
+
+func TestResolveConfigFile_MalformedInlineConfig(t *testing.T) {
+	path := writeConfigFile(t, map[string]any{"inputPath": "./morphe", "outputPath": "./gen"})
+
+	_, err := resolveConfigFile(`{"outputPath":`, path)
+
+	var configFileErr *ConfigFileError
+	require.ErrorAs(t, err, &configFileErr)
+	assert.Equal(t, path, configFileErr.Path)
+	assert.Contains(t, err.Error(), "invalid inline config JSON")
+}
+
+func TestResolveConfigFile_PreservesLargeIntegers(t *testing.T) {
+	path := writeConfigFile(t, map[string]any{"inputPath": "./morphe", "outputPath": "./gen"})
+
+	merged, err := resolveConfigFile(`{"config":{"maxLineLength":1000000000000000000000}}`, path)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"inputPath":"./morphe","outputPath":"./gen","config":{"maxLineLength":1000000000000000000000}}`, merged)
+}
+
+func TestRun_ConfigFileFlag(t *testing.T) {
+	outputPath := t.TempDir()
+	var config map[string]any
+	require.NoError(t, json.Unmarshal([]byte(pluginConfig(t, outputPath, nil)), &config))
+	path := writeConfigFile(t, config)
+
+	code, _, stderr := runPlugin(configFileFlag, path)
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	assert.FileExists(t, filepath.Join(outputPath, "models", "company.py"))
+}
+
+func TestRun_ConfigFileFlagWithoutPath(t *testing.T) {
+	for _, args := range [][]string{
+		{pluginConfig(t, t.TempDir(), nil), configFileFlag},
+		{configFileFlag, checkFlag},
+		{configFileFlag + "="},
+	} {
+		code, _, stderr := runPlugin(args...)
+
+		assert.Equal(t, ExitInvalidConfig, code, args)
+		assert.Contains(t, stderr, "Error: --config-file requires a path", args)
+	}
+}
+
+func TestRun_MissingConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+
+	code, _, stderr := runPlugin(configFileFlag + "=" + path)
+
+	assert.Equal(t, ExitInvalidConfig, code)
+	assert.Contains(t, stderr, "Error loading config: failed to load config file "+path)
+}
+
+func TestRun_MalformedConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pydantic.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"inputPath":`), 0644))
+
+	code, _, stderr := runPlugin(`{"configFile":"` + path + `"}`)
+
+	assert.Equal(t, ExitInvalidConfig, code)
+	assert.Contains(t, stderr, "invalid config JSON")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// envReferencePattern matches ${VAR} and ${VAR:-default} references in config strings
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// UndefinedEnvVarError is returned when a config string references an unset environment
+// variable without a default
+type UndefinedEnvVarError struct {
+	Name string
+}
+
+func (e *UndefinedEnvVarError) Error() string {
+	return fmt.Sprintf("environment variable %s is not set (use ${%s:-default} to provide a default)", e.Name, e.Name)
+}
+
+// expandEnv replaces the environment variable references in a string. A variable that is set,
+// even to an empty value, wins over the default.
+func expandEnv(value string) (string, error) {
+	var expandErr error
+	expanded := envReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := envReferencePattern.FindStringSubmatch(reference)
+		if envValue, isSet := os.LookupEnv(match[1]); isSet {
+			return envValue
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		if expandErr == nil {
+			expandErr = &UndefinedEnvVarError{Name: match[1]}
+		}
+		return reference
+	})
+	return expanded, expandErr
+}
+
+// expectedConfigFormat describes the shape of the JSON config in syntax error messages
+const expectedConfigFormat = `{"inputPath":"...","outputPath":"...","config":{...},"verbose":false}`
+
+// ConfigSyntaxError is returned when the config isn't valid JSON
+type ConfigSyntaxError struct {
+	Err error
+}
+
+func (e *ConfigSyntaxError) Error() string {
+	return fmt.Sprintf("invalid config JSON: %v\nExpected format: %s", e.Err, expectedConfigFormat)
+}
+
+func (e *ConfigSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// decodeConfigJSON decodes a JSON config into v, keeping numbers as json.Number so that large
+// integers survive re-encoding unchanged
+func decodeConfigJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// expandConfigEnv expands the environment variable references in every string value of the JSON
+// config, leaving object keys untouched. A config that isn't valid JSON is reported as a
+// ConfigSyntaxError.
+func expandConfigEnv(rawConfig string) (string, error) {
+	var document any
+	if err := decodeConfigJSON([]byte(rawConfig), &document); err != nil {
+		return "", &ConfigSyntaxError{Err: err}
+	}
+
+	expanded, err := expandEnvValues(document)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(expanded)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// expandEnvValues walks a decoded JSON value, expanding its strings. Object keys are visited in
+// sorted order so the same undefined variable is reported on every run.
+func expandEnvValues(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return expandEnv(v)
+	case []any:
+		for i, item := range v {
+			expanded, err := expandEnvValues(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			expanded, err := expandEnvValues(v[key])
+			if err != nil {
+				return nil, err
+			}
+			v[key] = expanded
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandConfigEnv_PackagePath(t *testing.T) {
+	t.Setenv("MORPHE_PACKAGE", "billing_types")
+
+	expanded, err := expandConfigEnv(`{"inputPath":"./morphe","outputPath":"./gen/${MORPHE_PACKAGE}","config":{"pythonVersion":"${PYTHON_VERSION:-3.11}"}}`)
+	require.NoError(t, err)
+
+	var compileConfig CompileConfig
+	require.NoError(t, json.Unmarshal([]byte(expanded), &compileConfig))
+	assert.Equal(t, "./gen/billing_types", compileConfig.OutputPath)
+	assert.Equal(t, "3.11", compileConfig.Config.PythonVersion)
+}
+
+func TestExpandConfigEnv_SetVariableWinsOverDefault(t *testing.T) {
+	t.Setenv("PYTHON_VERSION", "3.9")
+
+	expanded, err := expandEnv("${PYTHON_VERSION:-3.11}")
+
+	require.NoError(t, err)
+	assert.Equal(t, "3.9", expanded)
+}
+
+func TestExpandConfigEnv_UndefinedVariable(t *testing.T) {
+	_, err := expandConfigEnv(`{"inputPath":"./morphe","outputPath":"./gen/${MORPHE_UNDEFINED_PACKAGE}"}`)
+
+	var undefinedErr *UndefinedEnvVarError
+	require.ErrorAs(t, err, &undefinedErr)
+	assert.Equal(t, "MORPHE_UNDEFINED_PACKAGE", undefinedErr.Name)
+}
+
+func TestExpandConfigEnv_PreservesLargeIntegers(t *testing.T) {
+	expanded, err := expandConfigEnv(`{"outputPath":"./gen","config":{"maxLineLength":1000000000000000000000}}`)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"outputPath":"./gen","config":{"maxLineLength":1000000000000000000000}}`, expanded)
+	assert.NotContains(t, expanded, "e+")
+}
+
+func TestExpandConfigEnv_ReportsFirstUndefinedVariableByKey(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		_, err := expandConfigEnv(`{"outputPath":"${MORPHE_UNDEFINED_OUTPUT}","inputPath":"${MORPHE_UNDEFINED_INPUT}"}`)
+
+		var undefinedErr *UndefinedEnvVarError
+		require.ErrorAs(t, err, &undefinedErr)
+		assert.Equal(t, "MORPHE_UNDEFINED_INPUT", undefinedErr.Name)
+	}
+}
+
+func TestExpandConfigEnv_InvalidJSON(t *testing.T) {
+	_, err := expandConfigEnv(`{"inputPath":`)
+
+	var syntaxErr *ConfigSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.Contains(t, err.Error(), "Expected format: "+expectedConfigFormat)
+}
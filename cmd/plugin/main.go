@@ -2,9 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
@@ -12,26 +17,80 @@ import (
 
 // CompileConfig represents the configuration passed to the plugin
 type CompileConfig struct {
-	InputPath  string       `json:"inputPath"`
+	InputPath  InputPaths   `json:"inputPath"`
 	OutputPath string       `json:"outputPath"`
 	Config     PluginConfig `json:"config,omitempty"`
 	Verbose    bool         `json:"verbose,omitempty"`
+	Check      bool         `json:"check,omitempty"` // Fail when the output directory is out of date instead of writing it
+	Only       []string     `json:"only,omitempty"`  // Type names to compile together with their dependencies
+}
+
+// InputPaths holds one or more registry roots, accepting either a single JSON string or an array
+type InputPaths []string
+
+// UnmarshalJSON accepts both "path" and ["path", ...] forms
+func (p *InputPaths) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*p = InputPaths{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("inputPath must be a string or an array of strings: %w", err)
+	}
+	*p = multiple
+	return nil
 }
 
 // PluginConfig represents the Pydantic-specific configuration
 type PluginConfig struct {
 	// Pydantic-specific settings
-	PythonVersion string `json:"pythonVersion,omitempty"`
-	PydanticV2    *bool  `json:"pydanticV2,omitempty"`
-	AddTypeHints  *bool  `json:"addTypeHints,omitempty"`
-	GenerateInit  *bool  `json:"generateInit,omitempty"`
-	IndentSize    *int   `json:"indentSize,omitempty"`
+	PythonVersion    string  `json:"pythonVersion,omitempty"`
+	PydanticV2       *bool   `json:"pydanticV2,omitempty"`
+	AddTypeHints     *bool   `json:"addTypeHints,omitempty"`
+	GenerateInit     *bool   `json:"generateInit,omitempty"`
+	IndentSize       *int    `json:"indentSize,omitempty"`
+	MaxLineLength    *int    `json:"maxLineLength,omitempty"`
+	EmitPyTyped      *bool   `json:"emitPyTyped,omitempty"`
+	EmitJSONSchema   *bool   `json:"emitJSONSchema,omitempty"`
+	FileTemplatePath string  `json:"fileTemplatePath,omitempty"`
+	GenerateGraph    string  `json:"generateGraph,omitempty"`
+	FileNaming       string  `json:"fileNaming,omitempty"`
+	FieldCase        string  `json:"fieldCase,omitempty"`
+	StrictTypes      *bool   `json:"strictTypes,omitempty"`
+	StrictRelations  *bool   `json:"strictRelations,omitempty"`
+	UnknownRelations string  `json:"unknownRelations,omitempty"`
+	DocstringStyle   string  `json:"docstringStyle,omitempty"`
+	JSONType         string  `json:"jsonType,omitempty"`
+	AwareDatetimes   *bool   `json:"awareDatetimes,omitempty"`
+	FileHeader       *string `json:"fileHeader,omitempty"` // An empty string disables the header
+	RootPackage      string  `json:"rootPackage,omitempty"`
+	SingleFile       *bool   `json:"singleFile,omitempty"`
+	ValidateSyntax   *bool   `json:"validateSyntax,omitempty"`
+	// Field telling apart many-polymorphic union members; an empty string disables it
+	PolymorphicDiscriminator *string `json:"polymorphicDiscriminator,omitempty"`
+	// Required fields ahead of optional ones
+	SortRequiredFirst *bool `json:"sortRequiredFirst,omitempty"`
+	// No aliases to the original Morphe field keys (default: false)
+	DisableFieldKeyAliases *bool `json:"disableFieldKeyAliases,omitempty"`
+	// Python types of Morphe field types, ahead of the built-in mappings
+	CustomTypeMappings map[string]compile.CustomType `json:"customTypeMappings,omitempty"`
+	// Precedence of the field type sources
+	TypeResolutionOrder []string `json:"typeResolutionOrder,omitempty"`
+	// Stub-only package for separate distribution
+	GenerateStubPackage *bool  `json:"generateStubPackage,omitempty"`
+	StubPackageName     string `json:"stubPackageName,omitempty"`
 
 	// Type-specific configurations
 	Enums      cfg.EnumConfig      `json:"enums,omitempty"`
 	Models     cfg.ModelConfig     `json:"models,omitempty"`
 	Structures cfg.StructureConfig `json:"structures,omitempty"`
 	Entities   cfg.EntityConfig    `json:"entities,omitempty"`
+
+	// Input validation
+	FieldNameConvention string `json:"fieldNameConvention,omitempty"`
 }
 
 // Exit codes
@@ -40,52 +99,155 @@ const (
 	ExitCompileFailed   = 1
 	ExitMissingConfig   = 3
 	ExitInvalidConfig   = 4
+	ExitTypeMapFailed   = 5
+	ExitRelationFailed  = 6
+	ExitFieldNameFailed = 7
+	ExitStale           = 8
 	ExitInputPathError  = 12
 	ExitOutputPathError = 13
 )
 
+// compileExitCode maps a compilation error to its exit code
+func compileExitCode(err error) int {
+	var relationErr *compile.RelationResolveError
+	var typeMapErr *compile.TypeMapError
+	var configErr *compile.ConfigValidationError
+	var fieldNameErr *compile.FieldNameConventionError
+	switch {
+	case errors.As(err, &fieldNameErr):
+		return ExitFieldNameFailed
+	case errors.As(err, &relationErr):
+		return ExitRelationFailed
+	case errors.As(err, &typeMapErr):
+		return ExitTypeMapFailed
+	case errors.As(err, &configErr):
+		return ExitInvalidConfig
+	default:
+		return ExitCompileFailed
+	}
+}
+
 // logInfo prints info messages only when verbose mode is enabled
-func logInfo(verbose bool, format string, args ...interface{}) {
+func logInfo(stdout io.Writer, verbose bool, format string, args ...interface{}) {
 	if verbose {
-		fmt.Fprintf(os.Stdout, format+"\n", args...)
+		fmt.Fprintf(stdout, format+"\n", args...)
 	}
 }
 
 func main() {
+	os.Exit(Run(os.Args, os.Stdin, os.Stdout, os.Stderr))
+}
+
+// errMissingConfigFilePath is returned when --config-file isn't followed by a path
+var errMissingConfigFilePath = errors.New(configFileFlag + " requires a path")
+
+// parseArgs splits the command line into the JSON config, the --config-file path (given as
+// "--config-file path" or "--config-file=path") and the --watch and --check flags. A
+// --config-file without a path, at the end or followed by another flag, is an error rather
+// than being taken for the config.
+func parseArgs(args []string) (rawConfig string, configFile string, watch, check bool, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == watchFlag {
+			watch = true
+		} else if arg == checkFlag {
+			check = true
+		} else if arg == configFileFlag {
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+				return "", "", false, false, errMissingConfigFilePath
+			}
+			i++
+			configFile = args[i]
+		} else if strings.HasPrefix(arg, configFileFlag+"=") {
+			configFile = strings.TrimPrefix(arg, configFileFlag+"=")
+			if configFile == "" {
+				return "", "", false, false, errMissingConfigFilePath
+			}
+		} else if rawConfig == "" {
+			rawConfig = arg
+		}
+	}
+	return rawConfig, configFile, watch, check, nil
+}
+
+// Run executes the plugin with the given command line, including the program name, and
+// standard streams, returning the process exit code. It never exits the process itself, so it
+// can be tested directly or embedded in another command. stdin is accepted for symmetry with
+// the process streams and is not read yet.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 	// Check command line arguments
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: plugin-morphe-pydantic-types <config>")
-		fmt.Fprintln(os.Stderr, "  config: JSON string with inputPath, outputPath, and optional config parameters")
-		fmt.Fprintln(os.Stderr, "")
-		fmt.Fprintln(os.Stderr, "Example:")
-		fmt.Fprintln(os.Stderr, `  plugin-morphe-pydantic-types '{"inputPath":"./morphe","outputPath":"./output","verbose":true}'`)
-		os.Exit(ExitMissingConfig)
+	var rawConfig, configFile string
+	var watch, check bool
+	if len(args) > 0 {
+		var err error
+		rawConfig, configFile, watch, check, err = parseArgs(args[1:])
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return ExitInvalidConfig
+		}
+	}
+	if rawConfig == "" && configFile == "" {
+		fmt.Fprintln(stderr, "Usage: plugin-morphe-pydantic-types [--watch | --check] [--config-file <path>] <config>")
+		fmt.Fprintln(stderr, "  config: JSON string with inputPath, outputPath, and optional config parameters")
+		fmt.Fprintln(stderr, "  --config-file: load the config from a JSON file; the inline config overrides its values")
+		fmt.Fprintln(stderr, "  --watch: recompile whenever a registry file changes (Ctrl+C to stop)")
+		fmt.Fprintln(stderr, "  --check: fail if the output directory differs from the generated files, without writing")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example:")
+		fmt.Fprintln(stderr, `  plugin-morphe-pydantic-types '{"inputPath":"./morphe","outputPath":"./output","verbose":true}'`)
+		return ExitMissingConfig
+	}
+
+	// Merge the inline config over the config file, if any
+	rawConfig, err := resolveConfigFile(rawConfig, configFile)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error loading config:", err)
+		return ExitInvalidConfig
+	}
+
+	// Expand ${VAR} and ${VAR:-default} references before parsing and validation
+	expandedConfig, err := expandConfigEnv(rawConfig)
+	if err != nil {
+		var syntaxErr *ConfigSyntaxError
+		if errors.As(err, &syntaxErr) {
+			fmt.Fprintln(stderr, "Error parsing config JSON:", err)
+		} else {
+			fmt.Fprintln(stderr, "Error expanding config:", err)
+		}
+		return ExitInvalidConfig
 	}
 
 	// Parse configuration
-	rawConfig := os.Args[1]
 	var compileConfig CompileConfig
-	if err := json.Unmarshal([]byte(rawConfig), &compileConfig); err != nil {
-		fmt.Fprintln(os.Stderr, "Error parsing config JSON:", err)
-		fmt.Fprintln(os.Stderr, "Expected format: {\"inputPath\":\"...\",\"outputPath\":\"...\",\"config\":{...},\"verbose\":false}")
-		os.Exit(ExitInvalidConfig)
+	if err := json.Unmarshal([]byte(expandedConfig), &compileConfig); err != nil {
+		fmt.Fprintln(stderr, "Error parsing config JSON:", err)
+		fmt.Fprintln(stderr, "Expected format:", expectedConfigFormat)
+		return ExitInvalidConfig
 	}
 
 	// Validate required fields
-	if compileConfig.InputPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: inputPath is required")
-		os.Exit(ExitInputPathError)
+	if len(compileConfig.InputPath) == 0 {
+		fmt.Fprintln(stderr, "Error: inputPath is required")
+		return ExitInputPathError
+	}
+	for _, inputPath := range compileConfig.InputPath {
+		if inputPath == "" {
+			fmt.Fprintln(stderr, "Error: inputPath entries must not be empty")
+			return ExitInputPathError
+		}
 	}
 
 	if compileConfig.OutputPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: outputPath is required")
-		os.Exit(ExitOutputPathError)
+		fmt.Fprintln(stderr, "Error: outputPath is required")
+		return ExitOutputPathError
 	}
 
 	// Convert to absolute paths
-	inputAbs, err := filepath.Abs(compileConfig.InputPath)
-	if err == nil {
-		compileConfig.InputPath = inputAbs
+	for i, inputPath := range compileConfig.InputPath {
+		inputAbs, err := filepath.Abs(inputPath)
+		if err == nil {
+			compileConfig.InputPath[i] = inputAbs
+		}
 	}
 
 	outputAbs, err := filepath.Abs(compileConfig.OutputPath)
@@ -93,45 +255,190 @@ func main() {
 		compileConfig.OutputPath = outputAbs
 	}
 
-	logInfo(compileConfig.Verbose, "Processing Morphe registry from: '%s'", compileConfig.InputPath)
-	logInfo(compileConfig.Verbose, "Output Pydantic types to: '%s'", compileConfig.OutputPath)
+	for _, inputPath := range compileConfig.InputPath {
+		logInfo(stdout, compileConfig.Verbose, "Processing Morphe registry from: '%s'", inputPath)
+	}
+	logInfo(stdout, compileConfig.Verbose, "Output Pydantic types to: '%s'", compileConfig.OutputPath)
 
 	// Initialize the compile configuration
-	logInfo(compileConfig.Verbose, "Initializing compile configuration...")
+	logInfo(stdout, compileConfig.Verbose, "Initializing compile configuration...")
 	morpheConfig := compile.DefaultMorpheCompileConfig(
-		compileConfig.InputPath,
+		compileConfig.InputPath[0],
 		compileConfig.OutputPath,
+		compileConfig.InputPath[1:]...,
 	)
 
 	// Apply configuration from compileConfig.Config
 	// Python version
 	if compileConfig.Config.PythonVersion != "" {
 		morpheConfig.FormatConfig.PythonVersion = compileConfig.Config.PythonVersion
-		logInfo(compileConfig.Verbose, "Setting Python version to: %s", compileConfig.Config.PythonVersion)
+		logInfo(stdout, compileConfig.Verbose, "Setting Python version to: %s", compileConfig.Config.PythonVersion)
 	}
 
 	// Pydantic settings
 	if compileConfig.Config.PydanticV2 != nil {
 		morpheConfig.FormatConfig.PydanticV2 = *compileConfig.Config.PydanticV2
-		logInfo(compileConfig.Verbose, "Use Pydantic v2: %v", *compileConfig.Config.PydanticV2)
+		logInfo(stdout, compileConfig.Verbose, "Use Pydantic v2: %v", *compileConfig.Config.PydanticV2)
 	}
 
 	// Type hints
 	if compileConfig.Config.AddTypeHints != nil {
 		morpheConfig.FormatConfig.AddTypeHints = *compileConfig.Config.AddTypeHints
-		logInfo(compileConfig.Verbose, "Add type hints: %v", *compileConfig.Config.AddTypeHints)
+		logInfo(stdout, compileConfig.Verbose, "Add type hints: %v", *compileConfig.Config.AddTypeHints)
 	}
 
 	// Init files
 	if compileConfig.Config.GenerateInit != nil {
 		morpheConfig.FormatConfig.GenerateInit = *compileConfig.Config.GenerateInit
-		logInfo(compileConfig.Verbose, "Generate __init__.py: %v", *compileConfig.Config.GenerateInit)
+		logInfo(stdout, compileConfig.Verbose, "Generate __init__.py: %v", *compileConfig.Config.GenerateInit)
 	}
 
 	// Indentation
 	if compileConfig.Config.IndentSize != nil {
 		morpheConfig.FormatConfig.IndentSize = *compileConfig.Config.IndentSize
-		logInfo(compileConfig.Verbose, "Indent size: %d", *compileConfig.Config.IndentSize)
+		logInfo(stdout, compileConfig.Verbose, "Indent size: %d", *compileConfig.Config.IndentSize)
+	}
+
+	// Line length
+	if compileConfig.Config.MaxLineLength != nil {
+		morpheConfig.FormatConfig.MaxLineLength = *compileConfig.Config.MaxLineLength
+		logInfo(stdout, compileConfig.Verbose, "Max line length: %d", *compileConfig.Config.MaxLineLength)
+	}
+
+	// Typed package marker
+	if compileConfig.Config.EmitPyTyped != nil {
+		morpheConfig.FormatConfig.EmitPyTyped = *compileConfig.Config.EmitPyTyped
+		logInfo(stdout, compileConfig.Verbose, "Emit py.typed: %v", *compileConfig.Config.EmitPyTyped)
+	}
+
+	// JSON Schema export
+	if compileConfig.Config.EmitJSONSchema != nil {
+		morpheConfig.FormatConfig.EmitJSONSchema = *compileConfig.Config.EmitJSONSchema
+		logInfo(stdout, compileConfig.Verbose, "Emit JSON schema: %v", *compileConfig.Config.EmitJSONSchema)
+	}
+
+	// Model file template
+	if compileConfig.Config.FileTemplatePath != "" {
+		morpheConfig.FormatConfig.FileTemplatePath = compileConfig.Config.FileTemplatePath
+		logInfo(stdout, compileConfig.Verbose, "Model file template: %s", compileConfig.Config.FileTemplatePath)
+	}
+
+	// Relationship graph export
+	if compileConfig.Config.GenerateGraph != "" {
+		morpheConfig.FormatConfig.GenerateGraph = compileConfig.Config.GenerateGraph
+		logInfo(stdout, compileConfig.Verbose, "Model graph format: %s", compileConfig.Config.GenerateGraph)
+	}
+
+	// Module file naming
+	if compileConfig.Config.FileNaming != "" {
+		morpheConfig.FormatConfig.FileNaming = compileConfig.Config.FileNaming
+		logInfo(stdout, compileConfig.Verbose, "File naming: %s", compileConfig.Config.FileNaming)
+	}
+	if compileConfig.Config.FieldCase != "" {
+		morpheConfig.FormatConfig.FieldCase = compileConfig.Config.FieldCase
+		logInfo(stdout, compileConfig.Verbose, "Field case: %s", compileConfig.Config.FieldCase)
+	}
+
+	// Required fields ahead of optional ones
+	if compileConfig.Config.SortRequiredFirst != nil {
+		morpheConfig.FormatConfig.SortRequiredFirst = *compileConfig.Config.SortRequiredFirst
+		logInfo(stdout, compileConfig.Verbose, "Sort required fields first: %v", *compileConfig.Config.SortRequiredFirst)
+	}
+
+	// Original Morphe field keys as aliases
+	if compileConfig.Config.DisableFieldKeyAliases != nil {
+		morpheConfig.FormatConfig.DisableFieldKeyAliases = *compileConfig.Config.DisableFieldKeyAliases
+		logInfo(stdout, compileConfig.Verbose, "Disable field key aliases: %v", *compileConfig.Config.DisableFieldKeyAliases)
+	}
+
+	// Strict type mapping
+	if compileConfig.Config.StrictTypes != nil {
+		morpheConfig.FormatConfig.StrictTypes = *compileConfig.Config.StrictTypes
+		logInfo(stdout, compileConfig.Verbose, "Strict types: %v", *compileConfig.Config.StrictTypes)
+	}
+
+	// Unresolvable polymorphic relationships
+	if compileConfig.Config.StrictRelations != nil {
+		morpheConfig.FormatConfig.StrictRelations = *compileConfig.Config.StrictRelations
+		logInfo(stdout, compileConfig.Verbose, "Strict relations: %v", *compileConfig.Config.StrictRelations)
+	}
+
+	// Relationships of an unknown type
+	if compileConfig.Config.UnknownRelations != "" {
+		morpheConfig.FormatConfig.UnknownRelations = compileConfig.Config.UnknownRelations
+		logInfo(stdout, compileConfig.Verbose, "Unknown relations: %s", compileConfig.Config.UnknownRelations)
+	}
+
+	// Class docstring style
+	if compileConfig.Config.DocstringStyle != "" {
+		morpheConfig.FormatConfig.DocstringStyle = compileConfig.Config.DocstringStyle
+		logInfo(stdout, compileConfig.Verbose, "Docstring style: %s", compileConfig.Config.DocstringStyle)
+	}
+
+	// Python type of JSON fields
+	if compileConfig.Config.JSONType != "" {
+		morpheConfig.FormatConfig.JSONType = compileConfig.Config.JSONType
+		logInfo(stdout, compileConfig.Verbose, "JSON type: %s", compileConfig.Config.JSONType)
+	}
+
+	// Timezone-aware datetime fields
+	if compileConfig.Config.AwareDatetimes != nil {
+		morpheConfig.FormatConfig.AwareDatetimes = *compileConfig.Config.AwareDatetimes
+		logInfo(stdout, compileConfig.Verbose, "Aware datetimes: %v", *compileConfig.Config.AwareDatetimes)
+	}
+
+	// Generated file banner
+	if compileConfig.Config.FileHeader != nil {
+		morpheConfig.FormatConfig.FileHeader = *compileConfig.Config.FileHeader
+		morpheConfig.FormatConfig.DisableFileHeader = *compileConfig.Config.FileHeader == ""
+		logInfo(stdout, compileConfig.Verbose, "File header: %q", *compileConfig.Config.FileHeader)
+	}
+
+	// Many-polymorphic union discriminator
+	if compileConfig.Config.PolymorphicDiscriminator != nil {
+		morpheConfig.FormatConfig.PolymorphicDiscriminator = *compileConfig.Config.PolymorphicDiscriminator
+		morpheConfig.FormatConfig.DisablePolymorphicDiscriminator = *compileConfig.Config.PolymorphicDiscriminator == ""
+		logInfo(stdout, compileConfig.Verbose, "Polymorphic discriminator: %q", *compileConfig.Config.PolymorphicDiscriminator)
+	}
+
+	// Root package namespace
+	if compileConfig.Config.RootPackage != "" {
+		morpheConfig.FormatConfig.RootPackage = compileConfig.Config.RootPackage
+		logInfo(stdout, compileConfig.Verbose, "Root package: %s", compileConfig.Config.RootPackage)
+	}
+
+	// Single bundled module
+	if compileConfig.Config.SingleFile != nil {
+		morpheConfig.FormatConfig.SingleFile = *compileConfig.Config.SingleFile
+		logInfo(stdout, compileConfig.Verbose, "Single file: %v", *compileConfig.Config.SingleFile)
+	}
+
+	// Python syntax check of the generated files
+	if compileConfig.Config.ValidateSyntax != nil {
+		morpheConfig.FormatConfig.ValidateSyntax = *compileConfig.Config.ValidateSyntax
+		logInfo(stdout, compileConfig.Verbose, "Validate syntax: %v", *compileConfig.Config.ValidateSyntax)
+	}
+
+	// User-provided field type mappings
+	if len(compileConfig.Config.CustomTypeMappings) > 0 {
+		morpheConfig.FormatConfig.CustomTypeMappings = compileConfig.Config.CustomTypeMappings
+		logInfo(stdout, compileConfig.Verbose, "Custom type mappings: %d", len(compileConfig.Config.CustomTypeMappings))
+	}
+
+	// Type resolution precedence
+	if len(compileConfig.Config.TypeResolutionOrder) > 0 {
+		morpheConfig.FormatConfig.TypeResolutionOrder = compileConfig.Config.TypeResolutionOrder
+		logInfo(stdout, compileConfig.Verbose, "Type resolution order: %v", compileConfig.Config.TypeResolutionOrder)
+	}
+
+	// Stub-only package
+	if compileConfig.Config.GenerateStubPackage != nil {
+		morpheConfig.FormatConfig.GenerateStubPackage = *compileConfig.Config.GenerateStubPackage
+		logInfo(stdout, compileConfig.Verbose, "Generate stub package: %v", *compileConfig.Config.GenerateStubPackage)
+	}
+	if compileConfig.Config.StubPackageName != "" {
+		morpheConfig.FormatConfig.StubPackageName = compileConfig.Config.StubPackageName
+		logInfo(stdout, compileConfig.Verbose, "Stub package name: %s", compileConfig.Config.StubPackageName)
 	}
 
 	// Apply type-specific configurations
@@ -139,33 +446,81 @@ func main() {
 	morpheConfig.MorpheConfig.Models = compileConfig.Config.Models
 	morpheConfig.MorpheConfig.Structures = compileConfig.Config.Structures
 	morpheConfig.MorpheConfig.Entities = compileConfig.Config.Entities
+	morpheConfig.MorpheConfig.FieldNameConvention = compileConfig.Config.FieldNameConvention
 
 	// Log type-specific configs if verbose
 	if compileConfig.Verbose {
 		if compileConfig.Config.Models.UseField {
-			logInfo(true, "Models use Field: true")
+			logInfo(stdout, true, "Models use Field: true")
 		}
 		if compileConfig.Config.Enums.GenerateStrMethod {
-			logInfo(true, "Enums generate __str__: true")
+			logInfo(stdout, true, "Enums generate __str__: true")
 		}
 		if compileConfig.Config.Entities.LazyLoadingStyle != "" {
-			logInfo(true, "Entity lazy loading style: %s", compileConfig.Config.Entities.LazyLoadingStyle)
+			logInfo(stdout, true, "Entity lazy loading style: %s", compileConfig.Config.Entities.LazyLoadingStyle)
 		}
 	}
 
+	// Restrict compilation to the listed types
+	if len(compileConfig.Only) > 0 {
+		morpheConfig.Only = compileConfig.Only
+		logInfo(stdout, compileConfig.Verbose, "Compiling only: %s", strings.Join(compileConfig.Only, ", "))
+	}
+
+	// Report progress and summarize the compilation once it completes
+	morpheConfig.Verbose = compileConfig.Verbose
+	morpheConfig.Output = stdout
+
+	// Keep warnings out of stdout, which may be piped elsewhere
+	morpheConfig.Warnings = stderr
+
 	// Validate configuration
 	if err := morpheConfig.Validate(); err != nil {
-		fmt.Fprintln(os.Stderr, "Invalid configuration:", err)
-		os.Exit(ExitInvalidConfig)
+		fmt.Fprintln(stderr, "Invalid configuration:", err)
+		return ExitInvalidConfig
+	}
+
+	// Compare with the existing output instead of writing it
+	if check || compileConfig.Check {
+		logInfo(stdout, compileConfig.Verbose, "Checking generated files against: '%s'", compileConfig.OutputPath)
+		return runCheck(morpheConfig, stdout, stderr)
 	}
 
 	// Run compilation
-	logInfo(compileConfig.Verbose, "Starting compilation process...")
+	logInfo(stdout, compileConfig.Verbose, "Starting compilation process...")
 	if err := compile.MorpheToPydantic(morpheConfig); err != nil {
-		fmt.Fprintln(os.Stderr, "Compilation failed:", err)
-		os.Exit(ExitCompileFailed)
+		fmt.Fprintln(stderr, "Compilation failed:", err)
+		if !watch {
+			return compileExitCode(err)
+		}
+	} else {
+		logInfo(stdout, compileConfig.Verbose, "Compilation completed successfully")
+	}
+
+	// Keep recompiling on registry changes; errors are reported without stopping the watcher
+	if watch {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+		defer signal.Stop(stop)
+
+		fmt.Fprintln(stdout, "Watching for registry changes (Ctrl+C to stop)...")
+		err := watchRegistries(compileConfig.InputPath, func() {
+			fmt.Fprintln(stdout, "Registry changed, recompiling...")
+			if err := compile.MorpheToPydantic(morpheConfig); err != nil {
+				fmt.Fprintln(stderr, "Compilation failed:", err)
+				return
+			}
+			fmt.Fprintln(stdout, "Compilation completed successfully")
+		}, func(err error) {
+			fmt.Fprintln(stderr, "Watch error:", err)
+		}, stop)
+		if err != nil {
+			fmt.Fprintln(stderr, "Failed to watch registries:", err)
+			return ExitInputPathError
+		}
+		fmt.Fprintln(stdout, "Stopped watching")
 	}
 
-	logInfo(compileConfig.Verbose, "Compilation completed successfully")
-	os.Exit(ExitSuccess)
+	return ExitSuccess
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runPlugin runs the plugin in process and returns its exit code and output streams
+func runPlugin(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(append([]string{"plugin-morphe-pydantic-types"}, args...), strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// pluginConfig renders a JSON config compiling the minimal test registry into outputPath
+func pluginConfig(t *testing.T, outputPath string, config map[string]any) string {
+	rawConfig, err := json.Marshal(map[string]any{
+		"inputPath":  filepath.Join("..", "..", "testdata", "registry", "minimal"),
+		"outputPath": outputPath,
+		"config":     config,
+	})
+	require.NoError(t, err)
+	return string(rawConfig)
+}
+
+func TestRun_MissingConfig(t *testing.T) {
+	code, _, stderr := runPlugin()
+
+	assert.Equal(t, ExitMissingConfig, code)
+	assert.Contains(t, stderr, "Usage: plugin-morphe-pydantic-types")
+}
+
+func TestRun_MalformedConfig(t *testing.T) {
+	code, _, stderr := runPlugin(`{"inputPath":`)
+
+	assert.Equal(t, ExitInvalidConfig, code)
+	assert.Contains(t, stderr, "Error parsing config JSON")
+	assert.Contains(t, stderr, "Expected format:")
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	code, _, stderr := runPlugin(pluginConfig(t, t.TempDir(), map[string]any{"docstringStyle": "sphinx"}))
+
+	assert.Equal(t, ExitInvalidConfig, code)
+	assert.Contains(t, stderr, "Invalid configuration: invalid docstringStyle: sphinx")
+}
+
+func TestRun_MissingOutputPath(t *testing.T) {
+	code, _, stderr := runPlugin(`{"inputPath":"./morphe"}`)
+
+	assert.Equal(t, ExitOutputPathError, code)
+	assert.Contains(t, stderr, "Error: outputPath is required")
+}
+
+func TestRun_Success(t *testing.T) {
+	outputPath := t.TempDir()
+
+	code, _, stderr := runPlugin(pluginConfig(t, outputPath, nil))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	assert.FileExists(t, filepath.Join(outputPath, "models", "company.py"))
+}
+
+func TestRun_VerboseWritesToStdout(t *testing.T) {
+	outputPath := t.TempDir()
+	var rawConfig map[string]any
+	require.NoError(t, json.Unmarshal([]byte(pluginConfig(t, outputPath, nil)), &rawConfig))
+	rawConfig["verbose"] = true
+	verboseConfig, err := json.Marshal(rawConfig)
+	require.NoError(t, err)
+
+	code, stdout, _ := runPlugin(string(verboseConfig))
+
+	assert.Equal(t, ExitSuccess, code)
+	assert.Contains(t, stdout, "Compilation completed successfully")
+}
+
+func TestRun_VerboseSummaryWritesToStdout(t *testing.T) {
+	outputPath := t.TempDir()
+	var rawConfig map[string]any
+	require.NoError(t, json.Unmarshal([]byte(pluginConfig(t, outputPath, nil)), &rawConfig))
+	rawConfig["verbose"] = true
+	verboseConfig, err := json.Marshal(rawConfig)
+	require.NoError(t, err)
+
+	code, stdout, stderr := runPlugin(string(verboseConfig))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	assert.Contains(t, stdout, "Compiling models...\n")
+	assert.Contains(t, stdout, `Compilation summary:
+  Enums:      2
+  Models:     3 (3 with relationships)
+  Structures: 1
+  Entities:   2
+  Polymorphic relationships: 0 resolved, 0 fell back to Any
+`)
+	assert.NotContains(t, stderr, "Compilation summary")
+}
+
+func TestRun_QuietWithoutVerbose(t *testing.T) {
+	code, stdout, stderr := runPlugin(pluginConfig(t, t.TempDir(), nil))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	assert.Empty(t, stdout)
+}
+
+func TestRun_CheckUpToDate(t *testing.T) {
+	outputPath := t.TempDir()
+	rawConfig := pluginConfig(t, outputPath, nil)
+	code, _, stderr := runPlugin(rawConfig)
+	require.Equal(t, ExitSuccess, code, stderr)
+
+	code, stdout, _ := runPlugin(checkFlag, rawConfig)
+
+	assert.Equal(t, ExitSuccess, code)
+	assert.Contains(t, stdout, "Generated files are up to date")
+}
+
+func TestRun_CheckStale(t *testing.T) {
+	code, _, stderr := runPlugin(checkFlag, pluginConfig(t, t.TempDir(), nil))
+
+	assert.Equal(t, ExitStale, code)
+	assert.Contains(t, stderr, "Generated files are out of date")
+}
+
+func TestRun_EmptyFileHeaderDisablesBanner(t *testing.T) {
+	outputPath := t.TempDir()
+
+	code, _, stderr := runPlugin(pluginConfig(t, outputPath, map[string]any{"fileHeader": ""}))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	content, err := os.ReadFile(filepath.Join(outputPath, "enums", "nationality.py"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "Auto-generated")
+}
+
+func TestRun_DisableFieldKeyAliases(t *testing.T) {
+	outputPath := t.TempDir()
+
+	code, _, stderr := runPlugin(pluginConfig(t, outputPath, map[string]any{"disableFieldKeyAliases": true}))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	content, err := os.ReadFile(filepath.Join(outputPath, "models", "person.py"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "    last_name: str\n")
+	assert.NotContains(t, string(content), "alias=")
+}
+
+func TestRun_CustomTypeMappings(t *testing.T) {
+	outputPath := t.TempDir()
+	mappings := map[string]any{
+		"String": map[string]any{"type": "Name", "import": "from myapp.names import Name"},
+	}
+
+	code, _, stderr := runPlugin(pluginConfig(t, outputPath, map[string]any{"customTypeMappings": mappings}))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	content, err := os.ReadFile(filepath.Join(outputPath, "models", "person.py"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "from myapp.names import Name\n")
+	assert.Contains(t, string(content), "    last_name: Name = Field(alias=\"LastName\")\n")
+}
+
+func TestRun_CustomTypeMappingWithoutType(t *testing.T) {
+	mappings := map[string]any{"Money": map[string]any{"import": "from myapp.money import Money"}}
+
+	code, _, stderr := runPlugin(pluginConfig(t, t.TempDir(), map[string]any{"customTypeMappings": mappings}))
+
+	assert.Equal(t, ExitInvalidConfig, code)
+	assert.Contains(t, stderr, "invalid customTypeMappings: Money has no Python type")
+}
+
+func TestRun_SortRequiredFirst(t *testing.T) {
+	inputPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(inputPath, "structures"), 0755))
+	structure := "name: Contact\nfields:\n  Alias:\n    type: String\n    attributes:\n      - optional\n  Name:\n    type: String\n"
+	require.NoError(t, os.WriteFile(filepath.Join(inputPath, "structures", "contact.str"), []byte(structure), 0644))
+	outputPath := t.TempDir()
+	rawConfig, err := json.Marshal(map[string]any{
+		"inputPath":  inputPath,
+		"outputPath": outputPath,
+		"config":     map[string]any{"sortRequiredFirst": true},
+	})
+	require.NoError(t, err)
+
+	code, _, stderr := runPlugin(string(rawConfig))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	content, err := os.ReadFile(filepath.Join(outputPath, "structures", "contact.py"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `    name: str = Field(alias="Name")
+    alias: Optional[str] = Field(default=None, alias="Alias")
+`)
+}
+
+func TestRun_WarningsWriteToStderr(t *testing.T) {
+	t.Setenv("PATH", "")
+
+	code, stdout, stderr := runPlugin(pluginConfig(t, t.TempDir(), map[string]any{"validateSyntax": true}))
+
+	require.Equal(t, ExitSuccess, code, stderr)
+	assert.Contains(t, stderr, "Warning: python3 not found, skipping the Python syntax check")
+	assert.NotContains(t, stdout, "Warning:")
+}
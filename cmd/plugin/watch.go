@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchFlag enables recompiling whenever a registry file changes
+const watchFlag = "--watch"
+
+// watchInterval is how often a pending change is checked against the debounce period
+const watchInterval = 100 * time.Millisecond
+
+// watchDebounce is how long changes must settle before a recompile, so that editors saving
+// several files at once trigger a single compile
+const watchDebounce = 500 * time.Millisecond
+
+// registryWatch tracks registry changes between checks and decides when a recompile is due
+type registryWatch struct {
+	changedAt time.Time
+	pending   bool
+	debounce  time.Duration
+}
+
+// observe records whether a change happened at now and reports whether changes have settled
+// for the debounce period, in which case a recompile is due
+func (w *registryWatch) observe(changed bool, now time.Time) bool {
+	if changed {
+		w.changedAt = now
+		w.pending = true
+		return false
+	}
+	if w.pending && now.Sub(w.changedAt) >= w.debounce {
+		w.pending = false
+		return true
+	}
+	return false
+}
+
+// addRegistryDirs adds the root and every directory below it to the watcher, since fsnotify
+// doesn't watch recursively. Unreadable entries are skipped so a directory being rewritten
+// doesn't stop the watcher.
+func addRegistryDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchRegistries watches the registry roots for file events and calls recompile once changes
+// have settled, returning when stop receives a signal. Directories created while watching are
+// watched as well. Watcher errors are passed to onError without stopping the watch.
+func watchRegistries(roots []string, recompile func(), onError func(error), stop <-chan os.Signal) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for _, root := range roots {
+		if err := addRegistryDirs(watcher, root); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	watch := registryWatch{debounce: watchDebounce}
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// Attribute-only changes don't affect the registry contents
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, statErr := os.Stat(event.Name); statErr == nil && info.IsDir() {
+					if addErr := addRegistryDirs(watcher, event.Name); addErr != nil {
+						onError(addErr)
+					}
+				}
+			}
+			watch.observe(true, time.Now())
+		case watchErr, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			onError(watchErr)
+		case now := <-ticker.C:
+			if watch.observe(false, now) {
+				recompile()
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRegistryDirs_WatchesNestedDirectories(t *testing.T) {
+	root := t.TempDir()
+	modelsDir := filepath.Join(root, "models", "billing")
+	require.NoError(t, os.MkdirAll(modelsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "person.mod"), []byte("name: Person\n"), 0644))
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	require.NoError(t, addRegistryDirs(watcher, root))
+
+	assert.ElementsMatch(t, []string{root, filepath.Join(root, "models"), modelsDir}, watcher.WatchList())
+}
+
+func TestAddRegistryDirs_MissingRoot(t *testing.T) {
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	assert.Error(t, addRegistryDirs(watcher, filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestRegistryWatch_DebouncesRapidChanges(t *testing.T) {
+	start := time.Now()
+	watch := registryWatch{debounce: 500 * time.Millisecond}
+
+	// Several saves in quick succession keep postponing the recompile
+	assert.False(t, watch.observe(true, start))
+	assert.False(t, watch.observe(true, start.Add(250*time.Millisecond)))
+	assert.False(t, watch.observe(false, start.Add(500*time.Millisecond)))
+
+	// Once the last change has settled for the debounce period, exactly one recompile is due
+	assert.True(t, watch.observe(false, start.Add(750*time.Millisecond)))
+	assert.False(t, watch.observe(false, start.Add(time.Second)))
+}
+
+func TestRegistryWatch_NoChanges(t *testing.T) {
+	start := time.Now()
+	watch := registryWatch{debounce: 500 * time.Millisecond}
+
+	assert.False(t, watch.observe(false, start))
+	assert.False(t, watch.observe(false, start.Add(time.Second)))
+}
+
+func TestWatchRegistries_RecompilesOnChange(t *testing.T) {
+	root := t.TempDir()
+	recompiled := make(chan struct{}, 1)
+	stop := make(chan os.Signal)
+	done := make(chan error)
+	go func() {
+		done <- watchRegistries([]string{root}, func() {
+			recompiled <- struct{}{}
+		}, func(err error) {
+			t.Error(err)
+		}, stop)
+	}()
+
+	// Give the watcher time to register the root before writing
+	time.Sleep(watchInterval)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "person.mod"), []byte("name: Person\n"), 0644))
+
+	select {
+	case <-recompiled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a recompile after the registry changed")
+	}
+	close(stop)
+	assert.NoError(t, <-done)
+}
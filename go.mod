@@ -3,6 +3,7 @@ module github.com/kalo-build/plugin-morphe-pydantic-types
 go 1.21.6
 
 require (
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/kalo-build/go-util v0.0.0-20250329083327-00e97aeff9b7
 	github.com/kalo-build/morphe-go v0.0.0-20260315110949-bffc845469fb
 	github.com/stretchr/testify v1.9.0
@@ -12,5 +13,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kalo-build/clone v0.0.0-20250329082958-41db0353412f // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,50 @@
+package compile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// awareDatetimeFieldNames returns the Python names of the datetime fields checked for a timezone
+// by a validator. Only Pydantic v1 needs one, v2 types the fields AwareDatetime instead.
+// Navigation and derived fields aren't validated.
+func awareDatetimeFieldNames(fields []formatdef.Field, config PydanticConfig) []string {
+	if !config.AwareDatetimes || config.PydanticV2 {
+		return nil
+	}
+	var fieldNames []string
+	for _, field := range fields {
+		if strings.HasPrefix(field.Name, "_nav_") || field.IsComputed || field.IsClassVar {
+			continue
+		}
+		if field.Type.GetName() == formatdef.TypeDateTime.Name {
+			fieldNames = append(fieldNames, config.pythonFieldName(field.Name))
+		}
+	}
+	return fieldNames
+}
+
+// writeAwareDatetimeValidator writes a Pydantic v1 validator rejecting naive values of the
+// datetime fields
+func writeAwareDatetimeValidator(cb *formatdef.ContentBuilder, fieldNames []string) {
+	if len(fieldNames) == 0 {
+		return
+	}
+	quoted := make([]string, 0, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		quoted = append(quoted, fmt.Sprintf("%q", fieldName))
+	}
+	cb.Line("")
+	cb.Line("@validator(%s)", strings.Join(quoted, ", "))
+	cb.Line("def require_aware_datetimes(cls, value):")
+	cb.Indent()
+	cb.Line(`"""Reject datetimes without a timezone."""`)
+	cb.Line("if value is not None and value.tzinfo is None:")
+	cb.Indent()
+	cb.Line(`raise ValueError("datetime must be timezone-aware")`)
+	cb.Dedent()
+	cb.Line("return value")
+	cb.Dedent()
+}
@@ -0,0 +1,13 @@
+package cfg
+
+import "fmt"
+
+// ConfigValidationError is returned when a configuration option holds an invalid value
+type ConfigValidationError struct {
+	Option string // Configuration key, e.g. "entities.lazyLoadingStyle"
+	Reason string
+}
+
+func (e *ConfigValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Option, e.Reason)
+}
@@ -1,6 +1,9 @@
 package cfg
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // MorpheConfig contains configuration for all Morphe type categories
 // This is a simplified version without language-specific details
@@ -10,16 +13,94 @@ type MorpheConfig struct {
 	Models     ModelConfig     `json:"models,omitempty"`
 	Structures StructureConfig `json:"structures,omitempty"`
 	Entities   EntityConfig    `json:"entities,omitempty"`
+
+	// FieldNameConvention rejects Morphe field names not matching "camelCase", "PascalCase"
+	// or "snake_case" before generation (empty disables the check)
+	FieldNameConvention string `json:"fieldNameConvention,omitempty"`
 }
 
+// Field naming conventions
+const (
+	FieldNameConventionCamelCase  = "camelCase"
+	FieldNameConventionPascalCase = "PascalCase"
+	FieldNameConventionSnakeCase  = "snake_case"
+)
+
 // EnumConfig contains configuration specific to enum generation
 type EnumConfig struct {
 	// GenerateHelpers controls whether to generate helper methods
 	GenerateStrMethod bool `json:"generateStrMethod,omitempty"`
 	// UseStrEnum uses StrEnum for string-based enums (Python 3.11+)
 	UseStrEnum bool `json:"useStrEnum,omitempty"`
+	// GenerateDefaultConstants emits DEFAULT_<ENUM> module-level constants in constants.py
+	GenerateDefaultConstants bool `json:"generateDefaultConstants,omitempty"`
+	// DefaultMembers maps enum names to the entry used as their default
+	DefaultMembers map[string]string `json:"defaultMembers,omitempty"`
+	// Collections maps RootModel wrapper names to the enum they hold a list of (e.g. Statuses: Status)
+	Collections map[string]string `json:"collections,omitempty"`
+	// Docs maps enum names to their class docstring and per-member comments
+	Docs map[string]EnumDoc `json:"docs,omitempty"`
+	// GenerateAliases emits a _missing_ hook resolving configured alias values to their member
+	GenerateAliases bool `json:"generateAliases,omitempty"`
+	// Aliases maps enum names to Morphe entry names and the legacy values accepted for them
+	Aliases map[string]map[string][]string `json:"aliases,omitempty"`
+	// GenerateEnumLiterals emits a <Enum>Literal = Literal[...] alias next to each enum and types
+	// model enum fields with it
+	GenerateEnumLiterals bool `json:"generateEnumLiterals,omitempty"`
+	// DeferCyclicImports imports the enums of models in a relationship cycle under
+	// TYPE_CHECKING and references them by quoted annotations, like the related models
+	DeferCyclicImports bool `json:"deferCyclicImports,omitempty"`
+	// MemberNameCase controls how member names are derived from entry names: "upper" (default)
+	// converts them to UPPER_SNAKE, "as_is" keeps them unchanged; values are emitted verbatim
+	MemberNameCase string `json:"memberNameCase,omitempty"`
+	// BaseClass is the class generated enums extend instead of Enum (IntEnum for integer enums)
+	BaseClass string `json:"baseClass,omitempty"`
+	// BaseImport is the module BaseClass is imported from; required unless it is a class of the
+	// standard enum module
+	BaseImport string `json:"baseImport,omitempty"`
 }
 
+// Enum member name cases
+const (
+	MemberNameCaseUpper = "upper"
+	MemberNameCaseAsIs  = "as_is"
+)
+
+// builtinEnumBases are the enum module classes usable as a base class without a BaseImport
+var builtinEnumBases = map[string]bool{
+	"Enum":    true,
+	"IntEnum": true,
+	"StrEnum": true,
+	"Flag":    true,
+	"IntFlag": true,
+}
+
+// BaseModule returns the module the enum base class is imported from
+func (config EnumConfig) BaseModule() string {
+	if config.BaseImport == "" && builtinEnumBases[config.BaseClass] {
+		return "enum"
+	}
+	return config.BaseImport
+}
+
+// EnumDoc documents an enum and its members
+type EnumDoc struct {
+	Description string            `json:"description,omitempty"`
+	Members     map[string]string `json:"members,omitempty"` // Morphe entry name to comment
+}
+
+// Model defaults policies
+const (
+	DefaultsPolicyNoneEverywhere   = "none-everywhere"
+	DefaultsPolicyEmptyCollections = "empty-collections"
+)
+
+// Collection types of many-relationship navigations
+const (
+	CollectionTypeList     = "list"
+	CollectionTypeSequence = "sequence"
+)
+
 // ModelConfig contains configuration specific to model generation
 type ModelConfig struct {
 	// UseField controls whether to use Pydantic Field for model fields
@@ -28,6 +109,104 @@ type ModelConfig struct {
 	GenerateExamples bool `json:"generateExamples,omitempty"`
 	// UseValidators generates Pydantic validators for common patterns
 	UseValidators bool `json:"useValidators,omitempty"`
+	// GenerateCollectionCounts adds @computed_field count properties for many-relationships (Pydantic v2)
+	GenerateCollectionCounts bool `json:"generateCollectionCounts,omitempty"`
+	// UseUnsetSentinel types optional fields as X | None | Unset defaulting to UNSET (PATCH semantics)
+	UseUnsetSentinel bool `json:"useUnsetSentinel,omitempty"`
+	// OnlyModels limits model compilation to these changed models and the models depending on
+	// them. Enums, structures and entities are still fully regenerated, and it can't be combined
+	// with singleFile.
+	OnlyModels []string `json:"onlyModels,omitempty"`
+	// GenerateStubs writes a .pyi stub with an explicit __init__ signature next to each model
+	GenerateStubs bool `json:"generateStubs,omitempty"`
+	// DefaultsPolicy controls how optional fields default: "none-everywhere" (default) or
+	// "empty-collections", which defaults optional lists and dicts to empty containers
+	DefaultsPolicy string `json:"defaultsPolicy,omitempty"`
+	// GenerateModelSerializer adds a @model_serializer hook returning dict(self) (Pydantic v2)
+	GenerateModelSerializer bool `json:"generateModelSerializer,omitempty"`
+	// AnnotatedStyle moves field constraints and metadata into Annotated[T, Field(...)] hints,
+	// leaving only the default on the right-hand side
+	AnnotatedStyle bool `json:"annotatedStyle,omitempty"`
+	// UseForwardRef renders relationship forward references as ForwardRef("X") instead of "X"
+	UseForwardRef bool `json:"useForwardRef,omitempty"`
+	// DefaultEmptyCollections types many-relationships as List[X] = Field(default_factory=list)
+	// without changing other optional fields (the "empty-collections" policy covers both)
+	DefaultEmptyCollections bool `json:"defaultEmptyCollections,omitempty"`
+	// PopulateByName lets aliased fields be populated by their field name (populate_by_name in
+	// Pydantic v2, allow_population_by_field_name in v1)
+	PopulateByName bool `json:"populateByName,omitempty"`
+	// BaseClasses maps model names to the class they extend; "*" sets the default (BaseModel otherwise)
+	BaseClasses map[string]string `json:"baseClasses,omitempty"`
+	// AbstractModels are generated as abstract bases raising TypeError when instantiated
+	// directly; models extending them through BaseClasses inherit their fields
+	AbstractModels []string `json:"abstractModels,omitempty"`
+	// BaseClassModules maps custom base class names to the module they are imported from
+	BaseClassModules map[string]string `json:"baseClassModules,omitempty"`
+	// ConstraintStyle controls how string constraints render: "field" (default) as Field(...)
+	// keyword arguments, or "annotated" as Annotated[str, StringConstraints(...)] (Pydantic v2)
+	ConstraintStyle string `json:"constraintStyle,omitempty"`
+	// OptionalGeneratedIds types database-generated ids (AutoIncrement fields and fields marked
+	// auto, sequence or identity) as Optional[T] = None so they aren't required on input
+	OptionalGeneratedIds bool `json:"optionalGeneratedIds,omitempty"`
+	// ImmutableIds marks primary and foreign key fields Field(frozen=True) so they can't change
+	// after construction, leaving other fields mutable (Pydantic v2)
+	ImmutableIds bool `json:"immutableIds,omitempty"`
+	// ValidationAlias derives the alias fields are read from ("camelCase", "PascalCase" or
+	// "snake_case") from the Morphe field name (validation_alias in Pydantic v2)
+	ValidationAlias string `json:"validationAlias,omitempty"`
+	// SerializationAlias derives the alias fields are written as from the Morphe field name
+	// (serialization_alias in Pydantic v2); v1 supports a single alias for both directions
+	SerializationAlias string `json:"serializationAlias,omitempty"`
+	// UseConfigDict emits model_config = ConfigDict(...) instead of a dict literal (Pydantic v2)
+	UseConfigDict bool `json:"useConfigDict,omitempty"`
+	// ExcludeLazyRelations marks navigation properties Field(exclude=True) so model_dump()
+	// omits relations that may only be partially loaded
+	ExcludeLazyRelations bool `json:"excludeLazyRelations,omitempty"`
+	// ValidateDefaults adds validate_default=True to optional fields so their None default
+	// also runs through validation (Pydantic v2)
+	ValidateDefaults bool `json:"validateDefaults,omitempty"`
+	// CollectionType types many-relationship navigations as "list" (default) or as the
+	// read-only "sequence", signalling that the related collection isn't mutated in place
+	CollectionType string `json:"collectionType,omitempty"`
+	// DisableHash emits __hash__ = None on the generated models, which are never frozen, so
+	// they can't be hashed by accident (e.g. through a base class defining __hash__)
+	DisableHash bool `json:"disableHash,omitempty"`
+	// GenerateSchemaExamples adds a synthesized example instance to the model's JSON schema
+	// (json_schema_extra in Pydantic v2, schema_extra in v1) for OpenAPI docs
+	GenerateSchemaExamples bool `json:"generateSchemaExamples,omitempty"`
+	// HoistTypeAliases declares the Union and Annotated types shared by several fields of a
+	// model once, as module-level TypeAlias declarations referenced by the fields
+	HoistTypeAliases bool `json:"hoistTypeAliases,omitempty"`
+}
+
+// Model constraint styles
+const (
+	ConstraintStyleField     = "field"
+	ConstraintStyleAnnotated = "annotated"
+)
+
+// DefaultBaseClass is the base class of models without a configured base
+const DefaultBaseClass = "BaseModel"
+
+// BaseClassFor returns the base class a model extends
+func (config ModelConfig) BaseClassFor(modelName string) string {
+	if base, exists := config.BaseClasses[modelName]; exists {
+		return base
+	}
+	if base, exists := config.BaseClasses["*"]; exists {
+		return base
+	}
+	return DefaultBaseClass
+}
+
+// IsAbstract reports whether a model is generated as an abstract base
+func (config ModelConfig) IsAbstract(modelName string) bool {
+	for _, abstractModel := range config.AbstractModels {
+		if abstractModel == modelName {
+			return true
+		}
+	}
+	return false
 }
 
 // StructureConfig contains configuration specific to structure generation
@@ -36,33 +215,146 @@ type StructureConfig struct {
 	UseDataclass bool `json:"useDataclass,omitempty"`
 	// GenerateSlots adds __slots__ for memory efficiency
 	GenerateSlots bool `json:"generateSlots,omitempty"`
+	// Invariants maps structure names to cross-field constraints (e.g. "Start < End"), each
+	// rendered as a validator method, or checked in __post_init__ with UseDataclass
+	Invariants map[string][]string `json:"invariants,omitempty"`
 }
 
 // EntityConfig contains configuration specific to entity generation
 type EntityConfig struct {
 	// GenerateRepository generates repository pattern methods
 	GenerateRepository bool `json:"generateRepository,omitempty"`
-	// LazyLoadingStyle controls lazy loading implementation
-	LazyLoadingStyle string `json:"lazyLoadingStyle,omitempty"` // "async", "sync", "property"
+	// LazyLoadingStyle controls how relationships render: "async" (default) or "sync" load_x()
+	// loader methods, "property" lazily resolving @property methods, or "eager" plain optional fields
+	LazyLoadingStyle string `json:"lazyLoadingStyle,omitempty"`
 	// IncludeValidation adds validation methods
 	IncludeValidation bool `json:"includeValidation,omitempty"`
 }
 
+// Entity lazy loading styles
+const (
+	LazyLoadingStyleAsync    = "async"
+	LazyLoadingStyleSync     = "sync"
+	LazyLoadingStyleProperty = "property"
+	LazyLoadingStyleEager    = "eager"
+)
+
 // Validate checks if the configuration is valid
 func (config MorpheConfig) Validate() error {
 	// Validate entity lazy loading style
-	if config.Entities.LazyLoadingStyle != "" {
-		validStyles := map[string]bool{
-			"async":    true,
-			"sync":     true,
-			"property": true,
+	switch config.Entities.LazyLoadingStyle {
+	case "", LazyLoadingStyleAsync, LazyLoadingStyleSync, LazyLoadingStyleProperty, LazyLoadingStyleEager:
+	default:
+		return &ConfigValidationError{
+			Option: "entities.lazyLoadingStyle",
+			Reason: fmt.Sprintf("%s (must be '%s', '%s', '%s' or '%s')", config.Entities.LazyLoadingStyle,
+				LazyLoadingStyleAsync, LazyLoadingStyleSync, LazyLoadingStyleProperty, LazyLoadingStyleEager),
+		}
+	}
+
+	// Validate enum member name case
+	switch config.Enums.MemberNameCase {
+	case "", MemberNameCaseUpper, MemberNameCaseAsIs:
+	default:
+		return &ConfigValidationError{
+			Option: "enums.memberNameCase",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.Enums.MemberNameCase,
+				MemberNameCaseUpper, MemberNameCaseAsIs),
+		}
+	}
+
+	// Validate enum base class
+	if config.Enums.BaseClass != "" && config.Enums.BaseModule() == "" {
+		return &ConfigValidationError{
+			Option: "enums.baseImport",
+			Reason: fmt.Sprintf("required for enum base class %s", config.Enums.BaseClass),
 		}
-		if !validStyles[config.Entities.LazyLoadingStyle] {
-			return fmt.Errorf("invalid lazy loading style: %s (must be 'async', 'sync', or 'property')",
-				config.Entities.LazyLoadingStyle)
+	}
+	if config.Enums.BaseClass == "" && config.Enums.BaseImport != "" {
+		return &ConfigValidationError{
+			Option: "enums.baseImport",
+			Reason: fmt.Sprintf("%s set without enums.baseClass", config.Enums.BaseImport),
+		}
+	}
+
+	// Validate model defaults policy
+	switch config.Models.DefaultsPolicy {
+	case "", DefaultsPolicyNoneEverywhere, DefaultsPolicyEmptyCollections:
+	default:
+		return &ConfigValidationError{
+			Option: "models.defaultsPolicy",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.Models.DefaultsPolicy,
+				DefaultsPolicyNoneEverywhere, DefaultsPolicyEmptyCollections),
+		}
+	}
+
+	switch config.Models.CollectionType {
+	case "", CollectionTypeList, CollectionTypeSequence:
+	default:
+		return &ConfigValidationError{
+			Option: "models.collectionType",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.Models.CollectionType,
+				CollectionTypeList, CollectionTypeSequence),
+		}
+	}
+
+	// Validate model alias cases, in a fixed order so the same option is always reported first
+	aliasCases := []struct {
+		option    string
+		aliasCase string
+	}{
+		{"models.validationAlias", config.Models.ValidationAlias},
+		{"models.serializationAlias", config.Models.SerializationAlias},
+	}
+	for _, alias := range aliasCases {
+		switch alias.aliasCase {
+		case "", FieldNameConventionCamelCase, FieldNameConventionPascalCase, FieldNameConventionSnakeCase:
+		default:
+			return &ConfigValidationError{
+				Option: alias.option,
+				Reason: fmt.Sprintf("%s (must be '%s', '%s' or '%s')", alias.aliasCase,
+					FieldNameConventionCamelCase, FieldNameConventionPascalCase, FieldNameConventionSnakeCase),
+			}
+		}
+	}
+
+	// Validate that custom model base classes can be imported; abstract models are generated
+	var baseModelNames []string
+	for modelName := range config.Models.BaseClasses {
+		baseModelNames = append(baseModelNames, modelName)
+	}
+	sort.Strings(baseModelNames)
+	for _, modelName := range baseModelNames {
+		base := config.Models.BaseClasses[modelName]
+		if _, hasModule := config.Models.BaseClassModules[base]; base != DefaultBaseClass && !hasModule && !config.Models.IsAbstract(base) {
+			return &ConfigValidationError{
+				Option: "models.baseClasses",
+				Reason: fmt.Sprintf("%s base %s has no module in models.baseClassModules", modelName, base),
+			}
+		}
+	}
+
+	// Validate model constraint style
+	switch config.Models.ConstraintStyle {
+	case "", ConstraintStyleField, ConstraintStyleAnnotated:
+	default:
+		return &ConfigValidationError{
+			Option: "models.constraintStyle",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.Models.ConstraintStyle,
+				ConstraintStyleField, ConstraintStyleAnnotated),
+		}
+	}
+
+	// Validate field naming convention
+	switch config.FieldNameConvention {
+	case "", FieldNameConventionCamelCase, FieldNameConventionPascalCase, FieldNameConventionSnakeCase:
+	default:
+		return &ConfigValidationError{
+			Option: "fieldNameConvention",
+			Reason: fmt.Sprintf("%s (must be '%s', '%s' or '%s')", config.FieldNameConvention,
+				FieldNameConventionCamelCase, FieldNameConventionPascalCase, FieldNameConventionSnakeCase),
 		}
 	}
 
-	// No other validations needed as all other options are boolean flags
 	return nil
 }
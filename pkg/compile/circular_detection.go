@@ -2,6 +2,7 @@ package compile
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/kalo-build/morphe-go/pkg/yaml"
@@ -73,6 +74,60 @@ func buildDependencyGraph(models map[string]yaml.Model) map[string][]string {
 	return graph
 }
 
+// modelRebuildOrder returns the models with relationships, whose annotations hold forward
+// references, ordered so each model follows the models it references; cycles are broken in
+// alphabetical order
+func modelRebuildOrder(models map[string]yaml.Model) []string {
+	graph := buildDependencyGraph(models)
+	var modelNames []string
+	for modelName := range models {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+
+	var order []string
+	visited := make(map[string]bool)
+	var visit func(modelName string)
+	visit = func(modelName string) {
+		if visited[modelName] {
+			return
+		}
+		visited[modelName] = true
+		deps := append([]string{}, graph[modelName]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, exists := models[dep]; exists {
+				visit(dep)
+			}
+		}
+		if len(models[modelName].Related) > 0 {
+			order = append(order, modelName)
+		}
+	}
+	for _, modelName := range modelNames {
+		visit(modelName)
+	}
+	return order
+}
+
+// inCycle reports whether a model can reach itself through its relationships
+func inCycle(modelName string, graph map[string][]string) bool {
+	visited := make(map[string]bool)
+	pending := append([]string{}, graph[modelName]...)
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if node == modelName {
+			return true
+		}
+		if !visited[node] {
+			visited[node] = true
+			pending = append(pending, graph[node]...)
+		}
+	}
+	return false
+}
+
 // dfsDetectCycles performs DFS to detect cycles
 func dfsDetectCycles(node string, graph map[string][]string, visited, recStack map[string]bool, path []string) []CircularDependency {
 	visited[node] = true
@@ -165,3 +220,40 @@ func contains(slice []string, value string) bool {
 	}
 	return false
 }
+
+// ResolveAffectedModels returns the changed models together with every model that references
+// one of them, directly or transitively, through its relationships
+func ResolveAffectedModels(models map[string]yaml.Model, changed []string) (map[string]bool, error) {
+	// Invert the dependency graph so each model points at the models referencing it
+	dependents := make(map[string][]string)
+	for modelName, deps := range buildDependencyGraph(models) {
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], modelName)
+		}
+	}
+
+	affected := make(map[string]bool)
+	queue := []string{}
+	for _, modelName := range changed {
+		if _, exists := models[modelName]; !exists {
+			return nil, &ModelNotFoundError{Model: modelName}
+		}
+		if !affected[modelName] {
+			affected[modelName] = true
+			queue = append(queue, modelName)
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[current] {
+			if !affected[dependent] {
+				affected[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	return affected, nil
+}
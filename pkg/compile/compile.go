@@ -9,21 +9,100 @@ import (
 
 // MorpheToPydantic compiles a Morphe registry to Python with Pydantic models
 func MorpheToPydantic(config MorpheCompileConfig) error {
-	// Load the Morphe registry
-	r, rErr := registry.LoadMorpheRegistry(registry.LoadMorpheRegistryHooks{}, config.MorpheLoadRegistryConfig)
+	files, stats, err := CompileToMemoryWithStats(config)
+	if err != nil {
+		return err
+	}
+
+	if err := NewMorpheWriter(config.OutputPath).WriteFiles(files); err != nil {
+		return err
+	}
+	if config.Verbose {
+		fmt.Fprint(config.output(), stats.String())
+	}
+	return nil
+}
+
+// StaleOutputFiles compiles in memory and returns the generated files that are missing from or
+// differ with the output directory, without writing anything
+func StaleOutputFiles(config MorpheCompileConfig) ([]StaleFile, error) {
+	files, err := CompileToMemory(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMorpheWriter(config.OutputPath).StaleFiles(files)
+}
+
+// CompileToMemory runs the full compilation pipeline and returns the generated files keyed by
+// their path relative to the output directory, without touching the filesystem
+func CompileToMemory(config MorpheCompileConfig) (map[string]string, error) {
+	files, _, err := CompileToMemoryWithStats(config)
+	return files, err
+}
+
+// CompileToMemoryWithStats runs the full compilation pipeline in memory like CompileToMemory,
+// also returning statistics about the compiled types and generated files
+func CompileToMemoryWithStats(config MorpheCompileConfig) (map[string]string, CompileStats, error) {
+	var stats CompileStats
+
+	// Load and merge the Morphe registries
+	r, rErr := LoadMorpheRegistries(config.RegistryConfigs())
 	if rErr != nil {
-		return fmt.Errorf("failed to load morphe registry: %w", rErr)
+		return nil, stats, fmt.Errorf("failed to load morphe registry: %w", rErr)
+	}
+
+	// Restrict the registry to the requested types and their dependencies
+	if len(config.Only) > 0 {
+		var err error
+		r, err = SelectTypes(r, config.Only)
+		if err != nil {
+			return nil, stats, fmt.Errorf("failed to select types: %w", err)
+		}
+	}
+
+	writer := NewMemoryWriter()
+	if err := compileRegistry(config, r, writer, &stats); err != nil {
+		return nil, stats, err
+	}
+	files := writer.Files()
+
+	// Publish the stubs separately as a stub-only package
+	if config.FormatConfig.GenerateStubPackage {
+		addStubPackage(files, config.stubPackageName())
 	}
 
-	// Initialize the writer
-	writer := NewMorpheWriter(config.OutputPath)
+	// Catch generation bugs that only surface when Python imports the output
+	if config.FormatConfig.ValidateSyntax {
+		if err := CheckPythonSyntax(files, config.warnings()); err != nil {
+			return nil, stats, err
+		}
+	}
+	stats.addFiles(files)
+	return files, stats, nil
+}
+
+// compileRegistry compiles every type category of the registry using the writer, counting the
+// compiled types into stats
+func compileRegistry(config MorpheCompileConfig, r *registry.Registry, writer *MorpheWriter, stats *CompileStats) error {
+	// Enforce input field naming hygiene before generating anything
+	if err := ValidateFieldNameConvention(r, config.MorpheConfig.FieldNameConvention); err != nil {
+		return err
+	}
+	writer.useConfig(config.FormatConfig)
+
+	// Leave relationships of an unknown type out of the stats, graph and entities too
+	if config.FormatConfig.UnknownRelations == UnknownRelationsWarn {
+		r = withoutUnknownRelations(config, r)
+	}
 
 	// Process enums if present
 	if r.HasEnums() {
-		fmt.Println("Compiling enums...")
+		config.logf("Compiling enums...")
 		if err := CompileAllEnums(config, r, writer); err != nil {
 			return fmt.Errorf("failed to compile enums: %w", err)
 		}
+		stats.Enums = len(r.GetAllEnums())
 	}
 
 	// Process models if present
@@ -31,29 +110,43 @@ func MorpheToPydantic(config MorpheCompileConfig) error {
 		// Check for circular dependencies
 		cycles := DetectCircularDependencies(r.GetAllModels())
 		if len(cycles) > 0 {
-			fmt.Println("Warning: Circular dependencies detected in models:")
+			config.logf("Circular dependencies detected in models:")
 			for _, cycle := range cycles {
-				fmt.Printf("  - %s\n", cycle.String())
+				config.logf("  - %s", cycle.String())
 			}
-			fmt.Println("Note: Using TYPE_CHECKING imports to handle circular dependencies")
+			config.logf("Note: Using TYPE_CHECKING imports to handle circular dependencies")
 		}
 
-		fmt.Println("Compiling models...")
+		config.logf("Compiling models...")
 		if err := CompileAllModels(config, r, writer); err != nil {
 			return fmt.Errorf("failed to compile models: %w", err)
 		}
+		stats.addModels(r, config.FormatConfig)
+
+		// Export the relationship graph for documentation
+		if config.FormatConfig.GenerateGraph != "" {
+			if err := writeModelGraph(config.FormatConfig.GenerateGraph, r, writer); err != nil {
+				return fmt.Errorf("failed to write model graph: %w", err)
+			}
+		}
 	}
 
 	// Process structures if present
 	if r.HasStructures() {
-		fmt.Println("Compiling structures...")
+		config.logf("Compiling structures...")
 		if err := CompileAllStructures(config, r, writer); err != nil {
 			return fmt.Errorf("failed to compile structures: %w", err)
 		}
+		stats.Structures = len(r.GetAllStructures())
+	}
+
+	// Entities share their names with models and can't be bundled, so say they're left out
+	if r.HasEntities() && config.FormatConfig.SingleFile {
+		config.warnf("singleFile does not generate entities; skipped %d", len(r.GetAllEntities()))
 	}
 
 	// Process entities if present
-	if r.HasEntities() {
+	if r.HasEntities() && !config.FormatConfig.SingleFile {
 		// Entities depend on models
 		if !r.HasModels() {
 			return fmt.Errorf("entities compilation requires models to be compiled")
@@ -62,22 +155,62 @@ func MorpheToPydantic(config MorpheCompileConfig) error {
 		// Check for circular dependencies in entities
 		entityCycles := DetectCircularDependencies(convertEntitiesToModels(r.GetAllEntities()))
 		if len(entityCycles) > 0 {
-			fmt.Println("Warning: Circular dependencies detected in entities:")
+			config.logf("Circular dependencies detected in entities:")
 			for _, cycle := range entityCycles {
-				fmt.Printf("  - %s\n", cycle.String())
+				config.logf("  - %s", cycle.String())
 			}
-			fmt.Println("Note: Using TYPE_CHECKING imports to handle circular dependencies")
+			config.logf("Note: Using TYPE_CHECKING imports to handle circular dependencies")
 		}
 
-		fmt.Println("Compiling entities...")
+		config.logf("Compiling entities...")
 		if err := CompileAllEntities(config, r, writer); err != nil {
 			return fmt.Errorf("failed to compile entities: %w", err)
 		}
+		stats.Entities = len(r.GetAllEntities())
+	}
+
+	// Export the JSON Schema for clients in other languages
+	if config.FormatConfig.EmitJSONSchema {
+		content, err := GenerateJSONSchema(config, r)
+		if err != nil {
+			return fmt.Errorf("failed to generate JSON schema: %w", err)
+		}
+		if err := writer.WriteJSONSchema(content); err != nil {
+			return fmt.Errorf("failed to write schema.json: %w", err)
+		}
+	}
+
+	// Mark the package as typed for downstream type checkers
+	if config.FormatConfig.EmitPyTyped {
+		if err := writer.WritePyTyped(); err != nil {
+			return fmt.Errorf("failed to write py.typed: %w", err)
+		}
+	}
+
+	// Make every level of the root package importable
+	if config.FormatConfig.GenerateInit {
+		if err := writer.WriteNamespaceInits(); err != nil {
+			return fmt.Errorf("failed to write root package __init__.py files: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// writeModelGraph renders the model relationship graph in the given format and writes it
+func writeModelGraph(format string, r *registry.Registry, writer *MorpheWriter) error {
+	graph := BuildModelGraph(r.GetAllModels())
+	content := graph.DOT()
+	if format == GraphFormatJSON {
+		var err error
+		content, err = graph.JSON()
+		if err != nil {
+			return err
+		}
+	}
+	return writer.WriteGraph(format, content)
+}
+
 // convertEntitiesToModels converts entities to models for circular dependency checking
 func convertEntitiesToModels(entities map[string]yaml.Entity) map[string]yaml.Model {
 	models := make(map[string]yaml.Model)
@@ -1,6 +1,7 @@
 package compile
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,12 +9,19 @@ import (
 	"github.com/kalo-build/morphe-go/pkg/registry"
 	"github.com/kalo-build/morphe-go/pkg/yaml"
 	"github.com/kalo-build/morphe-go/pkg/yamlops"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/typemap"
 )
 
 // CompileEntity converts a Morphe entity to the target format
 func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct, error) {
+	return compileEntity(entity, r, PydanticConfig{})
+}
+
+// compileEntity converts a Morphe entity, mapping the aggregated model fields with the custom
+// type mappings and strictness of the format config
+func compileEntity(entity yaml.Entity, r *registry.Registry, config PydanticConfig) (*formatdef.Struct, error) {
 	// Create the struct definition
 	formatStruct := &formatdef.Struct{
 		Name:   entity.Name,
@@ -30,9 +38,13 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 	// Process entity fields
 	for _, fieldName := range fieldNames {
 		field := entity.Fields[fieldName]
-		fieldType, err := resolveEntityFieldType(field.Type, r)
+		fieldType, err := resolveEntityFieldType(field.Type, r, config)
+		var unmappedErr *typemap.UnmappedTypeError
+		if errors.As(err, &unmappedErr) {
+			return nil, &UnmappedFieldTypeError{Owner: entity.Name, Field: fieldName, Type: unmappedErr.Type}
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve field type for %s: %w", fieldName, err)
+			return nil, &TypeMapError{Owner: entity.Name, Field: fieldName, Err: err}
 		}
 
 		formatField := formatdef.Field{
@@ -40,6 +52,12 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 			Type:       fieldType,
 			IsOptional: hasAttribute(field.Attributes, "optional"),
 		}
+		if expression, isDerived := attributeValue(field.Attributes, "expression"); isDerived {
+			formatField.IsComputed = true
+			formatField.Expression = expression
+		} else {
+			formatField.IsComputed = hasAttribute(field.Attributes, "computed")
+		}
 		formatStruct.Fields = append(formatStruct.Fields, formatField)
 	}
 
@@ -63,13 +81,15 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 					Name:       formatdef.ToSnakeCase(relatedName + "_type"),
 					Type:       formatdef.TypeString,
 					IsOptional: relOptional,
+					IsImplicit: true,
 				}
 				formatStruct.Fields = append(formatStruct.Fields, typeField)
 
 				idField := formatdef.Field{
 					Name:       formatdef.ToSnakeCase(relatedName + "_id"),
 					Type:       formatdef.TypeString,
-					IsOptional: relOptional,
+					IsOptional: true,
+					IsImplicit: true,
 				}
 				formatStruct.Fields = append(formatStruct.Fields, idField)
 			} else if yamlops.IsRelationPoly(relationType) {
@@ -81,7 +101,8 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 				fkField := formatdef.Field{
 					Name:       formatdef.ToSnakeCase(relatedName + "_id"),
 					Type:       formatdef.TypeString,
-					IsOptional: hasAttribute(relation.Attributes, "optional"),
+					IsOptional: true,
+					IsImplicit: true,
 				}
 				formatStruct.Fields = append(formatStruct.Fields, fkField)
 			}
@@ -97,15 +118,7 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 				// Polymorphic relationships need Union types
 				if len(relation.For) > 0 {
 					// Create a custom type representing the Union
-					unionType := "Union["
-					for i, forModel := range relation.For {
-						if i > 0 {
-							unionType += ", "
-						}
-						unionType += "'" + forModel + "'"
-					}
-					unionType += "]"
-					navType = formatdef.BasicType{Name: unionType}
+					navType = formatdef.BasicType{Name: polymorphicUnion(relation.For)}
 				} else {
 					// No 'for' specified, use Any
 					navType = formatdef.TypeAny
@@ -121,16 +134,14 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 					// Wrap in List for many relationships
 					navType = formatdef.ArrayType{ElementType: navType}
 				}
-				// Pluralize for many relationships
-				navFieldName = formatdef.ToSnakeCase(relatedName) + "s"
-			} else {
-				navFieldName = formatdef.ToSnakeCase(relatedName)
 			}
+			navFieldName = entityNavFieldName(relatedName, relationType, config)
 
 			navField := formatdef.Field{
 				Name:       navFieldName,
 				Type:       navType,
 				IsOptional: hasAttribute(relation.Attributes, "optional"),
+				IsImplicit: true,
 			}
 			formatStruct.Fields = append(formatStruct.Fields, navField)
 		}
@@ -139,8 +150,33 @@ func CompileEntity(entity yaml.Entity, r *registry.Registry) (*formatdef.Struct,
 	return formatStruct, nil
 }
 
-// resolveEntityFieldType resolves a model field path to a concrete type
-func resolveEntityFieldType(fieldPath yaml.ModelFieldPath, r *registry.Registry) (formatdef.Type, error) {
+// entityNavFieldName returns the name of an entity's navigation field in the field case,
+// pluralized for many-relationships
+func entityNavFieldName(relatedName string, relationType string, config PydanticConfig) string {
+	if yamlops.IsRelationMany(relationType) {
+		return config.pythonFieldName(relatedName) + "s"
+	}
+	return config.pythonFieldName(relatedName)
+}
+
+// quotedNavType quotes the entity names of a navigation type as forward references, leaving
+// Union and Any types as they are
+func quotedNavType(navType formatdef.Type) formatdef.Type {
+	switch t := navType.(type) {
+	case formatdef.ArrayType:
+		return formatdef.ArrayType{ElementType: quotedNavType(t.ElementType), Builtin: t.Builtin}
+	case formatdef.BasicType:
+		if strings.HasPrefix(t.Name, "Union[") || t.Name == formatdef.TypeAny.Name {
+			return t
+		}
+		return formatdef.BasicType{Name: `"` + t.Name + `"`}
+	}
+	return navType
+}
+
+// resolveEntityFieldType resolves a model field path to a concrete type. The field is inlined
+// on the entity as a denormalized value: reaching it through a many-relationship yields a list.
+func resolveEntityFieldType(fieldPath yaml.ModelFieldPath, r *registry.Registry, config PydanticConfig) (formatdef.Type, error) {
 	// Split the path (e.g., "User.email" or "User.ContactInfo.email")
 	parts := strings.Split(string(fieldPath), ".")
 	if len(parts) < 2 {
@@ -150,24 +186,31 @@ func resolveEntityFieldType(fieldPath yaml.ModelFieldPath, r *registry.Registry)
 	// Get the root model
 	currentModel, err := r.GetModel(parts[0])
 	if err != nil {
-		return nil, fmt.Errorf("model %s not found", parts[0])
+		return nil, &ModelNotFoundError{Model: parts[0]}
 	}
 
 	// Navigate through the path
+	throughMany := false
 	for i := 1; i < len(parts)-1; i++ {
 		// This is a related model
 		relation, exists := currentModel.Related[parts[i]]
 		if !exists {
-			return nil, fmt.Errorf("relation %s not found in model %s", parts[i], currentModel.Name)
+			return nil, &RelationResolveError{Model: currentModel.Name, Relation: parts[i]}
+		}
+
+		if yamlops.IsRelationMany(string(relation.Type)) {
+			throughMany = true
 		}
 
 		// Resolve the actual target model name using aliasing
 		targetModelName := yamlops.GetRelationTargetName(parts[i], relation.Aliased)
 
-		// Get the related model using the resolved target name
+		// Get the related model using the resolved target name. GetModel returns a zero
+		// model on failure, so the owning model's name is captured beforehand.
+		ownerName := currentModel.Name
 		currentModel, err = r.GetModel(targetModelName)
 		if err != nil {
-			return nil, fmt.Errorf("related model %s not found", targetModelName)
+			return nil, &RelationResolveError{Model: ownerName, Relation: parts[i], Err: &ModelNotFoundError{Model: targetModelName}}
 		}
 	}
 
@@ -178,8 +221,12 @@ func resolveEntityFieldType(fieldPath yaml.ModelFieldPath, r *registry.Registry)
 		return nil, fmt.Errorf("field %s not found in model %s", fieldName, currentModel.Name)
 	}
 
-	// Return the appropriate type
-	return typemap.GetFieldType(field.Type), nil
+	// Return the appropriate type, consulting the custom type mappings first
+	fieldType, err := mapFieldType(field.Type, r, config)
+	if err != nil || !throughMany {
+		return fieldType, err
+	}
+	return formatdef.ArrayType{ElementType: fieldType}, nil
 }
 
 // resolveFieldType checks if a type name is an enum, model, or basic type
@@ -206,6 +253,12 @@ func addToStringSlice(slice *[]string, value string) {
 	*slice = append(*slice, value)
 }
 
+// isArrayType reports whether a field type renders as a list
+func isArrayType(fieldType formatdef.Type) bool {
+	_, ok := fieldType.(formatdef.ArrayType)
+	return ok
+}
+
 // extractInnerType extracts the inner type from Optional[X], List[X], etc.
 func extractInnerType(typeName string) string {
 	// Handle Optional[X], List[X], etc.
@@ -226,35 +279,65 @@ func extractInnerType(typeName string) string {
 
 // CompileAllEntities compiles all entities and writes them using the writer
 func CompileAllEntities(config MorpheCompileConfig, r *registry.Registry, writer *MorpheWriter) error {
+	writer.useConfig(config.FormatConfig)
+
 	entityContents := make(map[string][]byte)
 
 	// Process each entity in the registry
 	for entityName, entity := range r.GetAllEntities() {
 		// Compile the entity
-		compiledEntity, err := CompileEntity(entity, r)
+		compiledEntity, err := compileEntity(entity, r, config.FormatConfig)
 		if err != nil {
 			return fmt.Errorf("failed to compile entity %s: %w", entityName, err)
 		}
 
+		// Python 3.9+ targets use the builtin list/dict generics
+		if config.FormatConfig.PythonVersionAtLeast(3, 9) {
+			compiledEntity.UseBuiltinGenerics()
+		}
+
 		// Generate the content for this entity
-		content := generateEntityContent(compiledEntity, entity, config.FormatConfig, r)
+		content := generateEntityContent(compiledEntity, entity, config.FormatConfig, config.MorpheConfig, r)
 		entityContents[entityName] = content
 	}
 
+	// The entities __init__.py resolves the forward references between entities
+	writer.RebuildEntities = modelRebuildOrder(convertEntitiesToModels(r.GetAllEntities()))
+
 	// Write all entity contents
 	return writer.WriteAllEntities(entityContents)
 }
 
 // generateEntityContent generates Python entity with relationships and identifiers
-func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, config PydanticConfig, r *registry.Registry) []byte {
-	cb := formatdef.NewContentBuilder("    ")
+func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, config PydanticConfig, morpheConfig cfg.MorpheConfig, r *registry.Registry) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+	lazyLoadingStyle := morpheConfig.Entities.LazyLoadingStyle
+
+	// Navigation fields by name, with their relationship names in sorted order
+	var relNames []string
+	for relName := range morpheEntity.Related {
+		relNames = append(relNames, relName)
+	}
+	sort.Strings(relNames)
+	navFields := make(map[string]bool)
+	for _, relName := range relNames {
+		navFields[entityNavFieldName(relName, string(morpheEntity.Related[relName].Type), config)] = true
+	}
 
 	// Create import tracker
 	imports := NewImportTracker(r)
+	imports.SetFileNaming(config.FileNaming)
 
-	// Add Pydantic imports
+	// Add Pydantic imports; declared fields aliased to their Morphe key stay populatable by name
+	var instanceFields []formatdef.Field
+	for _, field := range entity.Fields {
+		if !field.IsComputed {
+			instanceFields = append(instanceFields, field)
+		}
+	}
+	populateByName := config.AddTypeHints && config.hasFieldKeyAliases(instanceFields)
 	imports.AddPydantic("BaseModel")
-	if config.PydanticV2 {
+	if config.PydanticV2 || populateByName {
 		imports.AddPydantic("Field")
 	}
 
@@ -263,8 +346,14 @@ func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, c
 
 	// Scan all fields to determine imports
 	for _, field := range entity.Fields {
+		// Derived fields become @computed_field properties (Pydantic v2)
+		if field.IsComputed && config.PydanticV2 {
+			imports.AddPydantic("computed_field")
+		}
+
 		typeName := field.Type.GetName()
 		imports.TrackFieldType(typeName)
+		imports.AddStatement(config.customTypeImports(typeName)...)
 
 		// Check for polymorphic type fields
 		if strings.HasSuffix(field.Name, "_type") && typeName == "str" {
@@ -277,13 +366,23 @@ func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, c
 	}
 
 	// We always need these for entities
-	imports.AddTyping("Optional", "List", "TYPE_CHECKING")
+	builtinGenerics := config.PythonVersionAtLeast(3, 9)
+	imports.AddTyping("Optional")
+	if !builtinGenerics {
+		imports.AddTyping("List")
+	}
 
 	// Add Literal if we have polymorphic type fields
 	if hasPolymorphicTypeField {
 		imports.AddTyping("Literal")
 	}
 
+	// Pydantic v1 checks datetimes for a timezone with a validator
+	awareDatetimeFields := awareDatetimeFieldNames(entity.Fields, config)
+	if len(awareDatetimeFields) > 0 {
+		imports.AddPydantic("validator")
+	}
+
 	// Generate imports
 	imports.Generate(cb)
 	cb.Line("")
@@ -308,17 +407,35 @@ func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, c
 		}
 	}
 
-	// Add fields
+	// Add fields; derived fields are emitted as properties below
 	for _, field := range entity.Fields {
-		fieldName := SanitizePythonIdentifier(formatdef.ToSnakeCase(field.Name))
+		if field.IsComputed {
+			continue
+		}
+		fieldName := config.pythonFieldName(field.Name)
 		fieldType := field.Type.GetName()
 
+		// Navigation fields are resolved by properties in the property style, and otherwise are
+		// optional fields whose entities, imported only for type checking, are forward references
+		if navFields[field.Name] {
+			if lazyLoadingStyle == cfg.LazyLoadingStyleProperty {
+				continue
+			}
+			if config.AddTypeHints {
+				cb.Line("%s: Optional[%s] = None", fieldName, quotedNavType(field.Type).GetName())
+			} else {
+				cb.Line("%s = None", fieldName)
+			}
+			continue
+		}
+
 		// Add identifier comment
 		if idType, isIdentifier := identifierFields[field.Name]; isIdentifier {
 			cb.Line("# %s identifier", idType)
 		}
 
 		if config.AddTypeHints {
+			var annotation, defaultValue string
 			// Check if this is a polymorphic type field
 			if strings.HasSuffix(field.Name, "_type") && fieldType == "str" {
 				// Look for the corresponding relationship to get allowed types
@@ -328,58 +445,53 @@ func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, c
 				if relation, exists := morpheEntity.Related[relName]; exists && len(relation.For) > 0 {
 					// Build Literal type with allowed values
 					var allowedTypes []string
-					for _, forModel := range relation.For {
+					for _, forModel := range polymorphicCandidates(relation.For) {
 						allowedTypes = append(allowedTypes, fmt.Sprintf("\"%s\"", forModel))
 					}
-					cb.Line("%s: Literal[%s]", fieldName, strings.Join(allowedTypes, ", "))
+					annotation = fmt.Sprintf("Literal[%s]", strings.Join(allowedTypes, ", "))
 				} else {
-					cb.Line("%s: str", fieldName)
+					annotation = "str"
 				}
-			} else if strings.HasPrefix(fieldType, "Optional[") || strings.HasPrefix(fieldType, "List[") || strings.Contains(fieldType, "Union[") {
+			} else if isArrayType(field.Type) && !field.IsImplicit {
+				// Fields flattened through a many relationship are absent until loaded
+				annotation, defaultValue = fmt.Sprintf("Optional[%s]", fieldType), "None"
+			} else if strings.HasPrefix(fieldType, "Optional[") || isArrayType(field.Type) || strings.Contains(fieldType, "Union[") {
 				// Relationship fields or Union types
-				cb.Line("%s: %s = None", fieldName, fieldType)
-			} else if field.IsOptional || strings.HasSuffix(fieldName, "_id") || strings.HasSuffix(fieldName, "_type") {
-				// Optional attribute, foreign keys, or type fields
-				cb.Line("%s: Optional[%s] = None", fieldName, fieldType)
+				annotation, defaultValue = fieldType, "None"
 			} else {
-				cb.Line("%s: %s", fieldName, fieldType)
+				// Optional attributes and foreign keys default to None
+				annotation, defaultValue = nullableAnnotation(field, fieldType)
+			}
+			// Declared fields keep their original Morphe key as the alias
+			if value := fieldValue(defaultValue, config.fieldKeyAliasKwargs(field)); value != "" {
+				cb.Line("%s: %s = %s", fieldName, annotation, value)
+			} else {
+				cb.Line("%s: %s", fieldName, annotation)
 			}
 		} else {
 			cb.Line("%s = None", fieldName)
 		}
 	}
 
+	writeAwareDatetimeValidator(cb, awareDatetimeFields)
+
+	// Add derived field properties
+	generateDerivedProperties(cb, entity, config)
+
 	// Add identifier methods
 	if primary, hasPrimary := morpheEntity.Identifiers["primary"]; hasPrimary && len(primary.Fields) > 0 {
 		cb.Line("")
 		cb.Line("def get_id(self) -> str:")
 		cb.Indent()
 		cb.Line(`"""Get the primary identifier."""`)
-		cb.Line("return self.%s", formatdef.ToSnakeCase(primary.Fields[0]))
+		cb.Line("return self.%s", config.pythonFieldName(primary.Fields[0]))
 		cb.Dedent()
 	}
 
 	// Add relationship loader methods
-	if len(morpheEntity.Related) > 0 {
-		for relName, relation := range morpheEntity.Related {
-			cb.Line("")
-			switch relation.Type {
-			case "HasMany", "ForMany":
-				// Use plural form for method name
-				cb.Line("async def load_%ss(self) -> List['%s']:", SanitizePythonIdentifier(formatdef.ToSnakeCase(relName)), relName)
-				cb.Indent()
-				cb.Line(`"""Load related %s entities."""`, relName)
-				cb.Line("# TODO: Implement lazy loading")
-				cb.Line("return []")
-				cb.Dedent()
-			default:
-				cb.Line("async def load_%s(self) -> Optional['%s']:", SanitizePythonIdentifier(formatdef.ToSnakeCase(relName)), relName)
-				cb.Indent()
-				cb.Line(`"""Load related %s entity."""`, relName)
-				cb.Line("# TODO: Implement lazy loading")
-				cb.Line("return None")
-				cb.Dedent()
-			}
+	if lazyLoadingStyle != cfg.LazyLoadingStyleEager {
+		for _, relName := range relNames {
+			generateRelationLoader(cb, relName, morpheEntity.Related[relName], lazyLoadingStyle, builtinGenerics, config)
 		}
 	}
 
@@ -390,6 +502,9 @@ func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, c
 		cb.Indent()
 		cb.Line(`"validate_assignment": True,`)
 		cb.Line(`"arbitrary_types_allowed": True,`)
+		if populateByName {
+			cb.Line(`"%s": True,`, populateByNameOption.V2Key)
+		}
 		cb.Dedent()
 		cb.Line("}")
 	} else {
@@ -399,8 +514,44 @@ func generateEntityContent(entity *formatdef.Struct, morpheEntity yaml.Entity, c
 		cb.Indent()
 		cb.Line("validate_assignment = True")
 		cb.Line("arbitrary_types_allowed = True")
+		if populateByName {
+			cb.Line("%s = True", populateByNameOption.V1Key)
+		}
 		cb.Dedent()
 	}
 
 	return cb.Build()
 }
+
+// generateRelationLoader adds the method resolving a relationship: an async or sync load_x()
+// loader, or a @property named after the navigation field
+func generateRelationLoader(cb *formatdef.ContentBuilder, relName string, relation yaml.EntityRelation, lazyLoadingStyle string, builtinGenerics bool, config PydanticConfig) {
+	isMany := relation.Type == "HasMany" || relation.Type == "ForMany"
+	returnType := fmt.Sprintf(`Optional["%s"]`, relName)
+	returnValue := "None"
+	docstring := fmt.Sprintf(`"""Load related %s entity."""`, relName)
+	methodName := "load_" + SanitizePythonIdentifier(formatdef.ToSnakeCase(relName))
+	if isMany {
+		// Use plural form for method name
+		returnType = formatdef.ArrayType{ElementType: formatdef.BasicType{Name: `"` + relName + `"`}, Builtin: builtinGenerics}.GetName()
+		returnValue = "[]"
+		docstring = fmt.Sprintf(`"""Load related %s entities."""`, relName)
+		methodName += "s"
+	}
+
+	cb.Line("")
+	switch lazyLoadingStyle {
+	case cfg.LazyLoadingStyleProperty:
+		cb.Line("@property")
+		cb.Line("def %s(self) -> %s:", SanitizePythonIdentifier(entityNavFieldName(relName, string(relation.Type), config)), returnType)
+	case cfg.LazyLoadingStyleSync:
+		cb.Line("def %s(self) -> %s:", methodName, returnType)
+	default:
+		cb.Line("async def %s(self) -> %s:", methodName, returnType)
+	}
+	cb.Indent()
+	cb.Line("%s", docstring)
+	cb.Line("# TODO: Implement lazy loading")
+	cb.Line("return %s", returnValue)
+	cb.Dedent()
+}
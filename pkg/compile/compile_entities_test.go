@@ -0,0 +1,336 @@
+package compile_test
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+// newMemberEntityRegistry builds a registry whose Member entity aggregates fields of the Member model
+func newMemberEntityRegistry() *registry.Registry {
+	r := newStatusRegistry()
+	r.SetModel("Member", yaml.Model{
+		Name: "Member",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Status":   {Type: yaml.ModelFieldType("AccountStatus")},
+			"JoinedAt": {Type: yaml.ModelFieldTypeTime},
+			"Balance":  {Type: yaml.ModelFieldType("Money")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetEntity("Member", yaml.Entity{
+		Name: "Member",
+		Fields: map[string]yaml.EntityField{
+			"ID":       {Type: "Member.ID"},
+			"Status":   {Type: "Member.Status"},
+			"JoinedAt": {Type: "Member.JoinedAt"},
+			"Balance":  {Type: "Member.Balance"},
+		},
+		Identifiers: map[string]yaml.EntityIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_AggregatedFieldImports() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"Money": {Type: "Money", Import: "from myapp.money import Money"},
+	}
+
+	content := suite.generateSource(config, newMemberEntityRegistry(), "entities/member.py")
+
+	suite.Contains(content, "from datetime import time\n")
+	suite.Contains(content, "from myapp.money import Money\n")
+	suite.Contains(content, "from ..enums.account_status import AccountStatus\n")
+	suite.Contains(content, "    status: AccountStatus")
+	suite.Contains(content, "    balance: Money")
+}
+
+// newPersonEntityRegistry builds a registry whose Person entity derives FullName and Initials
+func newPersonEntityRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Person", yaml.Model{
+		Name: "Person",
+		Fields: map[string]yaml.ModelField{
+			"ID":        {Type: yaml.ModelFieldTypeAutoIncrement},
+			"FirstName": {Type: yaml.ModelFieldTypeString},
+			"LastName":  {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetEntity("Person", yaml.Entity{
+		Name: "Person",
+		Fields: map[string]yaml.EntityField{
+			"ID":        {Type: "Person.ID"},
+			"FirstName": {Type: "Person.FirstName"},
+			"LastName":  {Type: "Person.LastName"},
+			"FullName":  {Type: "Person.FirstName", Attributes: []string{`expression:FirstName + " " + LastName`}},
+			"Initials":  {Type: "Person.FirstName", Attributes: []string{"computed"}},
+		},
+		Identifiers: map[string]yaml.EntityIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+// newEntityCycleRegistry adds Author and Book entities referencing each other to the model cycle
+func newEntityCycleRegistry() *registry.Registry {
+	r := newCycleRegistry()
+	r.SetEntity("Author", yaml.Entity{
+		Name:        "Author",
+		Fields:      map[string]yaml.EntityField{"ID": {Type: "Author.ID"}},
+		Identifiers: map[string]yaml.EntityIdentifier{"primary": {Fields: []string{"ID"}}},
+		Related:     map[string]yaml.EntityRelation{"Book": {Type: "HasMany"}},
+	})
+	r.SetEntity("Book", yaml.Entity{
+		Name:        "Book",
+		Fields:      map[string]yaml.EntityField{"ID": {Type: "Book.ID"}},
+		Identifiers: map[string]yaml.EntityIdentifier{"primary": {Fields: []string{"ID"}}},
+		Related:     map[string]yaml.EntityRelation{"Author": {Type: "ForOne"}},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_CycleRebuiltInIndex() {
+	r := newEntityCycleRegistry()
+
+	author := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "entities/author.py")
+	book := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "entities/book.py")
+	index := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "entities/__init__.py")
+
+	suite.Contains(author, "    books: Optional[List[\"Book\"]] = None\n")
+	suite.Contains(book, "    author: Optional[\"Author\"] = None\n")
+	suite.True(strings.HasSuffix(index, `from .author import Author
+from .book import Book
+
+
+Book.model_rebuild()
+Author.model_rebuild()
+`))
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_CycleRebuiltInIndexPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	index := suite.generateSource(config, newEntityCycleRegistry(), "entities/__init__.py")
+
+	suite.True(strings.HasSuffix(index, "\n\n\nBook.update_forward_refs(**globals())\nAuthor.update_forward_refs(**globals())\n"))
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_DerivedFields() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newPersonEntityRegistry(), "entities/person.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, computed_field\n")
+	suite.Contains(content, "    first_name: str = Field(alias=\"FirstName\")\n")
+	suite.NotContains(content, "    full_name: str\n")
+	suite.Contains(content, `
+    @computed_field
+    @property
+    def full_name(self) -> str:
+        """Derived: FirstName + " " + LastName"""
+        return self.first_name + " " + self.last_name
+`)
+	suite.Contains(content, `
+    @computed_field
+    @property
+    def initials(self) -> str:
+        """Derived initials value."""
+        # TODO: derive this value from the entity fields
+        raise NotImplementedError
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_FieldCaseCamel() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = compile.FieldCaseCamel
+
+	content := suite.generateSource(config, newPersonEntityRegistry(), "entities/person.py")
+
+	suite.Contains(content, "    firstName: str = Field(alias=\"FirstName\")\n")
+	suite.Contains(content, "    def fullName(self) -> str:\n")
+	suite.Contains(content, `        return self.firstName + " " + self.lastName`)
+	suite.Contains(content, "        return self.id_\n")
+}
+func (suite *CompileTestSuite) TestCompileEntity_FieldKeyAliasesPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newPersonEntityRegistry(), "entities/person.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    first_name: str = Field(alias=\"FirstName\")\n")
+	suite.Contains(content, `
+    class Config:
+        validate_assignment = True
+        arbitrary_types_allowed = True
+        allow_population_by_field_name = True
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_FieldKeyAliasesDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DisableFieldKeyAliases = true
+
+	content := suite.generateSource(config, newPersonEntityRegistry(), "entities/person.py")
+
+	suite.Contains(content, "    first_name: str\n")
+	suite.NotContains(content, "alias=")
+	suite.NotContains(content, "populate_by_name")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_DerivedFieldsPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newPersonEntityRegistry(), "entities/person.py")
+
+	suite.NotContains(content, "computed_field")
+	suite.Contains(content, "    @property\n    def full_name(self) -> str:\n")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_DerivedExpressionNotTranslated() {
+	r := newPersonEntityRegistry()
+	entity, err := r.GetEntity("Person")
+	suite.Require().NoError(err)
+	entity.Fields["FullName"] = yaml.EntityField{Type: "Person.FirstName", Attributes: []string{"expression:FirstName.upper()"}}
+	r.SetEntity("Person", entity)
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "entities/person.py")
+
+	suite.Contains(content, "    def full_name(self) -> str:\n        \"\"\"Derived full_name value.\"\"\"\n")
+}
+
+// newOrderSummaryRegistry builds a registry whose entities flatten fields across the Customer
+// and Order models
+func newOrderSummaryRegistry() *registry.Registry {
+	r := newCustomerOrderRegistry()
+	customer, _ := r.GetModel("Customer")
+	customer.Fields["Status"] = yaml.ModelField{Type: yaml.ModelFieldType("AccountStatus")}
+	r.SetModel("Customer", customer)
+	statuses := newStatusRegistry()
+	enum, _ := statuses.GetEnum("AccountStatus")
+	r.SetEnum("AccountStatus", enum)
+
+	r.SetEntity("OrderSummary", yaml.Entity{
+		Name: "OrderSummary",
+		Fields: map[string]yaml.EntityField{
+			"ID":             {Type: "Order.ID"},
+			"Total":          {Type: "Order.Total"},
+			"CustomerName":   {Type: "Order.Customer.Name"},
+			"CustomerStatus": {Type: "Order.Customer.Status"},
+		},
+		Identifiers: map[string]yaml.EntityIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetEntity("CustomerSummary", yaml.Entity{
+		Name: "CustomerSummary",
+		Fields: map[string]yaml.EntityField{
+			"ID":          {Type: "Customer.ID"},
+			"OrderTotals": {Type: "Customer.Orders.Total"},
+		},
+		Identifiers: map[string]yaml.EntityIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_FlattensRelatedFields() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newOrderSummaryRegistry(), "entities/order_summary.py")
+
+	suite.Contains(content, "from ..enums.account_status import AccountStatus\n")
+	suite.Contains(content, "    customer_name: str = Field(alias=\"CustomerName\")\n")
+	suite.Contains(content, "    customer_status: AccountStatus = Field(alias=\"CustomerStatus\")\n")
+	suite.Contains(content, "    total: float = Field(alias=\"Total\")\n")
+	suite.NotContains(content, "TYPE_CHECKING")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_FlattensManyRelationFieldsAsLists() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newOrderSummaryRegistry(), "entities/customer_summary.py")
+
+	suite.Contains(content, "    order_totals: Optional[List[float]] = Field(default=None, alias=\"OrderTotals\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_LazyLoadingStyleProperty() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.MorpheConfig.Entities.LazyLoadingStyle = "property"
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	person := files["entities/person.py"]
+	suite.Contains(person, "    company_id: Optional[str] = None\n")
+	suite.NotContains(person, "    company: ")
+	suite.NotContains(person, "load_company")
+	suite.Contains(person, `
+    @property
+    def company(self) -> Optional["Company"]:
+        """Load related Company entity."""
+        # TODO: Implement lazy loading
+        return None
+`)
+	suite.Contains(files["entities/company.py"], `
+    @property
+    def persons(self) -> List["Person"]:
+        """Load related Person entities."""
+        # TODO: Implement lazy loading
+        return []
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_LazyLoadingStyleEager() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.MorpheConfig.Entities.LazyLoadingStyle = "eager"
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files["entities/person.py"], "    company: Optional[\"Company\"] = None\n")
+	suite.Contains(files["entities/company.py"], "    persons: Optional[List[\"Person\"]] = None\n")
+	suite.NotContains(files["entities/person.py"], "def load_")
+	suite.NotContains(files["entities/company.py"], "def load_")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_LazyLoadingStyleSync() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.MorpheConfig.Entities.LazyLoadingStyle = "sync"
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files["entities/person.py"], "\n    def load_company(self) -> Optional[\"Company\"]:\n")
+	suite.Contains(files["entities/person.py"], "    company: Optional[\"Company\"] = None\n")
+	suite.NotContains(files["entities/person.py"], "async def")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_LazyLoadingStyleAsyncQuotesNavigation() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files["entities/person.py"], "    company: Optional[\"Company\"] = None\n")
+	suite.Contains(files["entities/company.py"], "    persons: Optional[List[\"Person\"]] = None\n")
+	suite.NotContains(files["entities/person.py"], "    company: Company\n")
+	suite.NotContains(files["entities/company.py"], "List[Person]")
+}
+
+func (suite *CompileTestSuite) TestValidate_LazyLoadingStyle() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.MorpheConfig.Entities.LazyLoadingStyle = "deferred"
+
+	suite.EqualError(config.Validate(), "invalid entities.lazyLoadingStyle: deferred (must be 'async', 'sync', 'property' or 'eager')")
+}
@@ -2,14 +2,20 @@ package compile
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/kalo-build/morphe-go/pkg/registry"
 	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
 )
 
+// enumLiteralSuffix is appended to an enum name to form its Literal alias name
+const enumLiteralSuffix = "Literal"
+
 // CompileEnum converts a Morphe enum to the target format
 func CompileEnum(enum yaml.Enum) (*formatdef.Enum, error) {
 	// Create the enum definition
@@ -54,6 +60,8 @@ func mapEnumType(morpheType yaml.EnumType) formatdef.Type {
 
 // CompileAllEnums compiles all enums and writes them using the writer
 func CompileAllEnums(config MorpheCompileConfig, r *registry.Registry, writer *MorpheWriter) error {
+	writer.useConfig(config.FormatConfig)
+
 	enumContents := make(map[string][]byte)
 
 	// Process each enum in the registry
@@ -64,43 +72,271 @@ func CompileAllEnums(config MorpheCompileConfig, r *registry.Registry, writer *M
 			return fmt.Errorf("failed to compile enum %s: %w", enumName, err)
 		}
 
+		if err := checkEnumMemberNames(compiledEnum, config.MorpheConfig.Enums.MemberNameCase); err != nil {
+			return err
+		}
+
+		// Attach configured documentation
+		if doc, hasDoc := config.MorpheConfig.Enums.Docs[enumName]; hasDoc {
+			if err := applyEnumDoc(compiledEnum, doc); err != nil {
+				return err
+			}
+		}
+
+		// Attach configured alias values
+		if aliases, hasAliases := config.MorpheConfig.Enums.Aliases[enumName]; hasAliases && config.MorpheConfig.Enums.GenerateAliases {
+			if err := applyEnumAliases(compiledEnum, aliases); err != nil {
+				return err
+			}
+		}
+
 		// Generate the content for this enum
-		content := generateEnumContent(compiledEnum, config.FormatConfig)
+		content := generateEnumContent(compiledEnum, config.FormatConfig, config.MorpheConfig.Enums)
 		enumContents[enumName] = content
 	}
 
+	for enumName := range config.MorpheConfig.Enums.Docs {
+		if _, exists := r.GetAllEnums()[enumName]; !exists {
+			return ErrEnumNotFound(enumName)
+		}
+	}
+	for enumName := range config.MorpheConfig.Enums.Aliases {
+		if _, exists := r.GetAllEnums()[enumName]; !exists {
+			return ErrEnumNotFound(enumName)
+		}
+	}
+
+	// Generate RootModel wrappers for the configured enum collections
+	for collectionName, enumName := range config.MorpheConfig.Enums.Collections {
+		if _, exists := r.GetAllEnums()[enumName]; !exists {
+			return ErrEnumNotFound(enumName)
+		}
+		enumContents[collectionName] = generateEnumCollectionContent(collectionName, enumName, config.FormatConfig)
+	}
+
+	// Write module-level default constants
+	if config.MorpheConfig.Enums.GenerateDefaultConstants && len(config.MorpheConfig.Enums.DefaultMembers) > 0 {
+		content, err := generateEnumConstantsContent(r.GetAllEnums(), config.MorpheConfig.Enums, config.FormatConfig.FileNaming)
+		if err != nil {
+			return err
+		}
+		if err := writer.WriteConstants(content); err != nil {
+			return err
+		}
+	}
+
 	// Write all enum contents
 	return writer.WriteAllEnums(enumContents)
 }
 
+// generateEnumConstantsContent generates DEFAULT_<ENUM> constants referencing each enum's default member
+func generateEnumConstantsContent(enums map[string]yaml.Enum, enumConfig cfg.EnumConfig, fileNaming string) ([]byte, error) {
+	defaultMembers := enumConfig.DefaultMembers
+	cb := formatdef.NewContentBuilder("    ")
+
+	var enumNames []string
+	for enumName := range defaultMembers {
+		enumNames = append(enumNames, enumName)
+	}
+	sort.Strings(enumNames)
+
+	var constants []string
+	for _, enumName := range enumNames {
+		enum, exists := enums[enumName]
+		if !exists {
+			return nil, ErrEnumNotFound(enumName)
+		}
+		memberName := defaultMembers[enumName]
+		if _, exists := enum.Entries[memberName]; !exists {
+			return nil, ErrEnumMemberNotFound(enumName, memberName)
+		}
+
+		cb.Line("from .enums.%s import %s", ModuleName(enumName, fileNaming), enumName)
+		constants = append(constants, fmt.Sprintf("DEFAULT_%s = %s.%s",
+			strings.ToUpper(formatdef.ToSnakeCase(enumName)), enumName, enumMemberName(memberName, enumConfig.MemberNameCase)))
+	}
+
+	cb.Line("")
+	cb.Line("")
+	for _, constant := range constants {
+		cb.Line(constant)
+	}
+
+	return cb.Build(), nil
+}
+
+// applyEnumDoc sets the enum description and member comments from its configured docs
+func applyEnumDoc(enum *formatdef.Enum, doc cfg.EnumDoc) error {
+	enum.Description = doc.Description
+	for memberName, comment := range doc.Members {
+		found := false
+		for i := range enum.Entries {
+			if enum.Entries[i].Name == memberName {
+				enum.Entries[i].Comment = comment
+				found = true
+			}
+		}
+		if !found {
+			return ErrEnumMemberNotFound(enum.Name, memberName)
+		}
+	}
+	return nil
+}
+
+// applyEnumAliases attaches alias values to the enum members they resolve to
+func applyEnumAliases(enum *formatdef.Enum, aliases map[string][]string) error {
+	for memberName, values := range aliases {
+		found := false
+		for i := range enum.Entries {
+			if enum.Entries[i].Name == memberName {
+				enum.Entries[i].Aliases = values
+				found = true
+			}
+		}
+		if !found {
+			return ErrEnumMemberNotFound(enum.Name, memberName)
+		}
+	}
+	return nil
+}
+
+// docstringLines escapes text for a triple-quoted docstring and splits it into lines
+func docstringLines(text string) []string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), `\`, `\\`)
+	text = strings.ReplaceAll(text, `"""`, `\"\"\"`)
+	if strings.HasSuffix(text, `"`) {
+		text = strings.TrimSuffix(text, `"`) + `\"`
+	}
+	return strings.Split(text, "\n")
+}
+
+// enumValueLiteral renders the value assigned to an enum member verbatim: quoted for string
+// enums and bare otherwise. Integer values decoded as floats (e.g. from JSON) are rendered as
+// integers, so non-contiguous values such as 1 and 9 keep their assigned numbers.
+func enumValueLiteral(enumType formatdef.Type, value any) string {
+	if enumType.GetName() == formatdef.TypeString.Name {
+		return strconv.Quote(fmt.Sprint(value))
+	}
+	if number, isFloat := value.(float64); isFloat && enumType.GetName() == formatdef.TypeInteger.Name && number == math.Trunc(number) {
+		return strconv.FormatInt(int64(number), 10)
+	}
+	return fmt.Sprint(value)
+}
+
+// enumLiteralName returns the name of the Literal alias generated for an enum
+func enumLiteralName(enumName string) string {
+	return enumName + enumLiteralSuffix
+}
+
+// enumMemberName converts a Morphe enum entry name to a Python enum member name in the
+// configured case, sanitized the same way as field names; the entry's value is rendered unchanged
+func enumMemberName(entryName string, memberNameCase string) string {
+	if memberNameCase == cfg.MemberNameCaseAsIs {
+		return SanitizePythonIdentifier(entryName)
+	}
+	return SanitizePythonIdentifier(strings.ToUpper(formatdef.ToSnakeCase(entryName)))
+}
+
+// checkEnumMemberNames returns an error when two entries of the enum map to the same member
+// name, which Python would otherwise reject (or silently shadow) at import time
+func checkEnumMemberNames(enum *formatdef.Enum, memberNameCase string) error {
+	entriesByMember := make(map[string]string)
+	for _, entry := range enum.Entries {
+		memberName := enumMemberName(entry.Name, memberNameCase)
+		if otherEntry, exists := entriesByMember[memberName]; exists {
+			return &EnumMemberNameCollisionError{Enum: enum.Name, Member: memberName, Entries: []string{otherEntry, entry.Name}}
+		}
+		entriesByMember[memberName] = entry.Name
+	}
+	return nil
+}
+
+// generateEnumCollectionContent generates a root model wrapping a list of enum values
+func generateEnumCollectionContent(collectionName string, enumName string, config PydanticConfig) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+
+	listType := formatdef.ArrayType{ElementType: formatdef.BasicType{Name: enumName}}
+	if config.PythonVersionAtLeast(3, 9) {
+		listType.Builtin = true
+	}
+
+	// Add imports
+	from := map[string][]string{"." + ModuleName(enumName, config.FileNaming): {enumName}}
+	if config.PydanticV2 {
+		from["pydantic"] = []string{"RootModel"}
+	} else {
+		from["pydantic"] = []string{"BaseModel"}
+	}
+	if !listType.Builtin {
+		from["typing"] = []string{"List"}
+	}
+	writeImportSections(cb, from, nil)
+	cb.Line("")
+	cb.Line("")
+
+	// Pydantic v1 has no RootModel and uses a __root__ field instead
+	if config.PydanticV2 {
+		cb.Line("class %s(RootModel[%s]):", collectionName, listType.GetName())
+		cb.Indent()
+		cb.Line(`"""List of %s values."""`, enumName)
+	} else {
+		cb.Line("class %s(BaseModel):", collectionName)
+		cb.Indent()
+		cb.Line(`"""List of %s values."""`, enumName)
+		cb.Line("__root__: %s", listType.GetName())
+	}
+	cb.Dedent()
+
+	return cb.Build()
+}
+
 // generateEnumContent generates Python enum definition
-func generateEnumContent(enum *formatdef.Enum, config PydanticConfig) []byte {
-	cb := formatdef.NewContentBuilder("    ") // 4 spaces for Python
+func generateEnumContent(enum *formatdef.Enum, config PydanticConfig, enumConfig cfg.EnumConfig) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength) // 4 spaces for Python
+
+	// Integer enums derive from IntEnum so members compare equal to their values, unless a
+	// custom base class is configured
+	baseClass, baseModule := "Enum", "enum"
+	if enumConfig.BaseClass != "" {
+		baseClass, baseModule = enumConfig.BaseClass, enumConfig.BaseModule()
+	} else if enum.Type.GetName() == formatdef.TypeInteger.Name {
+		baseClass = "IntEnum"
+	}
 
 	// Add imports
-	cb.Line("from enum import Enum")
+	imports := map[string][]string{baseModule: {baseClass}}
+	if enumConfig.GenerateEnumLiterals {
+		imports["typing"] = append(imports["typing"], "Literal")
+	}
+	writeImportSections(cb, imports, nil)
 	cb.Line("")
 	cb.Line("")
 
 	// Generate enum class
-	cb.Line("class %s(Enum):", enum.Name)
+	cb.Line("class %s(%s):", enum.Name, baseClass)
 	cb.Indent()
 
 	// Add docstring
-	cb.Line(`"""%s enumeration."""`, enum.Name)
+	if enum.Description == "" {
+		cb.Line(`"""%s enumeration."""`, enum.Name)
+	} else if lines := docstringLines(enum.Description); len(lines) == 1 {
+		cb.Line(`"""%s"""`, lines[0])
+	} else {
+		cb.Line(`"""%s`, lines[0])
+		for _, line := range lines[1:] {
+			cb.Line("%s", line)
+		}
+		cb.Line(`"""`)
+	}
 
 	// Add enum entries
 	for _, entry := range enum.Entries {
 		// Python enum format: NAME = value
-		entryName := formatdef.ToSnakeCase(entry.Name)
-		entryName = strings.ToUpper(entryName)
-
-		switch enum.Type.GetName() {
-		case "str":
-			cb.Line("%s = %q", entryName, entry.Value)
-		default:
-			cb.Line("%s = %v", entryName, entry.Value)
+		line := fmt.Sprintf("%s = %s", enumMemberName(entry.Name, enumConfig.MemberNameCase), enumValueLiteral(enum.Type, entry.Value))
+		if entry.Comment != "" {
+			line += "  # " + strings.Join(strings.Fields(entry.Comment), " ")
 		}
+		cb.Line("%s", line)
 	}
 
 	// Add utility methods
@@ -119,5 +355,53 @@ func generateEnumContent(enum *formatdef.Enum, config PydanticConfig) []byte {
 	cb.Line("raise ValueError(f\"No %s member with value {value}\")", enum.Name)
 	cb.Dedent()
 
+	// Resolve alias values through the _missing_ hook so they deserialize to the canonical member
+	if enum.HasAliases() {
+		cb.Line("")
+		cb.Line("@classmethod")
+		cb.Line("def _missing_(cls, value):")
+		cb.Indent()
+		cb.Line(`"""Resolve alias values to their canonical member."""`)
+		cb.Line("aliases = {")
+		cb.Indent()
+		for _, entry := range enum.Entries {
+			for _, alias := range entry.Aliases {
+				if enum.Type.GetName() == "str" {
+					cb.Line("%q: cls.%s,", alias, enumMemberName(entry.Name, enumConfig.MemberNameCase))
+				} else {
+					cb.Line("%s: cls.%s,", alias, enumMemberName(entry.Name, enumConfig.MemberNameCase))
+				}
+			}
+		}
+		cb.Dedent()
+		cb.Line("}")
+		cb.Line("return aliases.get(value)")
+		cb.Dedent()
+	}
+
+	if enumConfig.GenerateStrMethod {
+		cb.Line("")
+		cb.Line("def __str__(self) -> str:")
+		cb.Indent()
+		if enum.Type.GetName() == "str" {
+			cb.Line("return self.value")
+		} else {
+			cb.Line("return str(self.value)")
+		}
+		cb.Dedent()
+	}
+	cb.Dedent()
+
+	// Lightweight Literal alias over the enum values
+	if enumConfig.GenerateEnumLiterals {
+		var values []string
+		for _, entry := range enum.Entries {
+			values = append(values, enumValueLiteral(enum.Type, entry.Value))
+		}
+		cb.Line("")
+		cb.Line("")
+		cb.Line("%s = Literal[%s]", enumLiteralName(enum.Name), strings.Join(values, ", "))
+	}
+
 	return cb.Build()
 }
@@ -0,0 +1,427 @@
+package compile_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
+)
+
+// newStatusRegistry builds a registry with a single string Status enum
+func newStatusRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetEnum("AccountStatus", yaml.Enum{
+		Name: "AccountStatus",
+		Type: yaml.EnumTypeString,
+		Entries: map[string]any{
+			"Active":   "active",
+			"Disabled": "disabled",
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_DefaultConstants() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateDefaultConstants = true
+	config.MorpheConfig.Enums.DefaultMembers = map[string]string{"AccountStatus": "Active"}
+
+	content := suite.generateSource(config, newStatusRegistry(), "constants.py")
+
+	suite.Equal(`# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from .enums.account_status import AccountStatus
+
+
+DEFAULT_ACCOUNT_STATUS = AccountStatus.ACTIVE
+`, content)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_DefaultConstantsUnknownMember() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateDefaultConstants = true
+	config.MorpheConfig.Enums.DefaultMembers = map[string]string{"AccountStatus": "Pending"}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllEnums(config, newStatusRegistry(), writer)
+
+	suite.ErrorContains(err, "enum AccountStatus has no member: Pending")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_DefaultConstantsDisabled() {
+	outputDirPath := suite.T().TempDir()
+	config := compile.DefaultMorpheCompileConfig("", outputDirPath)
+	config.MorpheConfig.Enums.DefaultMembers = map[string]string{"AccountStatus": "Active"}
+
+	writer := compile.NewMorpheWriter(outputDirPath)
+	suite.NoError(compile.CompileAllEnums(config, newStatusRegistry(), writer))
+
+	suite.NoFileExists(outputDirPath + "/constants.py")
+	_, statErr := os.Stat(outputDirPath + "/enums/account_status.py")
+	suite.NoError(statErr)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_CollectionRootModel() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.Collections = map[string]string{"AccountStatuses": "AccountStatus"}
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_statuses.py")
+
+	suite.Equal(`# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from typing import List
+
+from pydantic import RootModel
+
+from .account_status import AccountStatus
+
+
+class AccountStatuses(RootModel[List[AccountStatus]]):
+    """List of AccountStatus values."""
+`, content)
+	suite.Contains(suite.generateSource(config, newStatusRegistry(), "enums/__init__.py"),
+		"from .account_statuses import AccountStatuses")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_CollectionRootModelPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Enums.Collections = map[string]string{"AccountStatuses": "AccountStatus"}
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_statuses.py")
+
+	suite.Contains(content, "from pydantic import BaseModel\n")
+	suite.Contains(content, `class AccountStatuses(BaseModel):
+    """List of AccountStatus values."""
+    __root__: List[AccountStatus]
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_CollectionUnknownEnum() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.Collections = map[string]string{"Colors": "Color"}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllEnums(config, newStatusRegistry(), writer)
+
+	suite.ErrorContains(err, "enum not found: Color")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_DocsAndMemberComments() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.Docs = map[string]cfg.EnumDoc{
+		"AccountStatus": {
+			Description: `Lifecycle state of an "account"`,
+			Members: map[string]string{
+				"Disabled": "Suspended by an\nadministrator",
+			},
+		},
+	}
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.Contains(content, `class AccountStatus(Enum):
+    """Lifecycle state of an "account\""""
+    ACTIVE = "active"
+    DISABLED = "disabled"  # Suspended by an administrator
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_MultiLineDescription() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.Docs = map[string]cfg.EnumDoc{
+		"AccountStatus": {Description: "Account state.\n\nSee docs/accounts.md"},
+	}
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.Contains(content, `    """Account state.
+
+    See docs/accounts.md
+    """
+    ACTIVE = "active"
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_DocsUnknownMember() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.Docs = map[string]cfg.EnumDoc{
+		"AccountStatus": {Members: map[string]string{"Archived": "Gone"}},
+	}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllEnums(config, newStatusRegistry(), writer)
+
+	suite.ErrorContains(err, "enum AccountStatus has no member: Archived")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_StrMethod() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateStrMethod = true
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.Contains(content, `
+    def __str__(self) -> str:
+        return self.value
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_StrMethodIntegerEnum() {
+	r := registry.NewRegistry()
+	r.SetEnum("Priority", yaml.Enum{
+		Name:    "Priority",
+		Type:    yaml.EnumTypeInteger,
+		Entries: map[string]any{"Low": 1, "High": 2},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateStrMethod = true
+
+	content := suite.generateSource(config, r, "enums/priority.py")
+
+	suite.Contains(content, "        return str(self.value)\n")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_NonContiguousIntegerValues() {
+	r := registry.NewRegistry()
+	r.SetEnum("RecordState", yaml.Enum{
+		Name:    "RecordState",
+		Type:    yaml.EnumTypeInteger,
+		Entries: map[string]any{"Deleted": 9, "Active": 1, "Archived": float64(40)},
+	})
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "enums/record_state.py")
+
+	suite.Contains(content, "from enum import IntEnum\n")
+	suite.Contains(content, "class RecordState(IntEnum):\n")
+	suite.Contains(content, "    ACTIVE = 1\n    ARCHIVED = 40\n    DELETED = 9\n")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_SanitizedMemberNames() {
+	r := registry.NewRegistry()
+	r.SetEnum("Factor", yaml.Enum{
+		Name:    "Factor",
+		Type:    yaml.EnumTypeString,
+		Entries: map[string]any{"None": "None", "import": "import", "2fa": "2fa", "in-app": "in-app"},
+	})
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "enums/factor.py")
+
+	suite.Contains(content, "    _2FA = \"2fa\"\n    NONE = \"None\"\n    IMPORT = \"import\"\n    IN_APP = \"in-app\"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_SanitizedKeywordMemberNamesAsIs() {
+	r := registry.NewRegistry()
+	r.SetEnum("Factor", yaml.Enum{
+		Name:    "Factor",
+		Type:    yaml.EnumTypeString,
+		Entries: map[string]any{"None": "None", "import": "import"},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.MemberNameCase = cfg.MemberNameCaseAsIs
+
+	content := suite.generateSource(config, r, "enums/factor.py")
+
+	suite.Contains(content, "    None_ = \"None\"\n")
+	suite.Contains(content, "    import_ = \"import\"\n")
+}
+
+func (suite *CompileTestSuite) TestSanitizePythonIdentifier() {
+	suite.Equal("None_", compile.SanitizePythonIdentifier("None"))
+	suite.Equal("import_", compile.SanitizePythonIdentifier("import"))
+	suite.Equal("_2fa", compile.SanitizePythonIdentifier("2fa"))
+	suite.Equal("in_app", compile.SanitizePythonIdentifier("in-app"))
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_StrMethodDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.NotContains(content, "__str__")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_Aliases() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateAliases = true
+	config.MorpheConfig.Enums.Aliases = map[string]map[string][]string{
+		"AccountStatus": {"Active": {"enabled", "on"}},
+	}
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.Contains(content, `
+    @classmethod
+    def _missing_(cls, value):
+        """Resolve alias values to their canonical member."""
+        aliases = {
+            "enabled": cls.ACTIVE,
+            "on": cls.ACTIVE,
+        }
+        return aliases.get(value)
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_AliasesDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.Aliases = map[string]map[string][]string{
+		"AccountStatus": {"Active": {"enabled"}},
+	}
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.NotContains(content, "_missing_")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_AliasesUnknownMember() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateAliases = true
+	config.MorpheConfig.Enums.Aliases = map[string]map[string][]string{
+		"AccountStatus": {"Archived": {"gone"}},
+	}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllEnums(config, newStatusRegistry(), writer)
+
+	suite.ErrorContains(err, "enum AccountStatus has no member: Archived")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_Literals() {
+	r := newStatusRegistry()
+	r.SetModel("Account", yaml.Model{
+		Name: "Account",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Status": {Type: "AccountStatus"},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.GenerateEnumLiterals = true
+
+	enumContent := suite.generateSource(config, r, "enums/account_status.py")
+	modelContent := suite.generateSource(config, r, "models/account.py")
+
+	suite.Contains(enumContent, "from enum import Enum\nfrom typing import Literal\n")
+	suite.True(strings.HasSuffix(enumContent, "\n\n\nAccountStatusLiteral = Literal[\"active\", \"disabled\"]\n"))
+	suite.Contains(modelContent, "from ..enums.account_status import AccountStatusLiteral\n")
+	suite.Contains(modelContent, "    status: AccountStatusLiteral = Field(alias=\"Status\")\n")
+	suite.NotContains(modelContent, "import AccountStatus\n")
+}
+
+// newMixedCaseRegistry builds a registry with an enum whose entry names mix casing styles
+func newMixedCaseRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetEnum("Stage", yaml.Enum{
+		Name: "Stage",
+		Type: yaml.EnumTypeString,
+		Entries: map[string]any{
+			"inReview":  "In Review",
+			"Published": "PUBLISHED",
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_MemberNameCaseUpper() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.MemberNameCase = cfg.MemberNameCaseUpper
+
+	content := suite.generateSource(config, newMixedCaseRegistry(), "enums/stage.py")
+
+	suite.Contains(content, `
+    PUBLISHED = "PUBLISHED"
+    IN_REVIEW = "In Review"
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_MemberNameCaseAsIs() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.MemberNameCase = cfg.MemberNameCaseAsIs
+
+	content := suite.generateSource(config, newMixedCaseRegistry(), "enums/stage.py")
+
+	suite.Contains(content, `
+    Published = "PUBLISHED"
+    inReview = "In Review"
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_MemberNameCaseCollision() {
+	r := registry.NewRegistry()
+	r.SetEnum("Stage", yaml.Enum{
+		Name: "Stage",
+		Type: yaml.EnumTypeString,
+		Entries: map[string]any{
+			"IN_REVIEW": "IN_REVIEW",
+			"InReview":  "in_review",
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllEnums(config, r, writer)
+
+	var collisionErr *compile.EnumMemberNameCollisionError
+	suite.Require().True(errors.As(err, &collisionErr))
+	suite.Equal("Stage", collisionErr.Enum)
+	suite.Equal("IN_REVIEW", collisionErr.Member)
+	suite.Equal([]string{"IN_REVIEW", "InReview"}, collisionErr.Entries)
+	suite.ErrorContains(err, "enum Stage entries IN_REVIEW and InReview both map to member name: IN_REVIEW")
+
+	config.MorpheConfig.Enums.MemberNameCase = cfg.MemberNameCaseAsIs
+	suite.NoError(compile.CompileAllEnums(config, r, writer))
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_MemberNameCaseInvalid() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.MemberNameCase = "lower"
+
+	suite.ErrorContains(config.Validate(), "enums.memberNameCase")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_CustomBaseClass() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.BaseClass = "LabeledEnum"
+	config.MorpheConfig.Enums.BaseImport = "myapp.enums"
+	config.MorpheConfig.Enums.GenerateEnumLiterals = true
+
+	content := suite.generateSource(config, newMixedCaseRegistry(), "enums/stage.py")
+
+	suite.Contains(content, `
+from typing import Literal
+
+from myapp.enums import LabeledEnum
+
+
+class Stage(LabeledEnum):
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_BuiltinBaseClass() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.BaseClass = "StrEnum"
+
+	suite.Require().NoError(config.Validate())
+	content := suite.generateSource(config, newMixedCaseRegistry(), "enums/stage.py")
+
+	suite.Contains(content, "from enum import StrEnum\n")
+	suite.Contains(content, "class Stage(StrEnum):\n")
+}
+
+func (suite *CompileTestSuite) TestCompileEnum_BaseClassWithoutImport() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.BaseClass = "LabeledEnum"
+
+	suite.ErrorContains(config.Validate(), "invalid enums.baseImport: required for enum base class LabeledEnum")
+
+	config.MorpheConfig.Enums.BaseClass = ""
+	config.MorpheConfig.Enums.BaseImport = "myapp.enums"
+	suite.ErrorContains(config.Validate(), "enums.baseImport")
+}
@@ -1,21 +1,33 @@
 package compile
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
+)
 
 // Common compilation errors
-// TODO: Add format-specific error types as needed
 
 // ErrNoRegistry is returned when registry is nil
 var ErrNoRegistry = fmt.Errorf("registry is nil")
 
+// ErrNoPolymorphicTargets is returned when a polymorphic relationship has neither for nor through
+var ErrNoPolymorphicTargets = fmt.Errorf("polymorphic relationship has neither for nor through")
+
+// ErrUnknownRelationType is returned for a relationship type the compiler has no handling for
+func ErrUnknownRelationType(relationType string) error {
+	return fmt.Errorf("unknown relation type: %s", relationType)
+}
+
 // ErrInvalidFieldType is returned when a field type cannot be mapped
 func ErrInvalidFieldType(fieldType string) error {
 	return fmt.Errorf("invalid or unsupported field type: %s", fieldType)
 }
 
-// ErrModelNotFound is returned when a referenced model doesn't exist
-func ErrModelNotFound(modelName string) error {
-	return fmt.Errorf("model not found: %s", modelName)
+// ErrStructureNotFound is returned when a referenced structure doesn't exist
+func ErrStructureNotFound(structureName string) error {
+	return fmt.Errorf("structure not found: %s", structureName)
 }
 
 // ErrEnumNotFound is returned when a referenced enum doesn't exist
@@ -23,6 +35,131 @@ func ErrEnumNotFound(enumName string) error {
 	return fmt.Errorf("enum not found: %s", enumName)
 }
 
+// ErrTypeNotFound is returned when a requested type name matches no enum, model, structure or entity
+func ErrTypeNotFound(typeName string) error {
+	return fmt.Errorf("type not found: %s", typeName)
+}
+
+// ErrEnumMemberNotFound is returned when a referenced enum entry doesn't exist
+func ErrEnumMemberNotFound(enumName string, memberName string) error {
+	return fmt.Errorf("enum %s has no member: %s", enumName, memberName)
+}
+
+// TypeMapError is returned when a field's type cannot be mapped to a Python type
+type TypeMapError struct {
+	Owner string // Model, structure or entity declaring the field
+	Field string
+	Err   error
+}
+
+func (e *TypeMapError) Error() string {
+	return fmt.Sprintf("failed to map field type for %s.%s: %v", e.Owner, e.Field, e.Err)
+}
+
+func (e *TypeMapError) Unwrap() error {
+	return e.Err
+}
+
+// UnmappedFieldTypeError is returned in strict mode when a field's type has no known or custom mapping
+type UnmappedFieldTypeError struct {
+	Owner string // Model or structure declaring the field
+	Field string
+	Type  string
+}
+
+func (e *UnmappedFieldTypeError) Error() string {
+	return fmt.Sprintf("unmapped field type %s for %s.%s", e.Type, e.Owner, e.Field)
+}
+
+// AttributeValueError is returned when a numeric field's attribute value isn't a number literal
+type AttributeValueError struct {
+	Owner     string // Model or structure declaring the field
+	Field     string
+	Attribute string // Attribute key, such as "ge" or "default"
+	Value     string
+	Type      string // Python type the value must be a literal of
+}
+
+func (e *AttributeValueError) Error() string {
+	return fmt.Sprintf("invalid %s:%s attribute on %s.%s: not a valid %s literal", e.Attribute, e.Value, e.Owner, e.Field, e.Type)
+}
+
+// ModelNotFoundError is returned when a referenced model doesn't exist
+type ModelNotFoundError struct {
+	Model string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	return "model not found: " + e.Model
+}
+
+// RelationResolveError is returned when a relationship cannot be resolved to its target
+type RelationResolveError struct {
+	Model    string // Model declaring the relationship (empty when unknown)
+	Relation string
+	Err      error // Underlying cause (nil when the relationship does not exist)
+}
+
+func (e *RelationResolveError) Error() string {
+	msg := "failed to resolve relation " + e.Relation
+	if e.Model != "" {
+		msg += " in model " + e.Model
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *RelationResolveError) Unwrap() error {
+	return e.Err
+}
+
+// EnumMemberNameCollisionError is returned when two entries of an enum map to the same member name
+type EnumMemberNameCollisionError struct {
+	Enum    string
+	Member  string   // Python member name both entries map to
+	Entries []string // Colliding entry names, in the order they were seen
+}
+
+func (e *EnumMemberNameCollisionError) Error() string {
+	return fmt.Sprintf("enum %s entries %s both map to member name: %s", e.Enum, strings.Join(e.Entries, " and "), e.Member)
+}
+
+// FieldGroupTooSmallError is returned when a structure field group names fewer than two fields
+type FieldGroupTooSmallError struct {
+	Structure string
+	Group     string
+}
+
+func (e *FieldGroupTooSmallError) Error() string {
+	return fmt.Sprintf("structure %s field group %s needs at least two fields", e.Structure, e.Group)
+}
+
+// RootStructureFieldsError is returned when a structure's root field isn't its only field
+type RootStructureFieldsError struct {
+	Structure string
+	Field     string // Root field name
+}
+
+func (e *RootStructureFieldsError) Error() string {
+	return fmt.Sprintf("structure %s root field %s must be its only field", e.Structure, e.Field)
+}
+
+// DiscriminatorNotLiteralError is returned when a member of a many-polymorphic union declares its
+// discriminator field with a type other than a Literal, which Pydantic can't discriminate on
+type DiscriminatorNotLiteralError struct {
+	Model string
+	Field string // Discriminator field name
+}
+
+func (e *DiscriminatorNotLiteralError) Error() string {
+	return fmt.Sprintf("model %s is a polymorphic union member but its %s field is not a Literal (add choice attributes or remove it)", e.Model, e.Field)
+}
+
+// ConfigValidationError is returned when a configuration option holds an invalid value
+type ConfigValidationError = cfg.ConfigValidationError
+
 // Python-specific errors
 func ErrReservedKeyword(word string) error {
 	return fmt.Errorf("'%s' is a reserved Python keyword", word)
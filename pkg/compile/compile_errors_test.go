@@ -0,0 +1,135 @@
+package compile_test
+
+import (
+	"errors"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestCompileEntity_RelationResolveError() {
+	r := newCustomerOrderRegistry()
+	entity := yaml.Entity{
+		Name: "Customer",
+		Fields: map[string]yaml.EntityField{
+			"Region": {Type: "Customer.Address.Region"},
+		},
+	}
+
+	_, err := compile.CompileEntity(entity, r)
+
+	var typeMapErr *compile.TypeMapError
+	suite.Require().True(errors.As(err, &typeMapErr))
+	suite.Equal("Customer", typeMapErr.Owner)
+	suite.Equal("Region", typeMapErr.Field)
+
+	var relationErr *compile.RelationResolveError
+	suite.Require().True(errors.As(err, &relationErr))
+	suite.Equal("Customer", relationErr.Model)
+	suite.Equal("Address", relationErr.Relation)
+	suite.EqualError(err, "failed to map field type for Customer.Region: failed to resolve relation Address in model Customer")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_RelationResolveErrorMissingTarget() {
+	r := newCustomerOrderRegistry()
+	customer, _ := r.GetModel("Customer")
+	customer.Related["Invoices"] = yaml.ModelRelation{Type: "HasMany", Aliased: "Invoice"}
+	r.SetModel("Customer", customer)
+	entity := yaml.Entity{
+		Name: "Customer",
+		Fields: map[string]yaml.EntityField{
+			"InvoiceTotal": {Type: "Customer.Invoices.Total"},
+		},
+	}
+
+	_, err := compile.CompileEntity(entity, r)
+
+	var relationErr *compile.RelationResolveError
+	suite.Require().True(errors.As(err, &relationErr))
+	suite.Equal("Customer", relationErr.Model)
+	suite.Equal("Invoices", relationErr.Relation)
+	suite.ErrorContains(err, "failed to resolve relation Invoices in model Customer")
+}
+
+func (suite *CompileTestSuite) TestCompileEntity_TypeMapErrorUnknownModel() {
+	entity := yaml.Entity{
+		Name: "Invoice",
+		Fields: map[string]yaml.EntityField{
+			"ID": {Type: "Invoice.ID"},
+		},
+	}
+
+	_, err := compile.CompileEntity(entity, newCustomerOrderRegistry())
+
+	var typeMapErr *compile.TypeMapError
+	suite.Require().True(errors.As(err, &typeMapErr))
+	suite.Equal("ID", typeMapErr.Field)
+	var relationErr *compile.RelationResolveError
+	suite.False(errors.As(err, &relationErr))
+	var modelErr *compile.ModelNotFoundError
+	suite.Require().True(errors.As(err, &modelErr))
+	suite.Equal("Invoice", modelErr.Model)
+}
+
+func (suite *CompileTestSuite) TestValidate_ConfigValidationError() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.MorpheConfig.Entities.LazyLoadingStyle = "deferred"
+
+	err := config.Validate()
+
+	var configErr *compile.ConfigValidationError
+	suite.Require().True(errors.As(err, &configErr))
+	suite.Equal("entities.lazyLoadingStyle", configErr.Option)
+}
+
+func (suite *CompileTestSuite) TestValidate_IndentSize() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.FormatConfig.IndentSize = 0
+
+	err := config.Validate()
+
+	suite.EqualError(err, "invalid indentSize: must be positive")
+}
+
+func (suite *CompileTestSuite) TestValidateFieldNameConvention_MixedCasing() {
+	r := registry.NewRegistry()
+	r.SetModel("Person", yaml.Model{
+		Name: "Person",
+		Fields: map[string]yaml.ModelField{
+			"ID":        {Type: yaml.ModelFieldTypeAutoIncrement},
+			"firstName": {Type: yaml.ModelFieldTypeString},
+			"last_name": {Type: yaml.ModelFieldTypeString},
+			"NickName":  {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetStructure("Address", yaml.Structure{
+		Name: "Address",
+		Fields: map[string]yaml.StructureField{
+			"street_name": {Type: yaml.StructureFieldTypeString},
+		},
+	})
+
+	err := compile.ValidateFieldNameConvention(r, "PascalCase")
+
+	var conventionErr *compile.FieldNameConventionError
+	suite.Require().True(errors.As(err, &conventionErr))
+	suite.Equal([]string{"Address.street_name", "Person.firstName", "Person.last_name"}, conventionErr.Fields)
+	suite.EqualError(err, "field names do not follow PascalCase: Address.street_name, Person.firstName, Person.last_name")
+
+	suite.NoError(compile.ValidateFieldNameConvention(r, ""))
+}
+
+func (suite *CompileTestSuite) TestValidate_FieldNameConvention() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.MorpheConfig.FieldNameConvention = "kebab-case"
+
+	err := config.Validate()
+
+	var configErr *compile.ConfigValidationError
+	suite.Require().True(errors.As(err, &configErr))
+	suite.Equal("fieldNameConvention", configErr.Option)
+}
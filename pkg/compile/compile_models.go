@@ -1,9 +1,13 @@
 package compile
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/kalo-build/morphe-go/pkg/registry"
 	"github.com/kalo-build/morphe-go/pkg/yaml"
@@ -23,6 +27,317 @@ func hasAttribute(attributes []string, target string) bool {
 	return false
 }
 
+// generatedFieldAttributes mark fields whose value the database assigns
+var generatedFieldAttributes = []string{"auto", "sequence", "identity"}
+
+// isGeneratedField reports whether a model field is an auto-increment, sequence or identity id.
+func isGeneratedField(field yaml.ModelField) bool {
+	if field.Type == yaml.ModelFieldTypeAutoIncrement {
+		return true
+	}
+	for _, attr := range generatedFieldAttributes {
+		if hasAttribute(field.Attributes, attr) {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeValue returns the value of a "key:value" attribute, if present.
+func attributeValue(attributes []string, key string) (string, bool) {
+	prefix := key + ":"
+	for _, attr := range attributes {
+		if strings.HasPrefix(attr, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(attr, prefix)), true
+		}
+	}
+	return "", false
+}
+
+// renderDefaultValue renders a raw attribute value as a Python literal for the given type
+func renderDefaultValue(value string, fieldType formatdef.Type) string {
+	switch fieldType.GetName() {
+	case "str":
+		return fmt.Sprintf("%q", value)
+	case "bool":
+		if strings.EqualFold(value, "true") {
+			return "True"
+		}
+		return "False"
+	default:
+		return value
+	}
+}
+
+// attributeValues returns the values of every "key:value" attribute, in declaration order.
+func attributeValues(attributes []string, key string) []string {
+	prefix := key + ":"
+	var values []string
+	for _, attr := range attributes {
+		if strings.HasPrefix(attr, prefix) {
+			values = append(values, strings.TrimSpace(strings.TrimPrefix(attr, prefix)))
+		}
+	}
+	return values
+}
+
+// numericLiteralPattern matches a Python int or float literal
+var numericLiteralPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// integerLiteralPattern matches a Python int literal
+var integerLiteralPattern = regexp.MustCompile(`^[+-]?\d+$`)
+
+// checkNumericAttribute returns an AttributeValueError when an attribute value of an int or
+// float field, which is rendered verbatim, isn't a literal of the field's type. Bounds may be
+// fractional on int fields too.
+func checkNumericAttribute(owner string, fieldName string, attribute string, value string, fieldType formatdef.Type) error {
+	typeName := fieldType.GetName()
+	if typeName != "int" && typeName != "float" {
+		return nil
+	}
+	pattern := numericLiteralPattern
+	if typeName == "int" && !containsString(numericBoundKeywords, attribute) {
+		pattern = integerLiteralPattern
+	}
+	if !pattern.MatchString(value) {
+		return &AttributeValueError{Owner: owner, Field: fieldName, Attribute: attribute, Value: value, Type: typeName}
+	}
+	return nil
+}
+
+// fieldExamples renders the sample values declared through "example:<value>" attributes
+func fieldExamples(owner string, fieldName string, attributes []string, fieldType formatdef.Type) ([]string, error) {
+	var examples []string
+	for _, value := range attributeValues(attributes, "example") {
+		if err := checkNumericAttribute(owner, fieldName, "example", value, fieldType); err != nil {
+			return nil, err
+		}
+		examples = append(examples, renderDefaultValue(value, fieldType))
+	}
+	return examples, nil
+}
+
+// fieldValue renders the right-hand side of a field declaration, wrapping the default in
+// Field(...) when keyword arguments are present. An empty defaultValue marks a required field.
+func fieldValue(defaultValue string, kwargs []string) string {
+	if len(kwargs) == 0 {
+		return defaultValue
+	}
+	var args []string
+	if defaultValue != "" {
+		args = append(args, "default="+defaultValue)
+	}
+	args = append(args, kwargs...)
+	return fmt.Sprintf("Field(%s)", strings.Join(args, ", "))
+}
+
+// nullableAnnotation returns the annotation and default of an instance field: Optional[T] = None
+// for a nullable field and a required T without default otherwise. Nullability comes from the
+// Morphe metadata alone, the "optional" attribute or a relationship foreign key, never from the
+// field name, so a field has the same optionality in models, structures and entities.
+func nullableAnnotation(field formatdef.Field, fieldType string) (annotation string, defaultValue string) {
+	if field.IsOptional {
+		return fmt.Sprintf("Optional[%s]", fieldType), "None"
+	}
+	return fieldType, ""
+}
+
+// numericBoundKeywords are the Field(...) numeric bound keywords in their rendering order, lower
+// bounds before upper bounds, keeping the output independent of map iteration order
+var numericBoundKeywords = []string{"gt", "ge", "lt", "le"}
+
+// fieldBounds collects the "gt:", "ge:", "lt:" and "le:" attributes of a numeric field
+func fieldBounds(owner string, fieldName string, attributes []string, fieldType formatdef.Type) (map[string]string, error) {
+	if typeName := fieldType.GetName(); typeName != "int" && typeName != "float" {
+		return nil, nil
+	}
+	var bounds map[string]string
+	for _, keyword := range numericBoundKeywords {
+		if value, ok := attributeValue(attributes, keyword); ok {
+			if err := checkNumericAttribute(owner, fieldName, keyword, value, fieldType); err != nil {
+				return nil, err
+			}
+			if bounds == nil {
+				bounds = make(map[string]string)
+			}
+			bounds[keyword] = value
+		}
+	}
+	return bounds, nil
+}
+
+// fieldKwargs collects the Field(...) keyword arguments enabled for a model field
+func fieldKwargs(field formatdef.Field, config PydanticConfig, generateExamples bool) []string {
+	var kwargs []string
+	if field.Pattern != "" {
+		// Pydantic v2 renamed the regex keyword to pattern
+		keyword := "regex"
+		if config.PydanticV2 {
+			keyword = "pattern"
+		}
+		kwargs = append(kwargs, fmt.Sprintf("%s=%s", keyword, rawStringLiteral(field.Pattern)))
+	}
+	for _, keyword := range numericBoundKeywords {
+		if value, ok := field.Bounds[keyword]; ok {
+			kwargs = append(kwargs, fmt.Sprintf("%s=%s", keyword, value))
+		}
+	}
+	if generateExamples && len(field.Examples) > 0 {
+		kwargs = append(kwargs, fmt.Sprintf("examples=[%s]", strings.Join(field.Examples, ", ")))
+	}
+	if field.Description != "" {
+		kwargs = append(kwargs, fmt.Sprintf("description=%q", field.Description))
+	}
+	return kwargs
+}
+
+// visibilityKwargs renders the Field(...) keyword arguments hiding a field from serialization
+// and from the generated __repr__
+func visibilityKwargs(field formatdef.Field) []string {
+	var kwargs []string
+	if field.Exclude {
+		kwargs = append(kwargs, "exclude=True")
+	}
+	if field.HideRepr {
+		kwargs = append(kwargs, "repr=False")
+	}
+	return kwargs
+}
+
+// aliasKwargs renders the Field(...) keyword arguments aliasing a field. Pydantic v2 gets
+// validation_alias and serialization_alias, or a plain alias when both are the same; v1 only
+// has alias, used for whichever direction is configured. Without a configured alias case, the
+// field keeps its original Morphe key as the alias. Aliases matching the Python field name are
+// left out.
+func aliasKwargs(field formatdef.Field, fieldName string, config PydanticConfig, modelConfig cfg.ModelConfig) []string {
+	validationAlias := fieldAlias(field.Name, modelConfig.ValidationAlias)
+	serializationAlias := fieldAlias(field.Name, modelConfig.SerializationAlias)
+	if modelConfig.ValidationAlias == "" && modelConfig.SerializationAlias == "" {
+		validationAlias = config.fieldKeyAlias(field)
+		serializationAlias = validationAlias
+	}
+	if validationAlias == fieldName {
+		validationAlias = ""
+	}
+	if serializationAlias == fieldName {
+		serializationAlias = ""
+	}
+
+	if validationAlias == serializationAlias || !config.PydanticV2 {
+		alias := validationAlias
+		if alias == "" {
+			alias = serializationAlias
+		}
+		if alias == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf("alias=%q", alias)}
+	}
+	var kwargs []string
+	if validationAlias != "" {
+		kwargs = append(kwargs, fmt.Sprintf("validation_alias=%q", validationAlias))
+	}
+	if serializationAlias != "" {
+		kwargs = append(kwargs, fmt.Sprintf("serialization_alias=%q", serializationAlias))
+	}
+	return kwargs
+}
+
+// preservesFieldKeys reports whether any attribute of a model is aliased to its original Morphe
+// field key
+func preservesFieldKeys(model *formatdef.Struct, config PydanticConfig, modelConfig cfg.ModelConfig) bool {
+	if !config.AddTypeHints || modelConfig.ValidationAlias != "" || modelConfig.SerializationAlias != "" {
+		return false
+	}
+	return config.hasFieldKeyAliases(model.Fields)
+}
+
+// fieldAlias derives the alias of a Morphe field name in the given naming convention (empty
+// when no alias is configured)
+func fieldAlias(morpheName string, aliasCase string) string {
+	snakeName := formatdef.ToSnakeCase(morpheName)
+	switch aliasCase {
+	case cfg.FieldNameConventionCamelCase:
+		return formatdef.ToCamelCase(snakeName)
+	case cfg.FieldNameConventionPascalCase:
+		return formatdef.ToPascalCase(snakeName)
+	case cfg.FieldNameConventionSnakeCase:
+		return snakeName
+	}
+	return ""
+}
+
+// rawStringLiteral renders a regular expression as a Python raw string literal. Backslashes are
+// kept verbatim; double quotes are escaped, which the regex engine treats as a literal quote.
+func rawStringLiteral(pattern string) string {
+	return `r"` + strings.ReplaceAll(pattern, `"`, `\"`) + `"`
+}
+
+// polymorphicCandidates returns the distinct "for" models of a polymorphic relationship, in
+// declaration order
+func polymorphicCandidates(forModels []string) []string {
+	var candidates []string
+	for _, forModel := range forModels {
+		if !containsString(candidates, forModel) {
+			candidates = append(candidates, forModel)
+		}
+	}
+	return candidates
+}
+
+// polymorphicUnion renders the Union of quoted candidate models of a polymorphic relationship
+func polymorphicUnion(forModels []string) string {
+	var members []string
+	for _, forModel := range polymorphicCandidates(forModels) {
+		members = append(members, `"`+forModel+`"`)
+	}
+	return "Union[" + strings.Join(members, ", ") + "]"
+}
+
+// manyPolymorphicUnion renders the element type of a many-polymorphic relationship: a Union of
+// its candidates discriminated by the configured discriminator field, or the single candidate
+func manyPolymorphicUnion(forModels []string, config PydanticConfig) formatdef.Type {
+	union := formatdef.BasicType{Name: polymorphicUnion(forModels)}
+	discriminatorName := config.polymorphicDiscriminator()
+	if len(polymorphicCandidates(forModels)) < 2 || discriminatorName == "" {
+		return union
+	}
+	discriminator := fmt.Sprintf("Field(discriminator=%q)", config.pythonFieldName(discriminatorName))
+	return formatdef.AnnotatedType{Type: union, Metadata: []string{discriminator}}
+}
+
+// isManyPolymorphicMember reports whether a model is one of several candidates of a
+// many-polymorphic relationship, and so needs the union discriminator field
+func isManyPolymorphicMember(modelName string, r *registry.Registry) bool {
+	for _, model := range r.GetAllModels() {
+		for _, relation := range model.Related {
+			relationType := string(relation.Type)
+			if !yamlops.IsRelationPoly(relationType) || !yamlops.IsRelationMany(relationType) {
+				continue
+			}
+			candidates := polymorphicCandidates(relation.For)
+			if len(candidates) > 1 && containsString(candidates, modelName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compositePrimaryKey returns the primary key fields of a model when it has a composite key, or
+// a single "ID" component otherwise, so foreign keys render as <relation>_<key field>
+func compositePrimaryKey(modelName string, r *registry.Registry) []string {
+	model, err := r.GetModel(modelName)
+	if err != nil {
+		return []string{"ID"}
+	}
+	primary, hasPrimary := model.Identifiers["primary"]
+	if !hasPrimary || len(primary.Fields) < 2 {
+		return []string{"ID"}
+	}
+	return primary.Fields
+}
+
 // resolvePolymorphicThrough looks up the model that has the polymorphic relationship
 func resolvePolymorphicThrough(through string, r *registry.Registry) (string, error) {
 	// Find the model that has this polymorphic relationship
@@ -33,11 +348,162 @@ func resolvePolymorphicThrough(through string, r *registry.Registry) (string, er
 			}
 		}
 	}
-	return "", fmt.Errorf("polymorphic relationship %s not found", through)
+	return "", &RelationResolveError{Relation: through}
 }
 
 // CompileModel converts a Morphe model to the target format
 func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, error) {
+	return compileModel(model, r, PydanticConfig{})
+}
+
+// mapFieldType maps a Morphe field type through the resolution sources in their configured
+// order. In strict mode types without a known mapping are an error.
+func mapFieldType(fieldType yaml.ModelFieldType, r *registry.Registry, config PydanticConfig) (formatdef.Type, error) {
+	if formatType, resolved := config.typeResolver().Resolve(fieldType); resolved {
+		return config.pydanticFieldType(formatType), nil
+	}
+	if config.StrictTypes {
+		formatType, err := typemap.GetFieldTypeStrict(fieldType, r)
+		if err != nil {
+			return nil, err
+		}
+		return config.pydanticFieldType(formatType), nil
+	}
+	return config.pydanticFieldType(typemap.GetFieldType(fieldType)), nil
+}
+
+// knownRelationTypes are the Morphe relationship types the compiler handles
+var knownRelationTypes = map[string]bool{
+	"ForOne":      true,
+	"ForMany":     true,
+	"HasOne":      true,
+	"HasMany":     true,
+	"ForOnePoly":  true,
+	"ForManyPoly": true,
+	"HasOnePoly":  true,
+	"HasManyPoly": true,
+}
+
+// unknownRelationTypes returns a RelationResolveError for every model relationship of an unknown
+// type, sorted by model and relationship name
+func unknownRelationTypes(r *registry.Registry) []error {
+	models := r.GetAllModels()
+	modelNames := make([]string, 0, len(models))
+	for modelName := range models {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+
+	var unknown []error
+	for _, modelName := range modelNames {
+		relNames := make([]string, 0, len(models[modelName].Related))
+		for relName := range models[modelName].Related {
+			relNames = append(relNames, relName)
+		}
+		sort.Strings(relNames)
+		for _, relName := range relNames {
+			relationType := string(models[modelName].Related[relName].Type)
+			if !knownRelationTypes[relationType] {
+				unknown = append(unknown, &RelationResolveError{Model: modelName, Relation: relName, Err: ErrUnknownRelationType(relationType)})
+			}
+		}
+	}
+	return unknown
+}
+
+// withoutUnknownRelations returns a copy of the registry without the model relationships of an
+// unknown type, warning about each one since their fields silently go missing. The registry is
+// returned as is when every relationship type is known.
+func withoutUnknownRelations(config MorpheCompileConfig, r *registry.Registry) *registry.Registry {
+	unknown := unknownRelationTypes(r)
+	if len(unknown) == 0 {
+		return r
+	}
+
+	filtered := registry.NewRegistry()
+	for enumName, enum := range r.GetAllEnums() {
+		filtered.SetEnum(enumName, enum)
+	}
+	for structureName, structure := range r.GetAllStructures() {
+		filtered.SetStructure(structureName, structure)
+	}
+	for entityName, entity := range r.GetAllEntities() {
+		filtered.SetEntity(entityName, entity)
+	}
+	for modelName, model := range r.GetAllModels() {
+		related := make(map[string]yaml.ModelRelation, len(model.Related))
+		for relName, relation := range model.Related {
+			if knownRelationTypes[string(relation.Type)] {
+				related[relName] = relation
+			}
+		}
+		model.Related = related
+		filtered.SetModel(modelName, model)
+	}
+
+	for _, err := range unknown {
+		config.warnf("%v; relation skipped", err)
+	}
+	return filtered
+}
+
+// polymorphicNavType returns the navigation element type of a polymorphic relationship. When it
+// has neither candidate models nor a resolvable through relationship it falls back to Any,
+// returned along with the reason.
+func polymorphicNavType(relation yaml.ModelRelation, r *registry.Registry, config PydanticConfig) (formatdef.Type, error) {
+	relationType := string(relation.Type)
+	if len(relation.For) > 0 && yamlops.IsRelationMany(relationType) {
+		// Collections mixing several models need a discriminated Union
+		return manyPolymorphicUnion(relation.For, config), nil
+	}
+	if len(relation.For) > 0 {
+		// Create a custom type representing the Union
+		return formatdef.BasicType{Name: polymorphicUnion(relation.For)}, nil
+	}
+	if relation.Through != "" {
+		// HasManyPoly/HasOnePoly with through - resolve the actual model
+		throughModel, err := resolvePolymorphicThrough(relation.Through, r)
+		if err != nil {
+			return formatdef.TypeAny, err
+		}
+		return formatdef.BasicType{Name: throughModel}, nil
+	}
+	return formatdef.TypeAny, ErrNoPolymorphicTargets
+}
+
+// polymorphicFallbacks returns a RelationResolveError for every polymorphic relationship of the
+// registry's models typed as Any, sorted by model and relationship name
+func polymorphicFallbacks(r *registry.Registry, config PydanticConfig) []error {
+	models := r.GetAllModels()
+	modelNames := make([]string, 0, len(models))
+	for modelName := range models {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+
+	var fallbacks []error
+	for _, modelName := range modelNames {
+		relNames := make([]string, 0, len(models[modelName].Related))
+		for relName := range models[modelName].Related {
+			relNames = append(relNames, relName)
+		}
+		sort.Strings(relNames)
+		for _, relName := range relNames {
+			relation := models[modelName].Related[relName]
+			if !yamlops.IsRelationPoly(string(relation.Type)) {
+				continue
+			}
+			if _, err := polymorphicNavType(relation, r, config); err != nil {
+				fallbacks = append(fallbacks, &RelationResolveError{Model: modelName, Relation: relName, Err: err})
+			}
+		}
+	}
+	return fallbacks
+}
+
+// compileModel converts a Morphe model using the custom type mappings and strictness of the
+// format config
+func compileModel(model yaml.Model, r *registry.Registry, config PydanticConfig) (*formatdef.Struct, error) {
 	// Create the struct definition
 	formatStruct := &formatdef.Struct{
 		Name:   model.Name,
@@ -50,19 +516,69 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 		fieldNames = append(fieldNames, name)
 	}
 	sort.Strings(fieldNames)
+	primaryFields := model.Identifiers["primary"].Fields
 
 	// Add fields
 	for _, fieldName := range fieldNames {
 		field := model.Fields[fieldName]
-		fieldType := typemap.GetFieldType(field.Type)
+		fieldType, err := mapFieldType(field.Type, r, config)
+		var unmappedErr *typemap.UnmappedTypeError
+		if errors.As(err, &unmappedErr) {
+			return nil, &UnmappedFieldTypeError{Owner: model.Name, Field: fieldName, Type: unmappedErr.Type}
+		}
+		if err != nil {
+			return nil, &TypeMapError{Owner: model.Name, Field: fieldName, Err: err}
+		}
+		examples, err := fieldExamples(model.Name, fieldName, field.Attributes, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		bounds, err := fieldBounds(model.Name, fieldName, field.Attributes, fieldType)
+		if err != nil {
+			return nil, err
+		}
 		formatField := formatdef.Field{
-			Name:       fieldName,
-			Type:       fieldType,
-			IsOptional: hasAttribute(field.Attributes, "optional"),
+			Name:        fieldName,
+			Type:        fieldType,
+			IsOptional:  hasAttribute(field.Attributes, "optional"),
+			IsGenerated: isGeneratedField(field),
+			IsIdentity:  containsString(primaryFields, fieldName),
+			Examples:    examples,
+			Bounds:      bounds,
+			HideRepr:    hasAttribute(field.Attributes, "norepr"),
+			Exclude:     hasAttribute(field.Attributes, "exclude"),
+			IsFrozen:    hasAttribute(field.Attributes, "frozen"),
+		}
+		if pattern, ok := attributeValue(field.Attributes, "pattern"); ok && fieldType.GetName() == "str" {
+			formatField.Pattern = pattern
+		}
+		if description, ok := attributeValue(field.Attributes, "description"); ok {
+			formatField.Description = description
+		}
+		if choices := attributeValues(field.Attributes, "choice"); len(choices) > 0 && fieldType.GetName() == "str" {
+			formatField.Type = formatdef.LiteralType{Values: choices}
 		}
 		formatStruct.Fields = append(formatStruct.Fields, formatField)
 	}
 
+	// Members of a many-polymorphic union carry a Literal of their name as its discriminator; a
+	// declared discriminator must be a Literal itself for the union's Field(discriminator=...)
+	if discriminatorName := config.polymorphicDiscriminator(); discriminatorName != "" && isManyPolymorphicMember(model.Name, r) {
+		if _, declared := model.Fields[discriminatorName]; !declared {
+			formatStruct.Fields = append(formatStruct.Fields, formatdef.Field{
+				Name:    discriminatorName,
+				Type:    formatdef.LiteralType{Values: []string{model.Name}},
+				Default: strconv.Quote(model.Name),
+			})
+		} else {
+			for _, field := range formatStruct.Fields {
+				if _, isLiteral := field.Type.(formatdef.LiteralType); field.Name == discriminatorName && !isLiteral {
+					return nil, &DiscriminatorNotLiteralError{Model: model.Name, Field: discriminatorName}
+				}
+			}
+		}
+	}
+
 	// Process related models (if any)
 	if len(model.Related) > 0 {
 		// Sort related for consistent output
@@ -72,6 +588,15 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 		}
 		sort.Strings(relatedNames)
 
+		// Relationships of an unknown type fail the build; in warn mode withoutUnknownRelations
+		// has already removed them from the registry
+		for _, relatedName := range relatedNames {
+			relationType := string(model.Related[relatedName].Type)
+			if !knownRelationTypes[relationType] {
+				return nil, &RelationResolveError{Model: model.Name, Relation: relatedName, Err: ErrUnknownRelationType(relationType)}
+			}
+		}
+
 		// Add foreign key fields
 		for _, relatedName := range relatedNames {
 			relation := model.Related[relatedName]
@@ -81,14 +606,20 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 			if yamlops.IsRelationPoly(relationType) && yamlops.IsRelationFor(relationType) && yamlops.IsRelationOne(relationType) {
 				// ForOnePoly: Add type and id fields
 				typeField := formatdef.Field{
-					Name: formatdef.ToCamelCase(relatedName + "_type"),
-					Type: formatdef.TypeString,
+					Name:       formatdef.ToCamelCase(relatedName + "_type"),
+					Type:       formatdef.TypeString,
+					IsOptional: true,
+					IsImplicit: true,
+					PolyNav:    "_nav_" + relatedName,
 				}
 				formatStruct.Fields = append(formatStruct.Fields, typeField)
 
 				idField := formatdef.Field{
-					Name: formatdef.ToCamelCase(relatedName + "_id"),
-					Type: formatdef.TypeString,
+					Name:       formatdef.ToCamelCase(relatedName + "_id"),
+					Type:       formatdef.TypeString,
+					IsOptional: true,
+					IsIdentity: true,
+					IsImplicit: true,
 				}
 				formatStruct.Fields = append(formatStruct.Fields, idField)
 			} else if yamlops.IsRelationPoly(relationType) {
@@ -96,12 +627,18 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 				// These don't add fields to the model, but affect how we handle relationships
 				continue
 			} else if yamlops.IsRelationFor(relationType) && yamlops.IsRelationOne(relationType) {
-				// Regular ForOne: Add foreign key field
-				relField := formatdef.Field{
-					Name: formatdef.ToCamelCase(relatedName + "_id"),
-					Type: formatdef.TypeString,
+				// Regular ForOne: Add a foreign key field per component of the target's primary key
+				targetModelName := yamlops.GetRelationTargetName(relatedName, relation.Aliased)
+				for _, keyFieldName := range compositePrimaryKey(targetModelName, r) {
+					relField := formatdef.Field{
+						Name:       formatdef.ToCamelCase(relatedName + "_" + formatdef.ToSnakeCase(keyFieldName)),
+						Type:       formatdef.TypeString,
+						IsOptional: true,
+						IsIdentity: true,
+						IsImplicit: true,
+					}
+					formatStruct.Fields = append(formatStruct.Fields, relField)
 				}
-				formatStruct.Fields = append(formatStruct.Fields, relField)
 			}
 			// HasOne, HasMany, ForMany don't add fields to this model
 		}
@@ -117,30 +654,10 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 			// Add navigation field based on relationship type
 			var navType formatdef.Type
 			if yamlops.IsRelationPoly(relationType) {
-				// Polymorphic relationships need Union types
-				if len(relation.For) > 0 {
-					// Create a custom type representing the Union
-					unionType := "Union["
-					for i, forModel := range relation.For {
-						if i > 0 {
-							unionType += ", "
-						}
-						unionType += "'" + forModel + "'"
-					}
-					unionType += "]"
-					navType = formatdef.BasicType{Name: unionType}
-				} else if relation.Through != "" {
-					// HasManyPoly/HasOnePoly with through - resolve the actual model
-					throughModel, err := resolvePolymorphicThrough(relation.Through, r)
-					if err != nil {
-						// Fallback to Any if we can't resolve
-						navType = formatdef.TypeAny
-					} else {
-						navType = formatdef.BasicType{Name: throughModel}
-					}
-				} else {
-					// No 'for' or 'through' specified, use Any
-					navType = formatdef.TypeAny
+				var err error
+				navType, err = polymorphicNavType(relation, r, config)
+				if err != nil && config.StrictRelations {
+					return nil, &RelationResolveError{Model: model.Name, Relation: relatedName, Err: err}
 				}
 			} else {
 				// Regular relationship
@@ -154,8 +671,9 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 
 			// Add navigation field (prefixed with _ to distinguish from data fields)
 			navField := formatdef.Field{
-				Name: "_nav_" + relatedName,
-				Type: navType,
+				Name:       "_nav_" + relatedName,
+				Type:       navType,
+				IsImplicit: true,
 			}
 			formatStruct.Fields = append(formatStruct.Fields, navField)
 		}
@@ -166,34 +684,231 @@ func CompileModel(model yaml.Model, r *registry.Registry) (*formatdef.Struct, er
 
 // CompileAllModels compiles all models and writes them using the writer
 func CompileAllModels(config MorpheCompileConfig, r *registry.Registry, writer *MorpheWriter) error {
+	writer.useConfig(config.FormatConfig)
+
 	modelContents := make(map[string][]byte)
+	stubContents := make(map[string][]byte)
+
+	// Incremental builds only regenerate the changed models and their dependents
+	var affected map[string]bool
+	if len(config.MorpheConfig.Models.OnlyModels) > 0 && writer.UseMultiFile {
+		var err error
+		affected, err = ResolveAffectedModels(r.GetAllModels(), config.MorpheConfig.Models.OnlyModels)
+		if err != nil {
+			return err
+		}
+	}
+
+	for modelName := range config.MorpheConfig.Models.BaseClasses {
+		if _, exists := r.GetAllModels()[modelName]; !exists && modelName != "*" {
+			return &ModelNotFoundError{Model: modelName}
+		}
+	}
+
+	for _, modelName := range config.MorpheConfig.Models.AbstractModels {
+		if _, exists := r.GetAllModels()[modelName]; !exists {
+			return &ModelNotFoundError{Model: modelName}
+		}
+	}
+
+	// Relationships of an unknown type are left out of every later pass in warn mode
+	if config.FormatConfig.UnknownRelations == UnknownRelationsWarn {
+		r = withoutUnknownRelations(config, r)
+	}
+
+	// Relationships typed as Any likely point at a mistake in the Morphe definition
+	if config.Verbose && !config.FormatConfig.StrictRelations {
+		for _, fallback := range polymorphicFallbacks(r, config.FormatConfig) {
+			config.warnf("%v; typed as Any", fallback)
+		}
+	}
+
+	fileTemplate, err := LoadModelFileTemplate(config.FormatConfig.FileTemplatePath)
+	if err != nil {
+		return fmt.Errorf("failed to load model file template: %w", err)
+	}
+
+	// Model modules place the header through the template; the single-file bundle writes its own
+	header := ""
+	if writer.UseMultiFile && writer.AddGeneratedHeader {
+		header = writer.getGeneratedHeader()
+	}
 
 	// Process each model in the registry
 	for modelName, model := range r.GetAllModels() {
+		if affected != nil && !affected[modelName] {
+			continue
+		}
+
 		// Compile the model
-		compiledModel, err := CompileModel(model, r)
+		compiledModel, err := compileModel(model, r, config.FormatConfig)
 		if err != nil {
 			return fmt.Errorf("failed to compile model %s: %w", modelName, err)
 		}
 
+		// Python 3.9+ targets use the builtin list/dict generics
+		if config.FormatConfig.PythonVersionAtLeast(3, 9) {
+			compiledModel.UseBuiltinGenerics()
+		}
+
+		// Fields declared by abstract bases are inherited rather than redeclared; stubs keep
+		// them so their __init__ signature stays complete
+		stubModel := compiledModel
+		if compiledModel, err = withoutInheritedFields(compiledModel, r, config); err != nil {
+			return err
+		}
+
+		// Type enum fields with their Literal aliases
+		if config.MorpheConfig.Enums.GenerateEnumLiterals {
+			useEnumLiterals(compiledModel, r)
+		}
+
+		// Type many-relationship navigations as read-only sequences
+		if config.MorpheConfig.Models.CollectionType == cfg.CollectionTypeSequence {
+			useSequenceRelations(compiledModel)
+		}
+
 		// Generate the content for this model
-		content := generateModelContent(compiledModel, config.FormatConfig, config.MorpheConfig, r)
+		content, err := generateModelContent(compiledModel, config.FormatConfig, config.MorpheConfig, r, fileTemplate, header)
+		if err != nil {
+			return err
+		}
 		modelContents[modelName] = content
+
+		if config.MorpheConfig.Models.GenerateStubs && writer.UseMultiFile {
+			stubContents[modelName] = generateModelStubContent(stubModel, config.FormatConfig, config.MorpheConfig, r)
+		}
+	}
+
+	// Write the .pyi stubs next to their models
+	for modelName, content := range stubContents {
+		if err := writer.WriteModelStub(modelName, content); err != nil {
+			return err
+		}
+	}
+
+	// Write the shared sentinel module used by tri-state fields
+	if config.MorpheConfig.Models.UseUnsetSentinel {
+		if err := writer.WriteSentinels(generateSentinelContent(config.FormatConfig)); err != nil {
+			return err
+		}
+	}
+
+	// The models __init__.py resolves the forward references between models
+	writer.RebuildModels = modelRebuildOrder(r.GetAllModels())
+	if config.MorpheConfig.Enums.DeferCyclicImports {
+		writer.DeferredEnums = cyclicModelEnums(r)
+	}
+
+	if affected != nil {
+		var allModelNames []string
+		for modelName := range r.GetAllModels() {
+			allModelNames = append(allModelNames, modelName)
+		}
+		return writer.WriteModelSubset(modelContents, allModelNames)
 	}
 
 	// Write all model contents
 	return writer.WriteAllModels(modelContents)
 }
 
+// writeAbstractGuard writes an __init__ raising TypeError when the abstract model itself, rather
+// than one of its subclasses, is instantiated
+func writeAbstractGuard(cb *formatdef.ContentBuilder, modelName string) {
+	cb.Line("")
+	cb.Line("def __init__(self, **data: Any) -> None:")
+	cb.Indent()
+	cb.Line("if type(self) is %s:", modelName)
+	cb.Indent()
+	cb.Line(`raise TypeError("%s is abstract; instantiate one of its subclasses")`, modelName)
+	cb.Dedent()
+	cb.Line("super().__init__(**data)")
+	cb.Dedent()
+}
+
+// withoutInheritedFields returns a copy of the model without the fields declared by the chain of
+// abstract models it extends, or the model itself when it doesn't extend one
+func withoutInheritedFields(model *formatdef.Struct, r *registry.Registry, config MorpheCompileConfig) (*formatdef.Struct, error) {
+	modelConfig := config.MorpheConfig.Models
+	inherited := make(map[string]bool)
+	visited := map[string]bool{model.Name: true}
+	for base := modelConfig.BaseClassFor(model.Name); modelConfig.IsAbstract(base) && !visited[base]; base = modelConfig.BaseClassFor(base) {
+		visited[base] = true
+		compiledBase, err := compileModel(r.GetAllModels()[base], r, config.FormatConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile model %s: %w", base, err)
+		}
+		for _, field := range compiledBase.Fields {
+			inherited[field.Name] = true
+		}
+	}
+	if len(inherited) == 0 {
+		return model, nil
+	}
+
+	stripped := *model
+	stripped.Fields = nil
+	for _, field := range model.Fields {
+		if !inherited[field.Name] {
+			stripped.Fields = append(stripped.Fields, field)
+		}
+	}
+	return &stripped, nil
+}
+
+// useEnumLiterals retypes the enum fields of a model with the enums' Literal aliases
+func useEnumLiterals(model *formatdef.Struct, r *registry.Registry) {
+	for i, field := range model.Fields {
+		if basicType, ok := field.Type.(formatdef.BasicType); ok && resolveFieldType(basicType.Name, r) == "enum" {
+			model.Fields[i].Type = formatdef.BasicType{Name: enumLiteralName(basicType.Name)}
+		}
+	}
+}
+
+// cyclicModelEnums returns the enums referenced by the models of a relationship cycle, which
+// those models import under TYPE_CHECKING when DeferCyclicImports is enabled
+func cyclicModelEnums(r *registry.Registry) []string {
+	models := r.GetAllModels()
+	graph := buildDependencyGraph(models)
+	var enumNames []string
+	for modelName, model := range models {
+		if !inCycle(modelName, graph) {
+			continue
+		}
+		for _, field := range model.Fields {
+			enumName := string(field.Type)
+			if resolveFieldType(enumName, r) == "enum" && !containsString(enumNames, enumName) {
+				enumNames = append(enumNames, enumName)
+			}
+		}
+	}
+	sort.Strings(enumNames)
+	return enumNames
+}
+
+// useSequenceRelations retypes the many-relationship navigations of a model as Sequence[X]
+func useSequenceRelations(model *formatdef.Struct) {
+	for i, field := range model.Fields {
+		if arrayType, isMany := field.Type.(formatdef.ArrayType); isMany && strings.HasPrefix(field.Name, "_nav_") {
+			arrayType.Sequence = true
+			model.Fields[i].Type = arrayType
+		}
+	}
+}
+
 // generateModelContent generates Python Pydantic model
-func generateModelContent(model *formatdef.Struct, config PydanticConfig, morpheConfig cfg.MorpheConfig, r *registry.Registry) []byte {
-	cb := formatdef.NewContentBuilder("    ")
+func generateModelContent(model *formatdef.Struct, config PydanticConfig, morpheConfig cfg.MorpheConfig, r *registry.Registry, fileTemplate *template.Template, header string) ([]byte, error) {
+	importsCB := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
 
 	// Create import tracker
 	imports := NewImportTracker(r)
+	imports.SetFileNaming(config.FileNaming)
+	imports.SetCurrentModel(model.Name)
+	imports.SetDeferCyclicEnums(morpheConfig.Enums.DeferCyclicImports)
 
-	// Add Pydantic imports
-	imports.AddPydantic("BaseModel")
+	// Add base class and Pydantic imports
+	baseClass := addBaseClassImport(imports, model.Name, morpheConfig.Models, config.FileNaming)
 	if morpheConfig.Models.UseField {
 		imports.AddPydantic("Field")
 	}
@@ -201,7 +916,7 @@ func generateModelContent(model *formatdef.Struct, config PydanticConfig, morphe
 	// Track whether we need model config
 	needsModelConfig := false
 	hasPolymorphicTypeField := false
-	polymorphicTypeToNavMap := make(map[string]string)
+	useUnset := config.AddTypeHints && morpheConfig.Models.UseUnsetSentinel
 
 	// Scan all fields to determine imports
 	for _, field := range model.Fields {
@@ -212,6 +927,7 @@ func generateModelContent(model *formatdef.Struct, config PydanticConfig, morphe
 
 		typeName := field.Type.GetName()
 		imports.TrackFieldType(typeName)
+		imports.AddStatement(config.customTypeImports(typeName)...)
 
 		// Check if this field is an enum
 		if basicType, ok := field.Type.(formatdef.BasicType); ok {
@@ -221,16 +937,22 @@ func generateModelContent(model *formatdef.Struct, config PydanticConfig, morphe
 			}
 		}
 
+		// Tri-state optional fields need the shared sentinel
+		if field.IsOptional && useUnset {
+			imports.AddFrom(".."+sentinelsModuleName, "UNSET", "Unset")
+			if !config.PythonVersionAtLeast(3, 10) {
+				imports.AddTyping("Union")
+			}
+		}
+
 		// Check for polymorphic type fields
-		if strings.HasSuffix(field.Name, "_type") && typeName == "str" {
-			// Look for corresponding nav field
-			navFieldName := "_nav_" + strings.TrimSuffix(field.Name, "_type")
-			polymorphicTypeToNavMap[field.Name] = navFieldName
+		if field.PolyNav != "" {
 			hasPolymorphicTypeField = true
 		}
 	}
 
 	// Scan navigation properties
+	generateCounts := config.PydanticV2 && morpheConfig.Models.GenerateCollectionCounts
 	for _, field := range model.Fields {
 		if !strings.HasPrefix(field.Name, "_nav_") {
 			continue
@@ -238,140 +960,519 @@ func generateModelContent(model *formatdef.Struct, config PydanticConfig, morphe
 
 		typeName := field.Type.GetName()
 		imports.TrackFieldType(typeName)
+
+		// Discriminated unions carry Field(discriminator=...) metadata
+		if strings.Contains(typeName, "Annotated[") {
+			addAnnotatedImport(imports, config)
+			imports.AddPydantic("Field")
+		}
+
+		if _, isMany := field.Type.(formatdef.ArrayType); isMany && generateCounts {
+			imports.AddPydantic("computed_field")
+		}
+	}
+
+	fieldDecls, counts := modelFieldDecls(model, config, morpheConfig)
+
+	// Enums imported under TYPE_CHECKING are only available to quoted annotations
+	for i := range fieldDecls {
+		fieldDecls[i].Annotation = imports.QuoteDeferredEnums(fieldDecls[i].Annotation)
+	}
+
+	// Field(...) is needed for patterns, examples and default factories
+	for _, decl := range fieldDecls {
+		if strings.HasPrefix(decl.Value, "Field(") {
+			imports.AddPydantic("Field")
+		}
+		if len(decl.Metadata) > 0 {
+			addAnnotatedImport(imports, config)
+		}
+		for _, metadata := range decl.Metadata {
+			if strings.HasPrefix(metadata, "Field(") {
+				imports.AddPydantic("Field")
+			} else if strings.HasPrefix(metadata, "StringConstraints(") {
+				imports.AddPydantic("StringConstraints")
+			}
+		}
+	}
+
+	// Complex types shared by several fields become module-level aliases
+	aliasesCB := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+	if morpheConfig.Models.HoistTypeAliases && config.AddTypeHints {
+		aliases := hoistTypeAliases(fieldDecls)
+		if len(aliases) > 0 {
+			addTypeAliasImport(imports, config)
+		}
+		for _, alias := range aliases {
+			aliasesCB.Line("%s", alias.String())
+		}
+	}
+
+	// Whole-model serializer hook (Pydantic v2)
+	generateSerializer := config.PydanticV2 && morpheConfig.Models.GenerateModelSerializer
+	var serializerType formatdef.Type = formatdef.TypeJSON
+	if config.PythonVersionAtLeast(3, 9) {
+		serializerType = formatdef.WithBuiltinGenerics(serializerType)
+	}
+	if generateSerializer {
+		imports.AddPydantic("model_serializer")
+		imports.TrackFieldType(serializerType.GetName())
 	}
 
 	// We always need Optional for navigation properties
 	if config.AddTypeHints {
 		imports.AddTyping("Optional")
 	}
+	for _, decl := range fieldDecls {
+		if strings.Contains(decl.Annotation, "ForwardRef(") {
+			imports.AddTyping("ForwardRef")
+		}
+	}
+
+	// Pydantic v1 checks datetimes for a timezone with a validator
+	awareDatetimeFields := awareDatetimeFieldNames(model.Fields, config)
+	if len(awareDatetimeFields) > 0 {
+		imports.AddPydantic("validator")
+	}
+
+	// The abstract guard accepts arbitrary keyword arguments
+	if morpheConfig.Models.IsAbstract(model.Name) && len(model.Fields) > 0 {
+		imports.AddTyping("Any")
+	}
 
 	// Add Literal if we have polymorphic type fields
 	if hasPolymorphicTypeField {
 		imports.AddTyping("Literal")
 	}
 
+	// Typed model_config (Pydantic v2)
+	useConfigDict := config.PydanticV2 && morpheConfig.Models.UseConfigDict && len(model.Fields) > 0
+	generateSchemaExamples := morpheConfig.Models.GenerateSchemaExamples && len(model.Fields) > 0
+	// Fields aliased to their Morphe key stay populatable by their Python name
+	populateByName := morpheConfig.Models.PopulateByName || preservesFieldKeys(model, config, morpheConfig.Models)
+	if useConfigDict && (needsModelConfig || populateByName || generateSchemaExamples) {
+		imports.AddPydantic("ConfigDict")
+	}
+
 	// Generate imports
-	imports.Generate(cb)
-	cb.Line("")
+	imports.Generate(importsCB)
 
 	// Generate class
-	cb.Line("class %s(BaseModel):", model.Name)
+	cb.Line("class %s(%s):", model.Name, baseClass)
 	cb.Indent()
 
 	// Add docstring
-	cb.Line(`"""%s model."""`, model.Name)
+	writeClassDocstring(cb, model.Name+" model.", modelDocstringFields(model, fieldDecls, config), config.DocstringStyle)
 
 	if len(model.Fields) == 0 {
 		cb.Line("pass")
 	} else {
-		// Add fields
-		for _, field := range model.Fields {
-			// Skip navigation properties
-			if strings.HasPrefix(field.Name, "_nav_") {
-				continue
-			}
+		// Add fields and navigation properties (relationships)
+		for _, decl := range fieldDecls {
+			cb.Line("%s", decl.String())
+		}
 
-			fieldName := SanitizePythonIdentifier(formatdef.ToSnakeCase(field.Name))
-			fieldType := field.Type.GetName()
-
-			// Add type hint
-			if config.AddTypeHints {
-				// Check if this is a polymorphic type field
-				if navFieldName, isPolyType := polymorphicTypeToNavMap[field.Name]; isPolyType {
-					// Look for the navigation field to get allowed types
-					var allowedTypes []string
-					for _, navField := range model.Fields {
-						if navField.Name == navFieldName {
-							// Extract the types from Union[...]
-							unionType := navField.Type.GetName()
-							if strings.HasPrefix(unionType, "Union[") && strings.HasSuffix(unionType, "]") {
-								unionContent := unionType[6 : len(unionType)-1]
-								types := strings.Split(unionContent, ", ")
-								for _, t := range types {
-									// Remove quotes
-									t = strings.Trim(t, "'\"")
-									allowedTypes = append(allowedTypes, fmt.Sprintf("\"%s\"", t))
-								}
-							}
-							break
-						}
-					}
-					if len(allowedTypes) > 0 {
-						cb.Line("%s: Literal[%s]", fieldName, strings.Join(allowedTypes, ", "))
-					} else {
-						cb.Line("%s: str", fieldName)
-					}
-				} else if field.IsOptional || (len(fieldName) > 3 && (fieldName[len(fieldName)-3:] == "_id" || strings.HasSuffix(fieldName, "_type"))) {
-					// Optional attribute or foreign key/type fields
-					cb.Line("%s: Optional[%s] = None", fieldName, fieldType)
-				} else {
-					cb.Line("%s: %s", fieldName, fieldType)
-				}
-			} else {
-				cb.Line("%s = None", fieldName)
-			}
+		writeAwareDatetimeValidator(cb, awareDatetimeFields)
+
+		// Abstract bases can only be instantiated through a subclass
+		if morpheConfig.Models.IsAbstract(model.Name) {
+			writeAbstractGuard(cb, model.Name)
 		}
 
-		// Add navigation properties (relationships)
-		for _, field := range model.Fields {
-			if !strings.HasPrefix(field.Name, "_nav_") {
-				continue
-			}
+		// Mutable models are explicitly unhashable
+		if morpheConfig.Models.DisableHash {
+			cb.Line("")
+			cb.Line("__hash__ = None  # type: ignore[assignment]")
+		}
 
-			// Remove _nav_ prefix to get the actual relationship name
-			relName := strings.TrimPrefix(field.Name, "_nav_")
-			fieldName := SanitizePythonIdentifier(formatdef.ToSnakeCase(relName))
-			fieldType := field.Type.GetName()
+		// Add computed count properties for many-relationships
+		for _, count := range counts {
+			cb.Line("")
+			cb.Line("@computed_field")
+			cb.Line("@property")
+			cb.Line("def %s(self) -> int:", count.Property)
+			cb.Indent()
+			cb.Line(`"""Number of related %s."""`, count.Field)
+			cb.Line("return len(self.%s) if self.%s is not None else 0", count.Field, count.Field)
+			cb.Dedent()
+		}
 
-			// Skip if this is a polymorphic relationship with corresponding type/id fields
-			hasPolyFields := false
-			for _, f := range model.Fields {
-				if f.Name == relName+"_type" || f.Name == relName+"_id" {
-					hasPolyFields = true
-					break
-				}
-			}
+		if generateSerializer {
+			cb.Line("")
+			cb.Line("@model_serializer")
+			cb.Line("def serialize_model(self) -> %s:", serializerType.GetName())
+			cb.Indent()
+			cb.Line(`"""Customize whole-model serialization, e.g. to wrap output in an envelope."""`)
+			cb.Line("return dict(self)")
+			cb.Dedent()
+		}
 
-			if hasPolyFields {
-				// For polymorphic relationships, add a property that returns the actual object
-				// This would typically be implemented with a validator or custom getter
-				continue
-			}
+		var configOptions []modelConfigOption
+		if needsModelConfig {
+			configOptions = append(configOptions, validateAssignmentOption, useEnumValuesOption)
+		}
+		if populateByName {
+			configOptions = append(configOptions, populateByNameOption)
+		}
 
-			// For regular relationships, add the navigation property
-			if strings.HasPrefix(fieldType, "List[") {
-				// Many relationship - optional list with default empty list
-				cb.Line("%s: Optional[%s] = None", fieldName, fieldType)
-			} else if strings.Contains(fieldType, "Union[") {
-				// Union type - don't add extra quotes
-				cb.Line("%s: Optional[%s] = None", fieldName, fieldType)
-			} else {
-				// One relationship - optional with forward reference
-				cb.Line("%s: Optional['%s'] = None", fieldName, fieldType)
-			}
+		// Synthesized example instance for the generated JSON schema (OpenAPI docs)
+		var exampleEntries []string
+		if generateSchemaExamples {
+			exampleEntries = modelSchemaExample(model, config, r)
 		}
+		hasModelConfig := len(configOptions) > 0 || len(exampleEntries) > 0
 
-		if config.PydanticV2 && needsModelConfig {
+		if useConfigDict && len(exampleEntries) > 0 {
+			// Add Pydantic v2 model config as a typed ConfigDict, one keyword per line
+			cb.Line("")
+			cb.Line("model_config = ConfigDict(")
+			cb.Indent()
+			for _, option := range configOptions {
+				cb.Line("%s=True,", option.V2Key)
+			}
+			writeSchemaExamples(cb, exampleEntries, "json_schema_extra=", ",")
+			cb.Dedent()
+			cb.Line(")")
+		} else if useConfigDict && hasModelConfig {
+			// Add Pydantic v2 model config as a typed ConfigDict
+			keywords := make([]string, 0, len(configOptions))
+			for _, option := range configOptions {
+				keywords = append(keywords, option.V2Key+"=True")
+			}
+			cb.Line("")
+			cb.Line("model_config = ConfigDict(%s)", strings.Join(keywords, ", "))
+		} else if config.PydanticV2 && hasModelConfig {
 			// Add Pydantic v2 model config only if needed
 			cb.Line("")
 			cb.Line("model_config = {")
 			cb.Indent()
-			cb.Line(`"validate_assignment": True,`)
-			cb.Line(`"use_enum_values": True,`)
+			for _, option := range configOptions {
+				cb.Line(`"%s": True,`, option.V2Key)
+			}
+			if len(exampleEntries) > 0 {
+				writeSchemaExamples(cb, exampleEntries, `"json_schema_extra": `, ",")
+			}
 			cb.Dedent()
 			cb.Line("}")
-		} else if !config.PydanticV2 && needsModelConfig {
+		} else if !config.PydanticV2 && hasModelConfig {
 			// Add Pydantic v1 Config
 			cb.Line("")
 			cb.Line("class Config:")
 			cb.Indent()
-			cb.Line("validate_assignment = True")
-			cb.Line("use_enum_values = True")
+			for _, option := range configOptions {
+				cb.Line("%s = True", option.V1Key)
+			}
+			if len(exampleEntries) > 0 {
+				writeSchemaExamples(cb, exampleEntries, "schema_extra = ", "")
+			}
 			cb.Dedent()
 		}
 	}
 
 	cb.Dedent() // End of class body
 
-	return cb.Build()
+	return renderModelFile(fileTemplate, ModelFileData{
+		Header:  header,
+		Name:    model.Name,
+		Model:   model,
+		Imports: importsCB.String(),
+		Aliases: aliasesCB.String(),
+		Class:   cb.String(),
+	}, config)
+}
+
+// modelConfigOption is a boolean model configuration option, whose key differs between
+// Pydantic v2 (model_config) and v1 (class Config)
+type modelConfigOption struct {
+	V2Key string
+	V1Key string
+}
+
+// Model configuration options enabled by the generator
+var (
+	validateAssignmentOption = modelConfigOption{V2Key: "validate_assignment", V1Key: "validate_assignment"}
+	useEnumValuesOption      = modelConfigOption{V2Key: "use_enum_values", V1Key: "use_enum_values"}
+	populateByNameOption     = modelConfigOption{V2Key: "populate_by_name", V1Key: "allow_population_by_field_name"}
+)
+
+// addBaseClassImport imports the base class a model extends and returns its name
+func addBaseClassImport(imports *ImportTracker, modelName string, modelConfig cfg.ModelConfig, fileNaming string) string {
+	baseClass := modelConfig.BaseClassFor(modelName)
+	if baseClass == cfg.DefaultBaseClass {
+		imports.AddPydantic(baseClass)
+	} else if _, hasModule := modelConfig.BaseClassModules[baseClass]; !hasModule && modelConfig.IsAbstract(baseClass) {
+		// Abstract bases are generated next to the models extending them
+		imports.AddFrom("."+ModuleName(baseClass, fileNaming), baseClass)
+	} else {
+		imports.AddFrom(modelConfig.BaseClassModules[baseClass], baseClass)
+	}
+	return baseClass
+}
+
+// forwardRef renders a forward reference to a model, as a string literal or an explicit ForwardRef
+func forwardRef(modelName string, useForwardRef bool) string {
+	if useForwardRef {
+		return fmt.Sprintf("ForwardRef(%q)", modelName)
+	}
+	return fmt.Sprintf("%q", modelName)
+}
+
+// isModelReference reports whether a navigation element type is a plain related model name, as
+// opposed to a Union of candidates or Any
+func isModelReference(typeName string) bool {
+	return typeName != formatdef.TypeAny.Name && !strings.ContainsAny(typeName, `["(`)
+}
+
+// addAnnotatedImport imports Annotated from typing, or from typing_extensions before Python 3.9
+func addAnnotatedImport(imports *ImportTracker, config PydanticConfig) {
+	if config.PythonVersionAtLeast(3, 9) {
+		imports.AddTyping("Annotated")
+	} else {
+		imports.AddFrom("typing_extensions", "Annotated")
+	}
+}
+
+// collectionFactory returns the builtin factory producing an empty value of a collection type,
+// or an empty string for scalar types
+func collectionFactory(fieldType formatdef.Type) string {
+	switch fieldType.(type) {
+	case formatdef.ArrayType:
+		return "list"
+	case formatdef.DictType:
+		return "dict"
+	default:
+		return ""
+	}
+}
+
+// collectionCount is a computed count property of a many-relationship
+type collectionCount struct {
+	Property string // Name of the count property
+	Field    string // Navigation field whose items are counted
+}
+
+// modelFieldDecl is a single rendered model attribute declaration
+type modelFieldDecl struct {
+	Name       string
+	Annotation string   // Empty when type hints are disabled
+	Metadata   []string // Annotated[...] metadata entries, in order
+	Value      string   // Empty for required fields
+}
+
+// TypeHint renders the annotation, wrapped in Annotated[...] when metadata is attached
+func (decl modelFieldDecl) TypeHint() string {
+	if len(decl.Metadata) == 0 {
+		return decl.Annotation
+	}
+	return fmt.Sprintf("Annotated[%s, %s]", decl.Annotation, strings.Join(decl.Metadata, ", "))
+}
+
+// Required reports whether the declaration has a type hint and no default. Field(...) values
+// render their default (or default_factory) as the first keyword argument.
+func (decl modelFieldDecl) Required() bool {
+	if decl.Annotation == "" {
+		return false
+	}
+	return decl.Value == "" || (strings.HasPrefix(decl.Value, "Field(") && !strings.HasPrefix(decl.Value, "Field(default"))
+}
+
+// String renders the declaration as a class body line
+func (decl modelFieldDecl) String() string {
+	switch {
+	case decl.Annotation == "":
+		return fmt.Sprintf("%s = %s", decl.Name, decl.Value)
+	case decl.Value == "":
+		return fmt.Sprintf("%s: %s", decl.Name, decl.TypeHint())
+	default:
+		return fmt.Sprintf("%s: %s = %s", decl.Name, decl.TypeHint(), decl.Value)
+	}
+}
+
+// distinctCollectionCounts names the counts of many-relationships sharing a related model after
+// their fields instead, so every property stays unique
+func distinctCollectionCounts(counts []collectionCount) []collectionCount {
+	seen := make(map[string]int)
+	for _, count := range counts {
+		seen[count.Property]++
+	}
+	for i, count := range counts {
+		if seen[count.Property] > 1 {
+			counts[i].Property = count.Field + "_count"
+		}
+	}
+	return counts
+}
+
+// modelFieldDecls renders the data fields followed by the navigation properties of a model,
+// along with the computed count properties of its many-relationships
+func modelFieldDecls(model *formatdef.Struct, config PydanticConfig, morpheConfig cfg.MorpheConfig) ([]modelFieldDecl, []collectionCount) {
+	var decls []modelFieldDecl
+	useUnset := config.AddTypeHints && morpheConfig.Models.UseUnsetSentinel
+	generateExamples := config.AddTypeHints && morpheConfig.Models.GenerateExamples
+	useField := morpheConfig.Models.UseField
+	generateCounts := config.PydanticV2 && morpheConfig.Models.GenerateCollectionCounts
+	emptyCollections := morpheConfig.Models.DefaultsPolicy == cfg.DefaultsPolicyEmptyCollections
+	annotatedStyle := config.AddTypeHints && morpheConfig.Models.AnnotatedStyle
+	stringConstraints := config.AddTypeHints && config.PydanticV2 && morpheConfig.Models.ConstraintStyle == cfg.ConstraintStyleAnnotated
+	optionalGeneratedIds := morpheConfig.Models.OptionalGeneratedIds
+	frozenIds := config.PydanticV2 && morpheConfig.Models.ImmutableIds
+	emptyManyRelations := emptyCollections || morpheConfig.Models.DefaultEmptyCollections
+	var defaultKwargs []string
+	if config.PydanticV2 && morpheConfig.Models.ValidateDefaults {
+		defaultKwargs = []string{"validate_default=True"}
+	}
+	var navKwargs []string
+	if morpheConfig.Models.ExcludeLazyRelations {
+		navKwargs = []string{"exclude=True"}
+	}
+
+	// Add fields
+	for _, field := range model.Fields {
+		// Skip navigation properties
+		if strings.HasPrefix(field.Name, "_nav_") {
+			continue
+		}
+
+		fieldName := config.pythonFieldName(field.Name)
+		fieldType := field.Type.GetName()
+
+		// The annotated constraint style moves the pattern into StringConstraints(...) metadata
+		var metadata []string
+		if stringConstraints && field.Pattern != "" {
+			metadata = append(metadata, fmt.Sprintf("StringConstraints(pattern=%s)", rawStringLiteral(field.Pattern)))
+			field.Pattern = ""
+		}
+		kwargs := fieldKwargs(field, config, generateExamples)
+		if useField {
+			kwargs = append(kwargs, visibilityKwargs(field)...)
+		}
+		kwargs = append(kwargs, aliasKwargs(field, fieldName, config, morpheConfig.Models)...)
+		// Individually frozen fields leave the rest of the model mutable
+		if config.PydanticV2 && (field.IsFrozen || frozenIds && field.IsIdentity) {
+			kwargs = append(kwargs, "frozen=True")
+		}
+
+		// Annotated style carries the keyword arguments as Field(...) metadata in the type hint
+		if annotatedStyle && len(kwargs) > 0 {
+			metadata = append(metadata, fmt.Sprintf("Field(%s)", strings.Join(kwargs, ", ")))
+			kwargs = nil
+		}
+
+		if !config.AddTypeHints {
+			decls = append(decls, modelFieldDecl{Name: fieldName, Value: "None"})
+			continue
+		}
+
+		// Check if this is a polymorphic type field
+		if navFieldName := field.PolyNav; navFieldName != "" {
+			// Look for the navigation field to get allowed types
+			var allowedTypes []string
+			for _, navField := range model.Fields {
+				if navField.Name == navFieldName {
+					// Extract the types from Union[...]
+					unionType := navField.Type.GetName()
+					if strings.HasPrefix(unionType, "Union[") && strings.HasSuffix(unionType, "]") {
+						unionContent := unionType[6 : len(unionType)-1]
+						types := strings.Split(unionContent, ", ")
+						for _, t := range types {
+							// Remove quotes
+							t = strings.Trim(t, "'\"")
+							allowedTypes = append(allowedTypes, fmt.Sprintf("\"%s\"", t))
+						}
+					}
+					break
+				}
+			}
+			if len(allowedTypes) > 0 {
+				decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fmt.Sprintf("Optional[Literal[%s]]", strings.Join(allowedTypes, ", ")), Value: fieldValue("None", kwargs)})
+			} else {
+				decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: "Optional[str]", Value: fieldValue("None", kwargs)})
+			}
+		} else if field.IsGenerated && optionalGeneratedIds {
+			// Database-generated ids aren't required on input
+			decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fmt.Sprintf("Optional[%s]", fieldType), Value: fieldValue("None", append(kwargs, defaultKwargs...))})
+		} else if field.IsOptional && useUnset {
+			// Tri-state field distinguishing "not provided" from an explicit None
+			decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: unsetFieldType(fieldType, config), Value: fieldValue("UNSET", kwargs)})
+		} else if field.IsOptional && emptyCollections && collectionFactory(field.Type) != "" {
+			// Optional collections default to an empty container
+			decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fieldType, Value: fieldValue("", append([]string{"default_factory=" + collectionFactory(field.Type)}, kwargs...))})
+		} else {
+			// Optional attributes and foreign keys default to None
+			annotation, defaultValue := nullableAnnotation(field, fieldType)
+			if defaultValue == "" {
+				defaultValue = field.Default
+			}
+			if defaultValue != "" {
+				kwargs = append(kwargs, defaultKwargs...)
+			}
+			decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: annotation, Value: fieldValue(defaultValue, kwargs)})
+		}
+		decls[len(decls)-1].Metadata = metadata
+	}
+
+	// Required fields first; the declared order is kept within each group
+	if config.SortRequiredFirst {
+		sort.SliceStable(decls, func(i, j int) bool {
+			return decls[i].Required() && !decls[j].Required()
+		})
+	}
+
+	// Add navigation properties (relationships)
+	var counts []collectionCount
+	for _, field := range model.Fields {
+		if !strings.HasPrefix(field.Name, "_nav_") {
+			continue
+		}
+
+		// Remove _nav_ prefix to get the actual relationship name
+		relName := strings.TrimPrefix(field.Name, "_nav_")
+		fieldName := config.pythonFieldName(relName)
+		fieldType := field.Type.GetName()
+
+		// Skip if this is a polymorphic relationship with corresponding type/id fields
+		hasPolyFields := false
+		for _, f := range model.Fields {
+			if f.Name == relName+"_type" || f.Name == relName+"_id" {
+				hasPolyFields = true
+				break
+			}
+		}
+
+		if hasPolyFields {
+			// For polymorphic relationships, add a property that returns the actual object
+			// This would typically be implemented with a validator or custom getter
+			continue
+		}
+
+		// For regular relationships, add the navigation property
+		if arrayType, isMany := field.Type.(formatdef.ArrayType); isMany {
+			// Counts are named after the related model, e.g. order_count
+			countedName := relName
+			// Related models are only imported under TYPE_CHECKING, and self references are not yet
+			// defined in the class body, so both need a forward reference
+			if elementType, isBasic := arrayType.ElementType.(formatdef.BasicType); isBasic && isModelReference(elementType.Name) {
+				countedName = elementType.Name
+				arrayType.ElementType = formatdef.BasicType{Name: forwardRef(elementType.Name, morpheConfig.Models.UseForwardRef)}
+				fieldType = arrayType.GetName()
+			}
+			// Many relationship - optional list, or an empty list under the empty-collections policy
+			if emptyManyRelations {
+				decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fieldType, Value: fieldValue("", append([]string{"default_factory=list"}, navKwargs...))})
+			} else {
+				decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fmt.Sprintf("Optional[%s]", fieldType), Value: fieldValue("None", navKwargs)})
+			}
+			if generateCounts {
+				counts = append(counts, collectionCount{Property: formatdef.ToSnakeCase(countedName) + "_count", Field: fieldName})
+			}
+		} else if !isModelReference(fieldType) {
+			// Union type or Any fallback - don't add extra quotes
+			decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fmt.Sprintf("Optional[%s]", fieldType), Value: fieldValue("None", navKwargs)})
+		} else {
+			// One relationship - optional with forward reference
+			decls = append(decls, modelFieldDecl{Name: fieldName, Annotation: fmt.Sprintf("Optional[%s]", forwardRef(fieldType, morpheConfig.Models.UseForwardRef)), Value: fieldValue("None", navKwargs)})
+		}
+	}
+
+	return decls, distinctCollectionCounts(counts)
 }
@@ -0,0 +1,2382 @@
+package compile_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
+)
+
+// newCustomerOrderRegistry builds a registry where a Customer has many Orders
+func newCustomerOrderRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Customer", yaml.Model{
+		Name: "Customer",
+		Fields: map[string]yaml.ModelField{
+			"ID":   {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Name": {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Orders": {Type: "HasMany", Aliased: "Order"},
+		},
+	})
+	r.SetModel("Order", yaml.Model{
+		Name: "Order",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Total": {Type: yaml.ModelFieldTypeFloat},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Customer": {Type: "ForOne"},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CollectionCounts() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateCollectionCounts = true
+	config.MorpheConfig.Models.GenerateStubs = true
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+	stub := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.pyi")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, computed_field")
+	suite.Contains(content, "    orders: Optional[List[\"Order\"]] = None")
+	suite.Contains(stub, "    def order_count(self) -> int: ...\n")
+	suite.Contains(content, `    @computed_field
+    @property
+    def order_count(self) -> int:
+        """Number of related orders."""
+        return len(self.orders) if self.orders is not None else 0`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CollectionCountsSharedRelatedModel() {
+	r := newCustomerOrderRegistry()
+	customer, _ := r.GetModel("Customer")
+	customer.Related["Addresses"] = yaml.ModelRelation{Type: "HasMany", Aliased: "Order"}
+	r.SetModel("Customer", customer)
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateCollectionCounts = true
+	config.MorpheConfig.Models.GenerateStubs = true
+
+	content := suite.generateSource(config, r, "models/customer.py")
+	stub := suite.generateSource(config, r, "models/customer.pyi")
+
+	suite.Contains(content, "    def addresses_count(self) -> int:\n")
+	suite.Contains(stub, "    def addresses_count(self) -> int: ...\n")
+	suite.Contains(stub, "    def orders_count(self) -> int: ...\n")
+	suite.NotContains(content, "order_count")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CollectionCountsDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+
+	suite.NotContains(content, "computed_field")
+	suite.NotContains(content, "order_count")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CollectionCountsIgnoresSingleRelations() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateCollectionCounts = true
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/order.py")
+
+	suite.NotContains(content, "computed_field")
+	suite.NotContains(content, "_count")
+}
+
+// newProfileRegistry builds a registry with a single model mixing required and optional fields
+func newProfileRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Profile", yaml.Model{
+		Name: "Profile",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Nickname": {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional"}},
+			"Username": {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnsetSentinel() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.11"
+	config.MorpheConfig.Models.UseUnsetSentinel = true
+	r := newProfileRegistry()
+
+	content := suite.generateSource(config, r, "models/profile.py")
+
+	suite.Contains(content, "from .._sentinels import UNSET, Unset\n")
+	suite.Contains(content, "    nickname: str | None | Unset = Field(default=UNSET, alias=\"Nickname\")\n")
+	suite.Contains(content, "    username: str = Field(alias=\"Username\")\n")
+
+	sentinelContent := suite.generateSource(config, r, "_sentinels.py")
+	suite.Contains(sentinelContent, "class Unset:")
+	suite.Contains(sentinelContent, "def __get_pydantic_core_schema__(cls, source: Any, handler: Any) -> Any:")
+	suite.Contains(sentinelContent, "UNSET = Unset()\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnsetSentinelLegacyPython() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.UseUnsetSentinel = true
+
+	content := suite.generateSource(config, newProfileRegistry(), "models/profile.py")
+
+	suite.Contains(content, "from typing import Optional, Union\n")
+	suite.Contains(content, "    nickname: Union[str, None, Unset] = Field(default=UNSET, alias=\"Nickname\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnsetSentinelDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newProfileRegistry(), "models/profile.py")
+
+	suite.NotContains(content, "Unset")
+	suite.Contains(content, "    nickname: Optional[str] = Field(default=None, alias=\"Nickname\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_TypeCheckingOnlyWithModelImports() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newProfileRegistry(), "models/profile.py")
+
+	suite.NotContains(content, "TYPE_CHECKING")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_TypeCheckingWithModelImports() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+
+	suite.Contains(content, "from typing import TYPE_CHECKING, List, Optional\n")
+	suite.Contains(content, "if TYPE_CHECKING:\n    from .order import Order\n")
+}
+
+// newSampleDataRegistry builds a registry whose fields carry example:<value> sample data
+func newSampleDataRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Product", yaml.Model{
+		Name: "Product",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Name":   {Type: yaml.ModelFieldTypeString, Attributes: []string{"example:Desk", "example:Chair"}},
+			"Price":  {Type: yaml.ModelFieldTypeFloat, Attributes: []string{"optional", "example:19.99"}},
+			"Active": {Type: yaml.ModelFieldTypeBoolean, Attributes: []string{"example:true"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldExamples() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateExamples = true
+
+	content := suite.generateSource(config, newSampleDataRegistry(), "models/product.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field")
+	suite.Contains(content, "    active: bool = Field(examples=[True], alias=\"Active\")\n")
+	suite.Contains(content, "    id_: int = Field(alias=\"ID\")\n")
+	suite.Contains(content, `    name: str = Field(examples=["Desk", "Chair"], alias="Name")`+"\n")
+	suite.Contains(content, "    price: Optional[float] = Field(default=None, examples=[19.99], alias=\"Price\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldExamplesDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newSampleDataRegistry(), "models/product.py")
+
+	suite.NotContains(content, "examples=")
+	suite.Contains(content, "    name: str = Field(alias=\"Name\")\n")
+	suite.Contains(content, "    price: Optional[float] = Field(default=None, alias=\"Price\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_BuiltinGenerics() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.9"
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+
+	suite.Contains(content, "from typing import TYPE_CHECKING, Optional\n")
+	suite.Contains(content, "    orders: Optional[list[\"Order\"]] = None\n")
+	suite.NotContains(content, "List")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_TypingGenericsBeforePython39() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.8"
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+
+	suite.Contains(content, "from typing import TYPE_CHECKING, List, Optional\n")
+	suite.Contains(content, "    orders: Optional[List[\"Order\"]] = None\n")
+}
+
+// newLibraryRegistry builds a registry where a Review references a Book which references an
+// Author, alongside an unrelated Publisher
+func newLibraryRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	primary := map[string]yaml.ModelIdentifier{"primary": {Fields: []string{"ID"}}}
+	r.SetModel("Author", yaml.Model{
+		Name:        "Author",
+		Fields:      map[string]yaml.ModelField{"ID": {Type: yaml.ModelFieldTypeAutoIncrement}},
+		Identifiers: primary,
+	})
+	r.SetModel("Book", yaml.Model{
+		Name:        "Book",
+		Fields:      map[string]yaml.ModelField{"ID": {Type: yaml.ModelFieldTypeAutoIncrement}},
+		Identifiers: primary,
+		Related:     map[string]yaml.ModelRelation{"Author": {Type: "ForOne"}},
+	})
+	r.SetModel("Review", yaml.Model{
+		Name:        "Review",
+		Fields:      map[string]yaml.ModelField{"ID": {Type: yaml.ModelFieldTypeAutoIncrement}},
+		Identifiers: primary,
+		Related:     map[string]yaml.ModelRelation{"Book": {Type: "ForOne"}},
+	})
+	r.SetModel("Publisher", yaml.Model{
+		Name:        "Publisher",
+		Fields:      map[string]yaml.ModelField{"ID": {Type: yaml.ModelFieldTypeAutoIncrement}},
+		Identifiers: primary,
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_OnlyModelsWithDependents() {
+	outputDirPath := suite.T().TempDir()
+	config := compile.DefaultMorpheCompileConfig("", outputDirPath)
+	config.MorpheConfig.Models.OnlyModels = []string{"Book"}
+
+	writer := compile.NewMorpheWriter(outputDirPath)
+	suite.Require().NoError(compile.CompileAllModels(config, newLibraryRegistry(), writer))
+
+	suite.FileExists(filepath.Join(outputDirPath, "models", "book.py"))
+	suite.FileExists(filepath.Join(outputDirPath, "models", "review.py"))
+	suite.NoFileExists(filepath.Join(outputDirPath, "models", "author.py"))
+	suite.NoFileExists(filepath.Join(outputDirPath, "models", "publisher.py"))
+
+	index, readErr := os.ReadFile(filepath.Join(outputDirPath, "models", "__init__.py"))
+	suite.Require().NoError(readErr)
+	suite.Equal(`# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from .author import Author
+from .book import Book
+from .publisher import Publisher
+from .review import Review
+
+
+Book.model_rebuild()
+Review.model_rebuild()
+`, string(index))
+}
+
+func (suite *CompileTestSuite) TestValidate_OnlyModelsWithSingleFile() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.MorpheConfig.Models.OnlyModels = []string{"Book"}
+	config.FormatConfig.SingleFile = true
+
+	suite.EqualError(config.Validate(), "invalid models.onlyModels: not supported with singleFile")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_OnlyModelsUnknownModel() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.OnlyModels = []string{"Magazine"}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(config, newLibraryRegistry(), writer)
+
+	var modelErr *compile.ModelNotFoundError
+	suite.Require().True(errors.As(err, &modelErr))
+	suite.Equal("Magazine", modelErr.Model)
+	suite.ErrorContains(err, "model not found: Magazine")
+}
+
+// newPatternRegistry builds a registry whose string fields declare pattern:<regex> validations
+func newPatternRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Contact", yaml.Model{
+		Name: "Contact",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeAutoIncrement, Attributes: []string{`pattern:^\d+$`}},
+			"Phone": {Type: yaml.ModelFieldTypeString, Attributes: []string{`pattern:^\d{3}-\d{4}$`}},
+			"Quote": {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional", `pattern:^"[^"]*"$`}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldPatternPydanticV2() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    id_: int = Field(alias=\"ID\")\n")
+	suite.Contains(content, `    phone: str = Field(pattern=r"^\d{3}-\d{4}$", alias="Phone")`+"\n")
+	suite.Contains(content, `    quote: Optional[str] = Field(default=None, pattern=r"^\"[^\"]*\"$", alias="Quote")`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldPatternPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, `    phone: str = Field(regex=r"^\d{3}-\d{4}$", alias="Phone")`+"\n")
+	suite.NotContains(content, "pattern=")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AnnotatedConstraintStyle() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.9"
+	config.MorpheConfig.Models.ConstraintStyle = cfg.ConstraintStyleAnnotated
+	config.FormatConfig.DisableFieldKeyAliases = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, "from typing import Annotated, Optional\n")
+	suite.Contains(content, "from pydantic import BaseModel, StringConstraints\n")
+	suite.Contains(content, `    phone: Annotated[str, StringConstraints(pattern=r"^\d{3}-\d{4}$")]`+"\n")
+	suite.Contains(content, `    quote: Annotated[Optional[str], StringConstraints(pattern=r"^\"[^\"]*\"$")] = None`+"\n")
+	suite.NotContains(content, "Field(")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AnnotatedConstraintStyleWithAnnotatedFields() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.9"
+	config.MorpheConfig.Models.ConstraintStyle = cfg.ConstraintStyleAnnotated
+	config.MorpheConfig.Models.AnnotatedStyle = true
+	config.FormatConfig.MaxLineLength = 0
+
+	content := suite.generateSource(config, newDescribedRegistry(), "models/account.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, StringConstraints\n")
+	suite.Contains(content, `    handle: Annotated[str, StringConstraints(pattern=r"^[a-z]+$"), Field(description="Public handle", alias="Handle")]`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AnnotatedConstraintStylePydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.ConstraintStyle = cfg.ConstraintStyleAnnotated
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, `    phone: str = Field(regex=r"^\d{3}-\d{4}$", alias="Phone")`+"\n")
+	suite.NotContains(content, "StringConstraints")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_InvalidConstraintStyle() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ConstraintStyle = "kwargs"
+
+	suite.EqualError(config.Validate(), "invalid models.constraintStyle: kwargs (must be 'field' or 'annotated')")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DateOnlyImport() {
+	r := registry.NewRegistry()
+	r.SetModel("Holiday", yaml.Model{
+		Name: "Holiday",
+		Fields: map[string]yaml.ModelField{
+			"ID":  {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Day": {Type: yaml.ModelFieldTypeDate},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, r, "models/holiday.py")
+
+	suite.Contains(content, "from datetime import date\n")
+	suite.Contains(content, "    day: date = Field(alias=\"Day\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_StubInitSignature() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateStubs = true
+
+	content := suite.generateSource(config, newProfileRegistry(), "models/profile.pyi")
+
+	suite.Equal(`# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from typing import Optional
+
+from pydantic import BaseModel
+
+
+class Profile(BaseModel):
+    id_: int
+    nickname: Optional[str]
+    username: str
+
+    def __init__(
+        self, *, id_: int, nickname: Optional[str] = ..., username: str
+    ) -> None: ...
+`, content)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_StubsDisabled() {
+	outputDirPath := suite.T().TempDir()
+	config := compile.DefaultMorpheCompileConfig("", outputDirPath)
+
+	writer := compile.NewMorpheWriter(outputDirPath)
+	suite.Require().NoError(compile.CompileAllModels(config, newProfileRegistry(), writer))
+
+	suite.FileExists(filepath.Join(outputDirPath, "models", "profile.py"))
+	suite.NoFileExists(filepath.Join(outputDirPath, "models", "profile.pyi"))
+}
+
+// newTeamRegistry builds a registry where a Team has an optional scalar and a list of Players
+func newTeamRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Team", yaml.Model{
+		Name: "Team",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Motto": {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Players": {Type: "HasMany", Aliased: "Player"},
+		},
+	})
+	r.SetModel("Player", yaml.Model{
+		Name: "Player",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DefaultsPolicyNoneEverywhere() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.DefaultsPolicy = cfg.DefaultsPolicyNoneEverywhere
+
+	content := suite.generateSource(config, newTeamRegistry(), "models/team.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    motto: Optional[str] = Field(default=None, alias=\"Motto\")\n")
+	suite.Contains(content, "    players: Optional[List[\"Player\"]] = None\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DefaultsPolicyEmptyCollections() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.DefaultsPolicy = cfg.DefaultsPolicyEmptyCollections
+
+	content := suite.generateSource(config, newTeamRegistry(), "models/team.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    motto: Optional[str] = Field(default=None, alias=\"Motto\")\n")
+	suite.Contains(content, "    players: List[\"Player\"] = Field(default_factory=list)\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DefaultEmptyCollections() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.DefaultEmptyCollections = true
+
+	content := suite.generateSource(config, newTeamRegistry(), "models/team.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    motto: Optional[str] = Field(default=None, alias=\"Motto\")\n")
+	suite.Contains(content, "    players: List[\"Player\"] = Field(default_factory=list)\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ExcludeLazyRelations() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ExcludeLazyRelations = true
+
+	customer := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+	suite.Contains(customer, "from pydantic import BaseModel, Field\n")
+	suite.Contains(customer, "    name: str = Field(alias=\"Name\")\n")
+	suite.Contains(customer, "    orders: Optional[List[\"Order\"]] = Field(default=None, exclude=True)\n")
+
+	order := suite.generateSource(config, newCustomerOrderRegistry(), "models/order.py")
+	suite.Contains(order, "    customer_id: Optional[str] = None\n")
+	suite.Contains(order, "    customer: Optional[\"Customer\"] = Field(default=None, exclude=True)\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ExcludeLazyRelationsEmptyCollections() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ExcludeLazyRelations = true
+	config.MorpheConfig.Models.DefaultEmptyCollections = true
+
+	content := suite.generateSource(config, newTeamRegistry(), "models/team.py")
+
+	suite.Contains(content, "    players: List[\"Player\"] = Field(default_factory=list, exclude=True)\n")
+}
+
+func (suite *CompileTestSuite) TestValidate_DefaultsPolicy() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.MorpheConfig.Models.DefaultsPolicy = "zero-values"
+
+	err := config.Validate()
+
+	suite.EqualError(err, "invalid models.defaultsPolicy: zero-values (must be 'none-everywhere' or 'empty-collections')")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ModelSerializer() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateModelSerializer = true
+
+	content := suite.generateSource(config, newProfileRegistry(), "models/profile.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, model_serializer\n")
+	suite.Contains(content, "from typing import Any, Dict, Optional\n")
+	suite.Contains(content, `
+    @model_serializer
+    def serialize_model(self) -> Dict[str, Any]:
+        """Customize whole-model serialization, e.g. to wrap output in an envelope."""
+        return dict(self)
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ModelSerializerPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.GenerateModelSerializer = true
+
+	content := suite.generateSource(config, newProfileRegistry(), "models/profile.py")
+
+	suite.NotContains(content, "model_serializer")
+}
+
+// newDescribedRegistry builds a registry whose fields combine pattern constraints and descriptions
+func newDescribedRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Account", yaml.Model{
+		Name: "Account",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Handle": {Type: yaml.ModelFieldTypeString, Attributes: []string{`pattern:^[a-z]+$`, "description:Public handle"}},
+			"Bio":    {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional", "description:Short bio"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AnnotatedStyle() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.9"
+	config.MorpheConfig.Models.AnnotatedStyle = true
+
+	content := suite.generateSource(config, newDescribedRegistry(), "models/account.py")
+
+	suite.Contains(content, "from typing import Annotated, Optional\n")
+	suite.Contains(content, `    handle: Annotated[`+"\n")
+	suite.Contains(content, `    bio: Annotated[Optional[str], Field(description="Short bio", alias="Bio")] = None`+"\n")
+	suite.Contains(content, "    id_: Annotated[int, Field(alias=\"ID\")]\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AnnotatedStylePython38() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.AnnotatedStyle = true
+
+	content := suite.generateSource(config, newDescribedRegistry(), "models/account.py")
+
+	suite.Contains(content, "from typing_extensions import Annotated\n")
+	suite.NotContains(content, "from typing import Annotated")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SortRequiredFirst() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.SortRequiredFirst = true
+
+	content := suite.generateSource(config, newDescribedRegistry(), "models/account.py")
+
+	suite.Contains(content, `    handle: str = Field(
+        pattern=r"^[a-z]+$", description="Public handle", alias="Handle"
+    )
+    id_: int = Field(alias="ID")
+    bio: Optional[str] = Field(default=None, description="Short bio", alias="Bio")
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldDescription() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newDescribedRegistry(), "models/account.py")
+
+	suite.Contains(content, `    handle: str = Field(
+        pattern=r"^[a-z]+$", description="Public handle", alias="Handle"
+    )
+`)
+	suite.Contains(content, `    bio: Optional[str] = Field(default=None, description="Short bio", alias="Bio")`+"\n")
+}
+
+// newEmployeeRegistry builds a registry with a self-referential Employee model
+func newEmployeeRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Employee", yaml.Model{
+		Name: "Employee",
+		Fields: map[string]yaml.ModelField{
+			"ID":   {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Name": {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Manager": {Type: "ForOne", Aliased: "Employee"},
+			"Reports": {Type: "HasMany", Aliased: "Employee"},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SelfReference() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newEmployeeRegistry(), "models/employee.py")
+
+	suite.Contains(content, "    manager: Optional[\"Employee\"] = None\n")
+	suite.Contains(content, "    reports: Optional[List[\"Employee\"]] = None\n")
+	suite.NotContains(content, "from .employee import Employee")
+	suite.NotContains(content, "TYPE_CHECKING")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UseForwardRef() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.UseForwardRef = true
+
+	content := suite.generateSource(config, newEmployeeRegistry(), "models/employee.py")
+
+	suite.Contains(content, "from typing import ForwardRef, List, Optional\n")
+	suite.Contains(content, "    manager: Optional[ForwardRef(\"Employee\")] = None\n")
+	suite.Contains(content, "    reports: Optional[List[ForwardRef(\"Employee\")]] = None\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FileTemplate() {
+	templatePath := filepath.Join(suite.T().TempDir(), "model.tmpl")
+	suite.Require().NoError(os.WriteFile(templatePath, []byte(`"""{{.Name}} module ({{len .Model.Fields}} fields)."""
+{{.Imports}}
+{{.Class}}
+__all__ = ["{{.Name}}"]
+`), 0644))
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FileTemplatePath = templatePath
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.True(strings.HasPrefix(content, `"""Contact module (3 fields)."""
+from typing import Optional
+
+from pydantic import BaseModel, Field
+`), content)
+	suite.True(strings.HasSuffix(content, "\n\n\n__all__ = [\"Contact\"]\n"), content)
+	suite.NotContains(content, "Auto-generated")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FileTemplateHeader() {
+	templatePath := filepath.Join(suite.T().TempDir(), "model.tmpl")
+	suite.Require().NoError(os.WriteFile(templatePath, []byte(`"""{{.Name}} module."""
+{{.Header}}{{.Imports}}
+{{.Class}}`), 0644))
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FileTemplatePath = templatePath
+	config.FormatConfig.FileHeader = "Generated for the contacts service."
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.True(strings.HasPrefix(content, `"""Contact module."""
+# Generated for the contacts service.
+
+from typing import Optional
+`), content)
+}
+
+func (suite *CompileTestSuite) TestValidate_FileTemplateParseError() {
+	templatePath := filepath.Join(suite.T().TempDir(), "model.tmpl")
+	suite.Require().NoError(os.WriteFile(templatePath, []byte("{{.Imports"), 0644))
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.FormatConfig.FileTemplatePath = templatePath
+
+	err := config.Validate()
+
+	var configErr *compile.ConfigValidationError
+	suite.Require().True(errors.As(err, &configErr))
+	suite.Equal("fileTemplatePath", configErr.Option)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_BaseClasses() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.BaseClasses = map[string]string{"*": "AuditedModel", "Author": "BaseModel"}
+	config.MorpheConfig.Models.BaseClassModules = map[string]string{"AuditedModel": "myapp.audit"}
+
+	bookContent := suite.generateSource(config, newLibraryRegistry(), "models/book.py")
+	authorContent := suite.generateSource(config, newLibraryRegistry(), "models/author.py")
+
+	suite.Contains(bookContent, "from myapp.audit import AuditedModel\n")
+	suite.Contains(bookContent, "class Book(AuditedModel):\n")
+	suite.NotContains(bookContent, "import BaseModel")
+	suite.Contains(authorContent, "from pydantic import BaseModel, Field\n")
+	suite.Contains(authorContent, "class Author(BaseModel):\n")
+}
+
+// newAnimalRegistry builds a registry with an Animal model meant as the base of Dog
+func newAnimalRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Animal", yaml.Model{
+		Name: "Animal",
+		Fields: map[string]yaml.ModelField{
+			"ID":   {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Name": {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetModel("Dog", yaml.Model{
+		Name: "Dog",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Name":  {Type: yaml.ModelFieldTypeString},
+			"Breed": {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AbstractModels() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.AbstractModels = []string{"Animal"}
+	config.MorpheConfig.Models.BaseClasses = map[string]string{"Dog": "Animal"}
+	suite.Require().NoError(config.MorpheConfig.Validate())
+
+	animalContent := suite.generateSource(config, newAnimalRegistry(), "models/animal.py")
+	dogContent := suite.generateSource(config, newAnimalRegistry(), "models/dog.py")
+
+	suite.Contains(animalContent, "from typing import Any, Optional\n")
+	suite.Contains(animalContent, `class Animal(BaseModel):
+    """Animal model."""
+    id_: int = Field(alias="ID")
+    name: str = Field(alias="Name")
+
+    def __init__(self, **data: Any) -> None:
+        if type(self) is Animal:
+            raise TypeError("Animal is abstract; instantiate one of its subclasses")
+        super().__init__(**data)
+`)
+	suite.Contains(dogContent, "from .animal import Animal\n")
+	suite.Contains(dogContent, `class Dog(Animal):
+    """Dog model."""
+    breed: str = Field(alias="Breed")
+`)
+	suite.NotContains(dogContent, "import BaseModel")
+	suite.NotContains(dogContent, "__init__")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AbstractModelsStubKeepInheritedFields() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.AbstractModels = []string{"Animal"}
+	config.MorpheConfig.Models.BaseClasses = map[string]string{"Dog": "Animal"}
+	config.MorpheConfig.Models.GenerateStubs = true
+
+	content := suite.generateSource(config, newAnimalRegistry(), "models/dog.pyi")
+
+	suite.Contains(content, "class Dog(Animal):\n")
+	suite.Contains(content, "name: str")
+	suite.Contains(content, "breed: str")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AbstractModelNotFound() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.AbstractModels = []string{"Plant"}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(config, newAnimalRegistry(), writer)
+
+	suite.ErrorContains(err, "model not found: Plant")
+}
+
+func (suite *CompileTestSuite) TestValidate_BaseClassWithoutModule() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.MorpheConfig.Models.BaseClasses = map[string]string{"Person": "AuditedModel"}
+
+	err := config.Validate()
+
+	suite.EqualError(err, "invalid models.baseClasses: Person base AuditedModel has no module in models.baseClassModules")
+}
+
+func (suite *CompileTestSuite) TestValidate_BaseClassesReportedInOrder() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.MorpheConfig.Models.BaseClasses = map[string]string{
+		"Person":  "AuditedModel",
+		"Company": "TenantModel",
+		"Contact": "SyncedModel",
+	}
+
+	for i := 0; i < 10; i++ {
+		suite.EqualError(config.Validate(), "invalid models.baseClasses: Company base TenantModel has no module in models.baseClassModules")
+	}
+}
+
+func (suite *CompileTestSuite) TestValidate_AliasCasesReportedInOrder() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ValidationAlias = "kebab"
+	config.MorpheConfig.Models.SerializationAlias = "upper"
+
+	for i := 0; i < 10; i++ {
+		suite.EqualError(config.Validate(), "invalid models.validationAlias: kebab (must be 'camelCase', 'PascalCase' or 'snake_case')")
+	}
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CompositeKeyForeignKeys() {
+	r := registry.NewRegistry()
+	r.SetModel("Order", yaml.Model{
+		Name: "Order",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"TenantID": {Type: yaml.ModelFieldTypeUUID},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"TenantID", "ID"}},
+		},
+	})
+	r.SetModel("Shipment", yaml.Model{
+		Name: "Shipment",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Order": {Type: "ForOne"},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, r, "models/shipment.py")
+
+	suite.Contains(content, `    id_: int = Field(alias="ID")
+    order_tenant_id: Optional[str] = None
+    order_id: Optional[str] = None
+    order: Optional["Order"] = None
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PopulateByNamePydanticV2() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.PopulateByName = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, `
+    model_config = {
+        "populate_by_name": True,
+    }
+`)
+	suite.NotContains(content, "allow_population_by_field_name")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PopulateByNamePydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.PopulateByName = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, `
+    class Config:
+        allow_population_by_field_name = True
+`)
+	suite.NotContains(content, "populate_by_name")
+}
+
+// newMemberRegistry builds a registry whose Member model has an AccountStatus enum field
+func newMemberRegistry() *registry.Registry {
+	r := newStatusRegistry()
+	r.SetModel("Member", yaml.Model{
+		Name: "Member",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Status": {Type: yaml.ModelFieldType("AccountStatus")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UseConfigDict() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.UseConfigDict = true
+
+	content := suite.generateSource(config, newMemberRegistry(), "models/member.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, ConfigDict, Field\n")
+	suite.Contains(content, `
+    model_config = ConfigDict(
+        validate_assignment=True, use_enum_values=True, populate_by_name=True
+    )
+`)
+	suite.NotContains(content, "model_config = {")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ConfigDictLiteralByDefault() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newMemberRegistry(), "models/member.py")
+
+	suite.Contains(content, `
+    model_config = {
+        "validate_assignment": True,
+        "use_enum_values": True,
+        "populate_by_name": True,
+    }
+`)
+	suite.NotContains(content, "ConfigDict")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UseConfigDictPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.UseConfigDict = true
+
+	content := suite.generateSource(config, newMemberRegistry(), "models/member.py")
+
+	suite.Contains(content, "    class Config:\n        validate_assignment = True\n")
+	suite.NotContains(content, "ConfigDict")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SchemaExamples() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateSchemaExamples = true
+
+	content := suite.generateSource(config, newMemberRegistry(), "models/member.py")
+
+	suite.Contains(content, `
+    model_config = {
+        "validate_assignment": True,
+        "use_enum_values": True,
+        "populate_by_name": True,
+        "json_schema_extra": {
+            "examples": [
+                {
+                    "ID": 1,
+                    "Status": "active",
+                },
+            ],
+        },
+    }
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SchemaExamplesRelationsAreNone() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateSchemaExamples = true
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/order.py")
+
+	suite.Contains(content, `
+    model_config = {
+        "populate_by_name": True,
+        "json_schema_extra": {
+            "examples": [
+                {
+                    "ID": 1,
+                    "Total": 1.0,
+                    "customer_id": "string",
+                    "customer": None,
+                },
+            ],
+        },
+    }
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SchemaExamplesConfigDict() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.GenerateSchemaExamples = true
+	config.MorpheConfig.Models.UseConfigDict = true
+
+	content := suite.generateSource(config, newMemberRegistry(), "models/member.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, ConfigDict, Field\n")
+	suite.Contains(content, `
+    model_config = ConfigDict(
+        validate_assignment=True,
+        use_enum_values=True,
+        populate_by_name=True,
+        json_schema_extra={
+            "examples": [
+                {
+                    "ID": 1,
+                    "Status": "active",
+                },
+            ],
+        },
+    )
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SchemaExamplesPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.GenerateSchemaExamples = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    class Config:\n        allow_population_by_field_name = True\n        schema_extra = {\n            \"examples\": [\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CustomTypeMappings() {
+	r := registry.NewRegistry()
+	r.SetModel("Invoice", yaml.Model{
+		Name: "Invoice",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Total":    {Type: yaml.ModelFieldType("Money")},
+			"Location": {Type: yaml.ModelFieldType("GeoPoint")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetStructure("LineItem", yaml.Structure{
+		Name: "LineItem",
+		Fields: map[string]yaml.StructureField{
+			"Price": {Type: yaml.StructureFieldType("Money")},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"Money":    {Type: "Money", Import: "from myapp.money import Money"},
+		"GeoPoint": {Type: "GeoPoint", Import: "from myapp.geo import GeoPoint"},
+	}
+
+	model := suite.generateSource(config, r, "models/invoice.py")
+	suite.Contains(model, "from myapp.geo import GeoPoint\nfrom myapp.money import Money\n")
+	suite.Contains(model, "    location: GeoPoint = Field(alias=\"Location\")\n")
+	suite.Contains(model, "    total: Money = Field(alias=\"Total\")\n")
+
+	structure := suite.generateSource(config, r, "structures/line_item.py")
+	suite.Contains(structure, "from myapp.money import Money\n")
+	suite.Contains(structure, "    price: Money = Field(alias=\"Price\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CustomTypeMappingWithoutType() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"Money": {Import: "from myapp.money import Money"},
+	}
+
+	suite.EqualError(config.Validate(), "invalid customTypeMappings: Money has no Python type")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_StrictTypesUnmapped() {
+	r := newStatusRegistry()
+	r.SetModel("Invoice", yaml.Model{
+		Name: "Invoice",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Status": {Type: yaml.ModelFieldType("AccountStatus")},
+			"Total":  {Type: yaml.ModelFieldType("Money")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.StrictTypes = true
+
+	err := compile.CompileAllModels(config, r, compile.NewMorpheWriter(suite.T().TempDir()))
+
+	var unmappedErr *compile.UnmappedFieldTypeError
+	suite.Require().True(errors.As(err, &unmappedErr))
+	suite.Equal("Invoice", unmappedErr.Owner)
+	suite.Equal("Total", unmappedErr.Field)
+	suite.Equal("Money", unmappedErr.Type)
+	suite.EqualError(err, "failed to compile model Invoice: unmapped field type Money for Invoice.Total")
+
+	var typeMapErr *compile.TypeMapError
+	suite.False(errors.As(err, &typeMapErr))
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_StrictTypesUnmapped() {
+	r := registry.NewRegistry()
+	r.SetStructure("LineItem", yaml.Structure{
+		Name: "LineItem",
+		Fields: map[string]yaml.StructureField{
+			"Price": {Type: yaml.StructureFieldType("Money")},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.StrictTypes = true
+
+	err := compile.CompileAllStructures(config, r, compile.NewMorpheWriter(suite.T().TempDir()))
+
+	suite.EqualError(err, "failed to compile structure LineItem: unmapped field type Money for LineItem.Price")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_StrictTypesCustomMapping() {
+	r := newStatusRegistry()
+	r.SetModel("Invoice", yaml.Model{
+		Name: "Invoice",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Status": {Type: yaml.ModelFieldType("AccountStatus")},
+			"Total":  {Type: yaml.ModelFieldType("Money")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.StrictTypes = true
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"Money": {Type: "Money", Import: "from myapp.money import Money"},
+	}
+
+	content := suite.generateSource(config, r, "models/invoice.py")
+
+	suite.Contains(content, "    status: AccountStatus = Field(alias=\"Status\")\n")
+	suite.Contains(content, "    total: Money = Field(alias=\"Total\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_LenientTypesByDefault() {
+	r := registry.NewRegistry()
+	r.SetModel("Invoice", yaml.Model{
+		Name: "Invoice",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Total": {Type: yaml.ModelFieldType("Money")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/invoice.py")
+
+	suite.Contains(content, "    total: Money = Field(alias=\"Total\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_TypeResolutionOrder() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"String": {Type: "EmailStr", Import: "from pydantic import EmailStr"},
+	}
+
+	overridden := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+	suite.Contains(overridden, "    name: EmailStr = Field(alias=\"Name\")\n")
+
+	config.FormatConfig.TypeResolutionOrder = []string{"builtin", "custom"}
+	builtin := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+	suite.Contains(builtin, "    name: str = Field(alias=\"Name\")\n")
+	suite.NotContains(builtin, "EmailStr")
+}
+
+func (suite *CompileTestSuite) TestValidate_TypeResolutionOrder() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.FormatConfig.TypeResolutionOrder = []string{"builtin", "builtin"}
+
+	suite.EqualError(config.Validate(), "invalid typeResolutionOrder: builtin (must list 'custom', 'registered' or 'builtin' at most once each)")
+}
+
+// newLedgerRegistry builds a registry whose Entry model has a sequence-backed id
+func newLedgerRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Entry", yaml.Model{
+		Name: "Entry",
+		Fields: map[string]yaml.ModelField{
+			"Number": {Type: yaml.ModelFieldTypeInteger, Attributes: []string{"sequence"}},
+			"Memo":   {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"Number"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_OptionalGeneratedIds() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.OptionalGeneratedIds = true
+
+	content := suite.generateSource(config, newLedgerRegistry(), "models/entry.py")
+
+	suite.Contains(content, "    number: Optional[int] = Field(default=None, alias=\"Number\")\n")
+	suite.Contains(content, "    memo: str = Field(alias=\"Memo\")\n")
+
+	autoIncrement := suite.generateSource(config, newDescribedRegistry(), "models/account.py")
+	suite.Contains(autoIncrement, "    id_: Optional[int] = Field(default=None, alias=\"ID\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_GeneratedIdsRequiredByDefault() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newLedgerRegistry(), "models/entry.py")
+
+	suite.Contains(content, "    number: int = Field(alias=\"Number\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PolymorphicDuplicateCandidates() {
+	r := registry.NewRegistry()
+	for _, modelName := range []string{"Person", "Company"} {
+		r.SetModel(modelName, yaml.Model{
+			Name: modelName,
+			Fields: map[string]yaml.ModelField{
+				"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+			},
+			Identifiers: map[string]yaml.ModelIdentifier{
+				"primary": {Fields: []string{"ID"}},
+			},
+		})
+	}
+	r.SetModel("Comment", yaml.Model{
+		Name: "Comment",
+		Fields: map[string]yaml.ModelField{
+			"ID":      {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Content": {Type: yaml.ModelFieldTypeString},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Commentable": {Type: "ForOnePoly", For: []string{"Person", "Company", "Person"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, r, "models/comment.py")
+
+	suite.Contains(content, `    commentable_type: Optional[Literal["Person", "Company"]] = None`+"\n")
+	suite.Contains(content, `    commentable: Optional[Union["Person", "Company"]] = None`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ImmutableIds() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ImmutableIds = true
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/order.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    id_: int = Field(alias=\"ID\", frozen=True)\n")
+	suite.Contains(content, "    customer_id: Optional[str] = Field(default=None, frozen=True)\n")
+	suite.Contains(content, "    total: float = Field(alias=\"Total\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ImmutableIdsPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.ImmutableIds = true
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/order.py")
+
+	suite.NotContains(content, "frozen=True")
+}
+
+// newFrozenIdRegistry builds a registry with a model whose id is individually frozen
+func newFrozenIdRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Ticket", yaml.Model{
+		Name: "Ticket",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeUUID, Attributes: []string{"frozen", "description:Ticket id"}},
+			"Title": {Type: yaml.ModelFieldTypeString},
+			"Notes": {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FrozenField() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newFrozenIdRegistry(), "models/ticket.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    id_: str = Field(description=\"Ticket id\", alias=\"ID\", frozen=True)\n")
+	suite.Contains(content, "    title: str = Field(alias=\"Title\")\n")
+	suite.Contains(content, "    notes: Optional[str] = Field(default=None, alias=\"Notes\")\n")
+	suite.NotContains(content, "ConfigDict(frozen=True")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FrozenFieldPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newFrozenIdRegistry(), "models/ticket.py")
+
+	suite.NotContains(content, "frozen=True")
+}
+
+// newContactRegistry builds a registry with a model whose field names span several words
+func newContactRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Contact", yaml.Model{
+		Name: "Contact",
+		Fields: map[string]yaml.ModelField{
+			"ID":        {Type: yaml.ModelFieldTypeAutoIncrement},
+			"FirstName": {Type: yaml.ModelFieldTypeString},
+			"Nickname":  {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SerializationAlias() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ValidationAlias = cfg.FieldNameConventionSnakeCase
+	config.MorpheConfig.Models.SerializationAlias = cfg.FieldNameConventionCamelCase
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    first_name: str = Field(serialization_alias=\"firstName\")\n")
+	suite.Contains(content, "    id_: int = Field(alias=\"id\")\n")
+	suite.Contains(content, "    nickname: Optional[str] = None\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SameAliasBothWays() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ValidationAlias = cfg.FieldNameConventionCamelCase
+	config.MorpheConfig.Models.SerializationAlias = cfg.FieldNameConventionCamelCase
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    first_name: str = Field(alias=\"firstName\")\n")
+	suite.NotContains(content, "serialization_alias")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SerializationAliasPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.SerializationAlias = cfg.FieldNameConventionCamelCase
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    first_name: str = Field(alias=\"firstName\")\n")
+	suite.NotContains(content, "serialization_alias")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DistinctAliasesPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.ValidationAlias = cfg.FieldNameConventionSnakeCase
+	config.MorpheConfig.Models.SerializationAlias = cfg.FieldNameConventionCamelCase
+
+	suite.ErrorContains(config.Validate(), "distinct validation and serialization aliases require Pydantic v2")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PreserveFieldKeys() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    first_name: str = Field(alias=\"FirstName\")\n")
+	suite.Contains(content, "    id_: int = Field(alias=\"ID\")\n")
+	suite.Contains(content, "    nickname: Optional[str] = Field(default=None, alias=\"Nickname\")\n")
+	suite.Contains(content, `
+    model_config = {
+        "populate_by_name": True,
+    }
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PreserveFieldKeysPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    first_name: str = Field(alias=\"FirstName\")\n")
+	suite.Contains(content, `
+    class Config:
+        allow_population_by_field_name = True
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PreserveFieldKeysUnchangedNames() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = compile.FieldCaseAsIs
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.NotContains(content, "alias=")
+	suite.NotContains(content, "populate_by_name")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PreserveFieldKeysDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DisableFieldKeyAliases = true
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    first_name: str\n")
+	suite.NotContains(content, "alias=")
+	suite.NotContains(content, "populate_by_name")
+}
+func (suite *CompileTestSuite) TestCompileModel_PreserveFieldKeysSkipsForeignKeys() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newCustomerOrderRegistry(), "models/order.py")
+
+	suite.Contains(content, "    customer_id: Optional[str] = None\n")
+	suite.NotContains(content, "customerId")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ConfiguredAliasOverridesFieldKeys() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.SerializationAlias = cfg.FieldNameConventionCamelCase
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    first_name: str = Field(serialization_alias=\"firstName\")\n")
+	suite.NotContains(content, "FirstName")
+	suite.NotContains(content, "populate_by_name")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldCaseCamel() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = compile.FieldCaseCamel
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    firstName: str = Field(alias=\"FirstName\")\n")
+	suite.Contains(content, "    nickname: Optional[str] = Field(default=None, alias=\"Nickname\")\n")
+	suite.NotContains(content, "first_name")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldCaseAsIs() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = compile.FieldCaseAsIs
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    FirstName: str\n")
+	suite.Contains(content, "    ID: int\n")
+	suite.Contains(content, "    Nickname: Optional[str] = None\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldCaseAliases() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = compile.FieldCaseCamel
+	config.MorpheConfig.Models.ValidationAlias = cfg.FieldNameConventionSnakeCase
+	config.MorpheConfig.Models.SerializationAlias = cfg.FieldNameConventionCamelCase
+
+	content := suite.generateSource(config, newContactRegistry(), "models/contact.py")
+
+	suite.Contains(content, "    firstName: str = Field(validation_alias=\"first_name\")\n")
+	suite.NotContains(content, "serialization_alias")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldCaseInvalid() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = "kebab"
+
+	suite.EqualError(config.Validate(), "invalid fieldCase: kebab (must be 'snake', 'as_is' or 'camel')")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DocstringStyleGoogle() {
+	r := registry.NewRegistry()
+	r.SetModel("Contact", yaml.Model{
+		Name: "Contact",
+		Fields: map[string]yaml.ModelField{
+			"ID":    {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Phone": {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional", "description:Primary phone number"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleGoogle
+
+	content := suite.generateSource(config, r, "models/contact.py")
+
+	suite.Contains(content, `    """Contact model.
+
+    Attributes:
+        id_ (int)
+        phone (Optional[str]): Primary phone number
+    """
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DocstringStyleNumPy() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleNumPy
+
+	content := suite.generateSource(config, newMemberRegistry(), "models/member.py")
+
+	suite.Contains(content, `    """Member model.
+
+    Attributes
+    ----------
+    id_ : int
+`)
+}
+
+func (suite *CompileTestSuite) TestValidate_InvalidDocstringStyle() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = "sphinx"
+
+	err := config.Validate()
+
+	suite.EqualError(err, "invalid docstringStyle: sphinx (must be 'plain', 'google' or 'numpy')")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ValidateDefaults() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.ValidateDefaults = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, `    quote: Optional[str] = Field(
+        default=None, pattern=r"^\"[^\"]*\"$", alias="Quote", validate_default=True
+    )
+`)
+	suite.Contains(content, `    phone: str = Field(pattern=r"^\d{3}-\d{4}$", alias="Phone")`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_ValidateDefaultsPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Models.ValidateDefaults = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.NotContains(content, "validate_default")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_SequenceCollectionType() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.CollectionType = cfg.CollectionTypeSequence
+
+	content := suite.generateSource(config, newCustomerOrderRegistry(), "models/customer.py")
+
+	suite.Contains(content, "from typing import TYPE_CHECKING, Optional, Sequence\n")
+	suite.Contains(content, "    orders: Optional[Sequence[\"Order\"]] = None\n")
+	suite.NotContains(content, "List")
+}
+
+func (suite *CompileTestSuite) TestValidate_InvalidCollectionType() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.CollectionType = "tuple"
+
+	err := config.Validate()
+
+	suite.EqualError(err, "invalid models.collectionType: tuple (must be 'list' or 'sequence')")
+}
+
+func newCyclicEnumRegistry() *registry.Registry {
+	r := newCustomerOrderRegistry()
+	r.SetEnum("CustomerTier", yaml.Enum{
+		Name:    "CustomerTier",
+		Type:    yaml.EnumTypeString,
+		Entries: map[string]any{"Gold": "gold"},
+	})
+	r.SetEnum("OrderState", yaml.Enum{
+		Name:    "OrderState",
+		Type:    yaml.EnumTypeString,
+		Entries: map[string]any{"Open": "open"},
+	})
+	customer, _ := r.GetModel("Customer")
+	customer.Fields["LastOrderState"] = yaml.ModelField{Type: yaml.ModelFieldType("OrderState")}
+	r.SetModel("Customer", customer)
+	order, _ := r.GetModel("Order")
+	order.Fields["Tier"] = yaml.ModelField{Type: yaml.ModelFieldType("CustomerTier")}
+	r.SetModel("Order", order)
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CyclicEnumImportsAtRuntimeByDefault() {
+	r := newCyclicEnumRegistry()
+
+	customer := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/customer.py")
+	order := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/order.py")
+
+	suite.Contains(customer, "\nfrom ..enums.order_state import OrderState\n")
+	suite.Contains(customer, "    last_order_state: OrderState = Field(alias=\"LastOrderState\")\n")
+	suite.Contains(order, "\nfrom ..enums.customer_tier import CustomerTier\n")
+	suite.Contains(order, "    tier: CustomerTier = Field(alias=\"Tier\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DeferCyclicEnumImports() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.DeferCyclicImports = true
+	r := newCyclicEnumRegistry()
+
+	customer := suite.generateSource(config, r, "models/customer.py")
+	order := suite.generateSource(config, r, "models/order.py")
+	index := suite.generateSource(config, r, "models/__init__.py")
+
+	suite.Contains(customer, `
+if TYPE_CHECKING:
+    from ..enums.order_state import OrderState
+    from .order import Order
+`)
+	suite.Contains(customer, `    last_order_state: "OrderState" = Field(alias="LastOrderState")`+"\n")
+	suite.NotContains(customer, "\nfrom ..enums")
+	suite.Contains(order, `
+if TYPE_CHECKING:
+    from ..enums.customer_tier import CustomerTier
+    from .customer import Customer
+`)
+	suite.Contains(order, `    tier: "CustomerTier" = Field(alias="Tier")`+"\n")
+	suite.True(strings.HasSuffix(index, `from ..enums.customer_tier import CustomerTier
+from ..enums.order_state import OrderState
+from .customer import Customer
+from .order import Order
+
+
+Order.model_rebuild()
+Customer.model_rebuild()
+`))
+}
+
+// TestCompileModel_CyclicEnumImportsResolve imports the models of a relationship cycle, each
+// referencing the other's enum, so the forward references are resolved by Pydantic
+func (suite *CompileTestSuite) TestCompileModel_CyclicEnumImportsResolve() {
+	suite.assertCyclicEnumModelsResolve(compile.DefaultMorpheCompileConfig("", ""))
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DeferCyclicEnumImportsResolve() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Enums.DeferCyclicImports = true
+
+	suite.assertCyclicEnumModelsResolve(config)
+}
+
+// assertCyclicEnumModelsResolve compiles the cyclic enum registry into a package and imports it
+// with Pydantic, skipping when python3 or pydantic is unavailable
+func (suite *CompileTestSuite) assertCyclicEnumModelsResolve(config compile.MorpheCompileConfig) {
+	pythonPath, err := exec.LookPath("python3")
+	if err != nil {
+		suite.T().Skip("python3 not available")
+	}
+	if exec.Command(pythonPath, "-c", "import pydantic").Run() != nil {
+		suite.T().Skip("pydantic not available")
+	}
+	packageParentPath := suite.T().TempDir()
+	outputDirPath := filepath.Join(packageParentPath, "generated")
+	config.OutputPath = outputDirPath
+	r := newCyclicEnumRegistry()
+	writer := compile.NewMorpheWriter(outputDirPath)
+	suite.Require().NoError(compile.CompileAllEnums(config, r, writer))
+	suite.Require().NoError(compile.CompileAllModels(config, r, writer))
+
+	cmd := exec.Command(pythonPath, "-c", "from generated.models import Customer, Order; Customer.model_json_schema(); Order.model_json_schema()")
+	cmd.Dir = packageParentPath
+	output, err := cmd.CombinedOutput()
+	suite.NoError(err, string(output))
+}
+
+func (suite *CompileTestSuite) TestCompileModel_DisableHash() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.DisableHash = true
+
+	content := suite.generateSource(config, newPatternRegistry(), "models/contact.py")
+
+	suite.Contains(content, `
+    quote: Optional[str] = Field(default=None, pattern=r"^\"[^\"]*\"$", alias="Quote")
+
+    __hash__ = None  # type: ignore[assignment]
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HashableByDefault() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newPatternRegistry(), "models/contact.py")
+
+	suite.NotContains(content, "__hash__")
+}
+
+func newBoundedScoreRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Score", yaml.Model{
+		Name: "Score",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Points": {Type: yaml.ModelFieldTypeInteger, Attributes: []string{"le:100", "ge:0"}},
+			"Weight": {Type: yaml.ModelFieldTypeFloat, Attributes: []string{"optional", "lt:1", "gt:0"}},
+			"Label":  {Type: yaml.ModelFieldTypeString, Attributes: []string{"ge:0"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_NumericBoundsOrder() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newBoundedScoreRegistry(), "models/score.py")
+
+	suite.Contains(content, "    points: int = Field(ge=0, le=100, alias=\"Points\")\n")
+	suite.Contains(content, "    weight: Optional[float] = Field(default=None, gt=0, lt=1, alias=\"Weight\")\n")
+	suite.Contains(content, "    label: str = Field(alias=\"Label\")\n")
+	for i := 0; i < 10; i++ {
+		suite.Equal(content, suite.generateSource(config, newBoundedScoreRegistry(), "models/score.py"))
+	}
+}
+
+func (suite *CompileTestSuite) TestCompileModel_NumericBoundNotANumber() {
+	r := newBoundedScoreRegistry()
+	score, _ := r.GetModel("Score")
+	score.Fields["Points"] = yaml.ModelField{Type: yaml.ModelFieldTypeInteger, Attributes: []string{"ge:abc"}}
+	r.SetModel("Score", score)
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(compile.DefaultMorpheCompileConfig("", ""), r, writer)
+
+	var valueErr *compile.AttributeValueError
+	suite.Require().ErrorAs(err, &valueErr)
+	suite.Equal("Score", valueErr.Owner)
+	suite.Equal("Points", valueErr.Field)
+	suite.ErrorContains(err, "invalid ge:abc attribute on Score.Points: not a valid int literal")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_NumericBoundFractionalOnInt() {
+	r := newBoundedScoreRegistry()
+	score, _ := r.GetModel("Score")
+	score.Fields["Points"] = yaml.ModelField{Type: yaml.ModelFieldTypeInteger, Attributes: []string{"gt:0.5", "le:1e3"}}
+	r.SetModel("Score", score)
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/score.py")
+
+	suite.Contains(content, "    points: int = Field(gt=0.5, le=1e3, alias=\"Points\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_NumericExampleNotANumber() {
+	r := newSampleDataRegistry()
+	product, _ := r.GetModel("Product")
+	product.Fields["Price"] = yaml.ModelField{Type: yaml.ModelFieldTypeFloat, Attributes: []string{"example:cheap"}}
+	r.SetModel("Product", product)
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(compile.DefaultMorpheCompileConfig("", ""), r, writer)
+
+	suite.ErrorContains(err, "invalid example:cheap attribute on Product.Price: not a valid float literal")
+}
+
+func newShipmentRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Shipment", yaml.Model{
+		Name: "Shipment",
+		Fields: map[string]yaml.ModelField{
+			"ID":      {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Carrier": {Type: yaml.ModelFieldTypeString, Attributes: []string{"choice:ups", "choice:dhl", "choice:fedex"}},
+			"Window":  {Type: yaml.ModelFieldTypeString, Attributes: []string{"choice:date", "choice:time", "optional"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_LiteralChoices() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newShipmentRegistry(), "models/shipment.py")
+
+	suite.Contains(content, "from typing import Literal, Optional\n")
+	suite.Contains(content, "    carrier: Literal[\"ups\", \"dhl\", \"fedex\"] = Field(alias=\"Carrier\")\n")
+	suite.Contains(content, "    window: Optional[Literal[\"date\", \"time\"]] = Field(default=None, alias=\"Window\")\n")
+	suite.NotContains(content, "from datetime import")
+}
+
+func newAuditRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	for _, modelName := range []string{"Post", "Photo"} {
+		r.SetModel(modelName, yaml.Model{
+			Name: modelName,
+			Fields: map[string]yaml.ModelField{
+				"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+			},
+			Identifiers: map[string]yaml.ModelIdentifier{
+				"primary": {Fields: []string{"ID"}},
+			},
+		})
+	}
+	r.SetModel("Audit", yaml.Model{
+		Name: "Audit",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Code":     {Type: yaml.ModelFieldTypeString, Attributes: []string{"pattern:^[A-Z]{3}$"}},
+			"PrevCode": {Type: yaml.ModelFieldTypeString, Attributes: []string{"pattern:^[A-Z]{3}$"}},
+			"Note":     {Type: yaml.ModelFieldTypeString, Attributes: []string{"pattern:^.+$"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Commentable": {Type: "ForOnePoly", For: []string{"Post", "Photo"}},
+			"Origin":      {Type: "ForOnePoly", For: []string{"Post", "Photo"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HoistTypeAliases() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.10"
+	config.MorpheConfig.Models.ConstraintStyle = cfg.ConstraintStyleAnnotated
+	config.MorpheConfig.Models.HoistTypeAliases = true
+
+	content := suite.generateSource(config, newAuditRegistry(), "models/audit.py")
+
+	suite.Contains(content, "from typing import TYPE_CHECKING, Annotated, Literal, Optional, TypeAlias, Union\n")
+	suite.Contains(content, `CodeType: TypeAlias = Annotated[str, StringConstraints(pattern=r"^[A-Z]{3}$")]
+CommentableRef: TypeAlias = Union["Post", "Photo"]
+
+
+class Audit(BaseModel):
+`)
+	suite.Contains(content, "    code: CodeType = Field(alias=\"Code\")\n")
+	suite.Contains(content, "    prev_code: CodeType = Field(alias=\"PrevCode\")\n")
+	suite.Contains(content, `    note: Annotated[str, StringConstraints(pattern=r"^.+$")] = Field(alias="Note")`+"\n")
+	suite.Contains(content, "    commentable: Optional[CommentableRef] = None\n")
+	suite.Contains(content, "    origin: Optional[CommentableRef] = None\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HoistTypeAliasesLegacyPython() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.HoistTypeAliases = true
+
+	content := suite.generateSource(config, newAuditRegistry(), "models/audit.py")
+
+	suite.Contains(content, "from typing_extensions import TypeAlias\n")
+	suite.Contains(content, `CommentableRef: TypeAlias = Union["Post", "Photo"]`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HoistTypeAliasesDisabled() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newAuditRegistry(), "models/audit.py")
+
+	suite.NotContains(content, "TypeAlias")
+	suite.Contains(content, `    origin: Optional[Union["Post", "Photo"]] = None`+"\n")
+}
+
+// newFeedRegistry returns a Feed model collecting posts and photos through a many-polymorphic relationship
+func newFeedRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	for _, modelName := range []string{"Post", "Photo"} {
+		r.SetModel(modelName, yaml.Model{
+			Name: modelName,
+			Fields: map[string]yaml.ModelField{
+				"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+			},
+			Identifiers: map[string]yaml.ModelIdentifier{
+				"primary": {Fields: []string{"ID"}},
+			},
+		})
+	}
+	r.SetModel("Feed", yaml.Model{
+		Name: "Feed",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Items": {Type: "HasManyPoly", For: []string{"Post", "Photo"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyDiscriminatedUnion() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.11"
+
+	content := suite.generateSource(config, newFeedRegistry(), "models/feed.py")
+
+	suite.Contains(content, "from typing import TYPE_CHECKING, Annotated, Optional, Union\n")
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    from .photo import Photo\n")
+	suite.Contains(content, "    from .post import Post\n")
+	suite.Contains(content, `    items: Optional[
+        list[Annotated[Union["Post", "Photo"], Field(discriminator="kind")]]
+    ] = None
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyMemberDiscriminator() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newFeedRegistry(), "models/post.py")
+
+	suite.Contains(content, "from typing import Literal")
+	suite.Contains(content, `    kind: Literal["Post"] = Field(default="Post", alias="Kind")`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyDeclaredDiscriminatorNotLiteral() {
+	r := newFeedRegistry()
+	post, _ := r.GetModel("Post")
+	post.Fields["Kind"] = yaml.ModelField{Type: yaml.ModelFieldTypeString}
+	r.SetModel("Post", post)
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(compile.DefaultMorpheCompileConfig("", ""), r, writer)
+
+	var discriminatorErr *compile.DiscriminatorNotLiteralError
+	suite.Require().True(errors.As(err, &discriminatorErr))
+	suite.Equal("Post", discriminatorErr.Model)
+	suite.Equal("Kind", discriminatorErr.Field)
+	suite.EqualError(err, "failed to compile model Post: model Post is a polymorphic union member but its Kind field is not a Literal (add choice attributes or remove it)")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyCustomDiscriminator() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PolymorphicDiscriminator = "MediaType"
+
+	feed := suite.generateSource(config, newFeedRegistry(), "models/feed.py")
+	post := suite.generateSource(config, newFeedRegistry(), "models/post.py")
+
+	suite.Contains(feed, `Field(discriminator="media_type")`)
+	suite.Contains(post, `    media_type: Literal["Post"] = Field(default="Post", alias="MediaType")`+"\n")
+	suite.NotContains(post, "kind")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyDiscriminatorDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DisablePolymorphicDiscriminator = true
+
+	feed := suite.generateSource(config, newFeedRegistry(), "models/feed.py")
+	post := suite.generateSource(config, newFeedRegistry(), "models/post.py")
+
+	suite.Contains(feed, `    items: Optional[List[Union["Post", "Photo"]]] = None`+"\n")
+	suite.NotContains(feed, "discriminator")
+	suite.NotContains(post, "Literal")
+}
+
+func (suite *CompileTestSuite) TestValidate_PolymorphicDiscriminator() {
+	config := compile.DefaultMorpheCompileConfig(suite.TestDirPath+"/registry/minimal", "")
+	config.FormatConfig.PolymorphicDiscriminator = "media-type"
+
+	suite.EqualError(config.Validate(), "invalid polymorphicDiscriminator: media-type (must be an identifier)")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyDeclaredLiteralDiscriminator() {
+	r := newFeedRegistry()
+	post, _ := r.GetModel("Post")
+	post.Fields["Kind"] = yaml.ModelField{Type: yaml.ModelFieldTypeString, Attributes: []string{"choice:Post"}}
+	r.SetModel("Post", post)
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/post.py")
+
+	suite.Contains(content, `    kind: Literal["Post"] = Field(alias="Kind")`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_HasManyPolyLegacyPython() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newFeedRegistry(), "models/feed.py")
+
+	suite.Contains(content, "from typing_extensions import Annotated\n")
+}
+
+// newAccountRegistry returns an Account model with a sensitive password hash
+func newAccountRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Account", yaml.Model{
+		Name: "Account",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+			"PasswordHash": {Type: yaml.ModelFieldTypeString, Attributes: []string{
+				"optional", "description:Salted password hash", "exclude", "norepr",
+			}},
+			"Avatar": {Type: yaml.ModelFieldTypeString, Attributes: []string{"norepr"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldVisibility() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.UseField = true
+
+	content := suite.generateSource(config, newAccountRegistry(), "models/account.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, `    password_hash: Optional[str] = Field(
+        default=None,
+        description="Salted password hash",
+        exclude=True,
+        repr=False,
+        alias="PasswordHash",
+    )
+`)
+	suite.Contains(content, "    avatar: str = Field(repr=False, alias=\"Avatar\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_FieldVisibilityWithoutUseField() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newAccountRegistry(), "models/account.py")
+
+	suite.Contains(content, `    password_hash: Optional[str] = Field(
+        default=None, description="Salted password hash", alias="PasswordHash"
+    )
+`)
+	suite.Contains(content, "    avatar: str = Field(alias=\"Avatar\")\n")
+	suite.NotContains(content, "repr=False")
+	suite.NotContains(content, "exclude=True")
+}
+
+// newCycleRegistry returns two models referencing each other
+func newCycleRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Author", yaml.Model{
+		Name: "Author",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{"Book": {Type: "HasMany"}},
+	})
+	r.SetModel("Book", yaml.Model{
+		Name: "Book",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{"Author": {Type: "ForOne"}},
+	})
+	r.SetModel("Genre", yaml.Model{
+		Name: "Genre",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CycleRebuiltInIndex() {
+	r := newCycleRegistry()
+
+	author := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/author.py")
+	book := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/book.py")
+	index := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "models/__init__.py")
+
+	suite.Contains(author, "    book: Optional[List[\"Book\"]] = None\n")
+	suite.Contains(book, "    author: Optional[\"Author\"] = None\n")
+	suite.True(strings.HasSuffix(index, `from .author import Author
+from .book import Book
+from .genre import Genre
+
+
+Book.model_rebuild()
+Author.model_rebuild()
+`))
+}
+
+func (suite *CompileTestSuite) TestCompileModel_CycleRebuiltInIndexPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	index := suite.generateSource(config, newCycleRegistry(), "models/__init__.py")
+
+	suite.True(strings.HasSuffix(index, "\n\n\nBook.update_forward_refs(**globals())\nAuthor.update_forward_refs(**globals())\n"))
+}
+
+// newScheduleRegistry builds a registry whose model, structure and entity hold DateTime fields,
+// mapped to Python's datetime by a custom type mapping
+func newScheduleRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Event", yaml.Model{
+		Name: "Event",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"StartsAt": {Type: yaml.ModelFieldType("DateTime")},
+			"EndsAt":   {Type: yaml.ModelFieldType("DateTime"), Attributes: []string{"optional"}},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	r.SetStructure("Window", yaml.Structure{
+		Name: "Window",
+		Fields: map[string]yaml.StructureField{
+			"OpensAt": {Type: yaml.StructureFieldType("DateTime")},
+		},
+	})
+	r.SetEntity("Event", yaml.Entity{
+		Name: "Event",
+		Fields: map[string]yaml.EntityField{
+			"ID":       {Type: "Event.ID"},
+			"StartsAt": {Type: "Event.StartsAt"},
+		},
+		Identifiers: map[string]yaml.EntityIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+// awareDatetimesConfig maps DateTime fields to datetime and requires them timezone-aware
+func awareDatetimesConfig() compile.MorpheCompileConfig {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"DateTime": {Type: "datetime", Import: "from datetime import datetime"},
+	}
+	config.FormatConfig.AwareDatetimes = true
+	return config
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AwareDatetimes() {
+	config := awareDatetimesConfig()
+
+	model := suite.generateSource(config, newScheduleRegistry(), "models/event.py")
+	structure := suite.generateSource(config, newScheduleRegistry(), "structures/window.py")
+	entity := suite.generateSource(config, newScheduleRegistry(), "entities/event.py")
+
+	suite.Contains(model, "from pydantic import AwareDatetime, BaseModel, Field\n")
+	suite.Contains(model, "    ends_at: Optional[AwareDatetime] = Field(default=None, alias=\"EndsAt\")\n")
+	suite.Contains(model, "    starts_at: AwareDatetime = Field(alias=\"StartsAt\")\n")
+	suite.NotContains(model, "from datetime import datetime")
+	suite.Contains(structure, "    opens_at: AwareDatetime = Field(alias=\"OpensAt\")\n")
+	suite.Contains(entity, "    starts_at: AwareDatetime = Field(alias=\"StartsAt\")\n")
+	suite.NotContains(model+structure+entity, "validator")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AwareDatetimesDefaultMappings() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.AwareDatetimes = true
+	r := registry.NewRegistry()
+	r.SetModel("Shift", yaml.Model{
+		Name: "Shift",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Day":    {Type: yaml.ModelFieldTypeDate},
+			"Starts": {Type: yaml.ModelFieldTypeTime},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+
+	content := suite.generateSource(config, r, "models/shift.py")
+
+	suite.Contains(content, "from datetime import date, time\n")
+	suite.Contains(content, "    day: date = Field(alias=\"Day\")\n")
+	suite.Contains(content, "    starts: time = Field(alias=\"Starts\")\n")
+	suite.NotContains(content, "AwareDatetime")
+	suite.NotContains(content, "validator")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_AwareDatetimesPydanticV1() {
+	config := awareDatetimesConfig()
+	config.FormatConfig.PydanticV2 = false
+
+	model := suite.generateSource(config, newScheduleRegistry(), "models/event.py")
+	structure := suite.generateSource(config, newScheduleRegistry(), "structures/window.py")
+	entity := suite.generateSource(config, newScheduleRegistry(), "entities/event.py")
+
+	suite.Contains(model, "from pydantic import BaseModel, Field, validator\n")
+	suite.Contains(model, "    starts_at: datetime = Field(alias=\"StartsAt\")\n")
+	suite.Contains(model, `
+    @validator("ends_at", "starts_at")
+    def require_aware_datetimes(cls, value):
+        """Reject datetimes without a timezone."""
+        if value is not None and value.tzinfo is None:
+            raise ValueError("datetime must be timezone-aware")
+        return value
+`)
+	suite.Contains(structure, `@validator("opens_at")`)
+	suite.Contains(entity, `@validator("starts_at")`)
+}
+
+// newUnresolvedPolyRegistry builds a registry whose Post model has polymorphic relationships
+// without candidates, one through a relationship that doesn't exist
+func newUnresolvedPolyRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetModel("Post", yaml.Model{
+		Name: "Post",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Attachment": {Type: "HasOnePoly"},
+			"Comments":   {Type: "HasManyPoly", Through: "Commentable"},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnresolvedPolymorphicFallsBackToAny() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newUnresolvedPolyRegistry(), "models/post.py")
+
+	suite.Contains(content, "    attachment: Optional[Any] = None\n")
+	suite.Contains(content, "    comments: Optional[List[Any]] = None\n")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_StrictRelations() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.StrictRelations = true
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(config, newUnresolvedPolyRegistry(), writer)
+
+	var relationErr *compile.RelationResolveError
+	suite.Require().ErrorAs(err, &relationErr)
+	suite.Equal("Post", relationErr.Model)
+	suite.Equal("Attachment", relationErr.Relation)
+}
+
+func (suite *CompileTestSuite) TestCompileModel_PolymorphicFallbackWarnings() {
+	var warnings bytes.Buffer
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.Verbose = true
+	config.Warnings = &warnings
+
+	suite.generateSource(config, newUnresolvedPolyRegistry(), "models/post.py")
+
+	suite.Equal("Warning: failed to resolve relation Attachment in model Post: polymorphic relationship has neither for nor through; typed as Any\n"+
+		"Warning: failed to resolve relation Comments in model Post: failed to resolve relation Commentable; typed as Any\n", warnings.String())
+}
+
+func (suite *CompileTestSuite) TestCompileModel_StrictRelationsNoTargets() {
+	r := newUnresolvedPolyRegistry()
+	post, _ := r.GetModel("Post")
+	delete(post.Related, "Comments")
+	r.SetModel("Post", post)
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.StrictRelations = true
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(config, r, writer)
+
+	suite.ErrorIs(err, compile.ErrNoPolymorphicTargets)
+	suite.EqualError(err, "failed to compile model Post: failed to resolve relation Attachment in model Post: polymorphic relationship has neither for nor through")
+}
+
+// newUnknownRelationRegistry builds a registry whose Post model has a relationship of a type the
+// compiler doesn't know next to a regular one
+func newUnknownRelationRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	for _, modelName := range []string{"Author", "Tag"} {
+		r.SetModel(modelName, yaml.Model{
+			Name: modelName,
+			Fields: map[string]yaml.ModelField{
+				"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+			},
+			Identifiers: map[string]yaml.ModelIdentifier{
+				"primary": {Fields: []string{"ID"}},
+			},
+		})
+	}
+	r.SetModel("Post", yaml.Model{
+		Name: "Post",
+		Fields: map[string]yaml.ModelField{
+			"ID": {Type: yaml.ModelFieldTypeAutoIncrement},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+		Related: map[string]yaml.ModelRelation{
+			"Author": {Type: "ForOne"},
+			"Tag":    {Type: "ForOneOrMany"},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnknownRelationType() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllModels(config, newUnknownRelationRegistry(), writer)
+
+	var relationErr *compile.RelationResolveError
+	suite.Require().ErrorAs(err, &relationErr)
+	suite.Equal("Post", relationErr.Model)
+	suite.Equal("Tag", relationErr.Relation)
+	suite.ErrorContains(err, "failed to resolve relation Tag in model Post: unknown relation type: ForOneOrMany")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnknownRelationTypeWarn() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.UnknownRelations = compile.UnknownRelationsWarn
+
+	content := suite.generateSource(config, newUnknownRelationRegistry(), "models/post.py")
+
+	suite.Contains(content, "    author_id: Optional[str] = None\n")
+	suite.NotContains(content, "tag")
+	suite.NotContains(content, "Tag")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnknownRelationTypeWarnsToWriter() {
+	var warnings bytes.Buffer
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.UnknownRelations = compile.UnknownRelationsWarn
+	config.Warnings = &warnings
+
+	suite.generateSource(config, newUnknownRelationRegistry(), "models/post.py")
+
+	suite.Equal("Warning: failed to resolve relation Tag in model Post: unknown relation type: ForOneOrMany; relation skipped\n", warnings.String())
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnknownRelationTypeWarnSkipsPolymorphicMembers() {
+	r := newUnknownRelationRegistry()
+	post, _ := r.GetModel("Post")
+	post.Related["Attachment"] = yaml.ModelRelation{Type: "HasManyToManyPoly", For: []string{"Author", "Tag"}}
+	r.SetModel("Post", post)
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.UnknownRelations = compile.UnknownRelationsWarn
+	config.Warnings = io.Discard
+
+	content := suite.generateSource(config, r, "models/author.py")
+
+	suite.NotContains(content, "kind")
+	suite.NotContains(content, "Literal")
+}
+
+func (suite *CompileTestSuite) TestCompileModel_UnknownRelationsInvalid() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.UnknownRelations = "ignore"
+
+	suite.EqualError(config.Validate(), "invalid unknownRelations: ignore (must be 'error' or 'warn')")
+}
@@ -0,0 +1,183 @@
+package compile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// jsonSchemaDialect is the JSON Schema draft the exported document declares
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// basicTypeToJSONSchema maps Python basic types to their JSON Schema equivalents
+var basicTypeToJSONSchema = map[string]map[string]any{
+	"str":           {"type": "string"},
+	"int":           {"type": "integer"},
+	"float":         {"type": "number"},
+	"bool":          {"type": "boolean"},
+	"date":          {"type": "string", "format": "date"},
+	"time":          {"type": "string", "format": "time"},
+	"datetime":      {"type": "string", "format": "date-time"},
+	"AwareDatetime": {"type": "string", "format": "date-time"},
+	"Any":           {},
+}
+
+// GenerateJSONSchema builds a JSON Schema document with a $defs entry for every enum, structure
+// and model in the registry. It is derived from the compiled definitions, so the property names
+// and required-ness match the generated Pydantic models. Navigation properties are omitted.
+func GenerateJSONSchema(config MorpheCompileConfig, r *registry.Registry) ([]byte, error) {
+	defs := make(map[string]any)
+
+	for enumName, enum := range r.GetAllEnums() {
+		compiledEnum, err := CompileEnum(enum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile enum %s: %w", enumName, err)
+		}
+		defs[enumName] = enumJSONSchema(compiledEnum)
+	}
+
+	for structureName, structure := range r.GetAllStructures() {
+		compiledStructure, err := compileStructure(structure, r, config.FormatConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile structure %s: %w", structureName, err)
+		}
+		defs[structureName] = structureJSONSchema(compiledStructure, config.FormatConfig, r)
+	}
+
+	// Required-ness follows the typed model declarations
+	formatConfig := config.FormatConfig
+	formatConfig.AddTypeHints = true
+	formatConfig.SortRequiredFirst = false // declarations must follow the model's field order
+	for modelName, model := range r.GetAllModels() {
+		// Abstract bases are never instantiated, so they have no schema of their own
+		if config.MorpheConfig.Models.IsAbstract(modelName) {
+			continue
+		}
+		compiledModel, err := compileModel(model, r, config.FormatConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile model %s: %w", modelName, err)
+		}
+		defs[modelName] = modelJSONSchema(compiledModel, formatConfig, config, r)
+	}
+
+	document := map[string]any{
+		"$schema": jsonSchemaDialect,
+		"$defs":   defs,
+	}
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// enumJSONSchema renders an enum as a typed value list
+func enumJSONSchema(enum *formatdef.Enum) map[string]any {
+	values := make([]any, 0, len(enum.Entries))
+	for _, entry := range enum.Entries {
+		values = append(values, entry.Value)
+	}
+	schema := map[string]any{
+		"title": enum.Name,
+		"enum":  values,
+	}
+	for key, value := range basicTypeToJSONSchema[enum.Type.GetName()] {
+		schema[key] = value
+	}
+	return schema
+}
+
+// structureJSONSchema renders a structure as an object, skipping class variables
+func structureJSONSchema(structure *formatdef.Struct, formatConfig PydanticConfig, r *registry.Registry) map[string]any {
+	if rootField, isRoot := structureRootField(structure); isRoot {
+		schema := fieldJSONSchema(rootField.Type, rootField.IsOptional, r)
+		schema["title"] = structure.Name
+		return schema
+	}
+	properties := make(map[string]any)
+	required := []string{}
+	for _, field := range structure.Fields {
+		if field.IsClassVar {
+			continue
+		}
+		fieldName := formatConfig.pythonFieldName(field.Name)
+		properties[fieldName] = fieldJSONSchema(field.Type, field.IsOptional, r)
+		if !field.IsOptional && field.Default == "" {
+			required = append(required, fieldName)
+		}
+	}
+	return objectJSONSchema(structure.Name, properties, required)
+}
+
+// modelJSONSchema renders the data fields of a model as an object
+func modelJSONSchema(model *formatdef.Struct, formatConfig PydanticConfig, config MorpheCompileConfig, r *registry.Registry) map[string]any {
+	// Data field declarations come first and follow the model's field order
+	decls, _ := modelFieldDecls(model, formatConfig, config.MorpheConfig)
+
+	properties := make(map[string]any)
+	required := []string{}
+	declIndex := 0
+	for _, field := range model.Fields {
+		if strings.HasPrefix(field.Name, "_nav_") {
+			continue
+		}
+		decl := decls[declIndex]
+		declIndex++
+
+		nullable := strings.HasPrefix(decl.Annotation, "Optional[") || strings.Contains(decl.Annotation, "None")
+		properties[decl.Name] = fieldJSONSchema(field.Type, nullable, r)
+		if decl.Required() {
+			required = append(required, decl.Name)
+		}
+	}
+	return objectJSONSchema(model.Name, properties, required)
+}
+
+// objectJSONSchema renders an object schema with sorted required properties
+func objectJSONSchema(title string, properties map[string]any, required []string) map[string]any {
+	sort.Strings(required)
+	return map[string]any{
+		"title":      title,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// fieldJSONSchema maps a field type to JSON Schema, allowing null for nullable fields
+func fieldJSONSchema(fieldType formatdef.Type, nullable bool, r *registry.Registry) map[string]any {
+	schema := typeJSONSchema(fieldType, r)
+	if !nullable {
+		return schema
+	}
+	return map[string]any{
+		"anyOf": []any{schema, map[string]any{"type": "null"}},
+	}
+}
+
+// typeJSONSchema maps a formatdef type to JSON Schema, referencing enums, structures and models
+func typeJSONSchema(fieldType formatdef.Type, r *registry.Registry) map[string]any {
+	switch t := fieldType.(type) {
+	case formatdef.ArrayType:
+		return map[string]any{"type": "array", "items": typeJSONSchema(t.ElementType, r)}
+	case formatdef.DictType:
+		return map[string]any{"type": "object", "additionalProperties": typeJSONSchema(t.ValueType, r)}
+	}
+
+	typeName := fieldType.GetName()
+	if basic, isBasic := basicTypeToJSONSchema[typeName]; isBasic {
+		schema := make(map[string]any, len(basic))
+		for key, value := range basic {
+			schema[key] = value
+		}
+		return schema
+	}
+	if _, isStructure := r.GetAllStructures()[typeName]; isStructure || resolveFieldType(typeName, r) != "basic" {
+		return map[string]any{"$ref": "#/$defs/" + typeName}
+	}
+	return map[string]any{}
+}
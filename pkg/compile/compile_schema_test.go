@@ -0,0 +1,98 @@
+package compile_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestGenerateJSONSchema() {
+	r := newStatusRegistry()
+	r.SetModel("Account", yaml.Model{
+		Name: "Account",
+		Fields: map[string]yaml.ModelField{
+			"ID":       {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Status":   {Type: "AccountStatus"},
+			"Nickname": {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional"}},
+			"Birthday": {Type: yaml.ModelFieldTypeDate},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content, err := compile.GenerateJSONSchema(config, r)
+
+	suite.Require().NoError(err)
+	suite.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"AccountStatus": {
+				"title": "AccountStatus",
+				"type": "string",
+				"enum": ["active", "disabled"]
+			},
+			"Account": {
+				"title": "Account",
+				"type": "object",
+				"properties": {
+					"birthday": {"type": "string", "format": "date"},
+					"id_": {"type": "integer"},
+					"nickname": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+					"status": {"$ref": "#/$defs/AccountStatus"}
+				},
+				"required": ["birthday", "id_", "status"]
+			}
+		}
+	}`, string(content))
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_JSONSchema() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.EmitJSONSchema = true
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Require().Contains(files, "schema.json")
+	var document struct {
+		Defs map[string]any `json:"$defs"`
+	}
+	suite.Require().NoError(json.Unmarshal([]byte(files["schema.json"]), &document))
+	suite.Contains(document.Defs, "Person")
+	suite.Contains(document.Defs, "Nationality")
+	suite.Contains(document.Defs, "Address")
+}
+
+func (suite *CompileTestSuite) TestGenerateJSONSchema_SkipsAbstractModels() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.AbstractModels = []string{"Animal"}
+	config.MorpheConfig.Models.BaseClasses = map[string]string{"Dog": "Animal"}
+
+	content, err := compile.GenerateJSONSchema(config, newAnimalRegistry())
+
+	suite.Require().NoError(err)
+	var document struct {
+		Defs map[string]any `json:"$defs"`
+	}
+	suite.Require().NoError(json.Unmarshal(content, &document))
+	suite.NotContains(document.Defs, "Animal")
+	suite.Contains(document.Defs, "Dog")
+}
+
+func (suite *CompileTestSuite) TestGenerateJSONSchema_RootStructures() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content, err := compile.GenerateJSONSchema(config, newRootStructureRegistry())
+
+	suite.Require().NoError(err)
+	var document struct {
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	suite.Require().NoError(json.Unmarshal(content, &document))
+	suite.JSONEq(`{"title": "Statuses", "type": "array", "items": {"$ref": "#/$defs/AccountStatus"}}`, string(document.Defs["Statuses"]))
+	suite.JSONEq(`{"title": "Scores", "type": "object", "additionalProperties": {"type": "integer"}}`, string(document.Defs["Scores"]))
+}
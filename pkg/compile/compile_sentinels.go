@@ -0,0 +1,82 @@
+package compile
+
+import "github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+
+// sentinelsModuleName is the package-root module holding shared sentinel values
+const sentinelsModuleName = "_sentinels"
+
+// generateSentinelContent generates the shared Unset sentinel used for tri-state fields
+func generateSentinelContent(config PydanticConfig) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+
+	cb.Line("from typing import Any")
+	if config.PydanticV2 {
+		cb.Line("")
+		cb.Line("from pydantic_core import core_schema")
+	}
+	cb.Line("")
+	cb.Line("")
+
+	cb.Line("class Unset:")
+	cb.Indent()
+	cb.Line(`"""Sentinel marking a field that was not provided, as opposed to an explicit None."""`)
+	cb.Line("")
+	cb.Line("_instance = None")
+	cb.Line("")
+	cb.Line(`def __new__(cls) -> "Unset":`)
+	cb.Indent()
+	cb.Line("if cls._instance is None:")
+	cb.Indent()
+	cb.Line("cls._instance = super().__new__(cls)")
+	cb.Dedent()
+	cb.Line("return cls._instance")
+	cb.Dedent()
+	cb.Line("")
+	cb.Line("def __bool__(self) -> bool:")
+	cb.Indent()
+	cb.Line("return False")
+	cb.Dedent()
+	cb.Line("")
+	cb.Line("def __repr__(self) -> str:")
+	cb.Indent()
+	cb.Line(`return "UNSET"`)
+	cb.Dedent()
+	cb.Line("")
+	if config.PydanticV2 {
+		cb.Line("@classmethod")
+		cb.Line("def __get_pydantic_core_schema__(cls, source: Any, handler: Any) -> Any:")
+		cb.Indent()
+		cb.Line("return core_schema.is_instance_schema(cls)")
+		cb.Dedent()
+	} else {
+		cb.Line("@classmethod")
+		cb.Line("def __get_validators__(cls) -> Any:")
+		cb.Indent()
+		cb.Line("yield cls.validate")
+		cb.Dedent()
+		cb.Line("")
+		cb.Line("@classmethod")
+		cb.Line(`def validate(cls, value: Any) -> "Unset":`)
+		cb.Indent()
+		cb.Line("if not isinstance(value, cls):")
+		cb.Indent()
+		cb.Line(`raise TypeError("value is not UNSET")`)
+		cb.Dedent()
+		cb.Line("return value")
+		cb.Dedent()
+	}
+	cb.Dedent()
+	cb.Line("")
+	cb.Line("")
+	cb.Line("UNSET = Unset()")
+
+	return cb.Build()
+}
+
+// unsetFieldType renders a tri-state type accepting the value, an explicit None, or Unset
+func unsetFieldType(fieldType string, config PydanticConfig) string {
+	if config.PythonVersionAtLeast(3, 10) {
+		return fieldType + " | None | Unset"
+	}
+	return "Union[" + fieldType + ", None, Unset]"
+}
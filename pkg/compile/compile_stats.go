@@ -0,0 +1,64 @@
+package compile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yamlops"
+)
+
+// CompileStats summarizes a compilation
+type CompileStats struct {
+	Enums                   int
+	Models                  int
+	ModelsWithRelationships int
+	Structures              int
+	Entities                int
+	PolymorphicResolved     int // Polymorphic relationships typed by their candidate models
+	PolymorphicFallbacks    int // Polymorphic relationships that fell back to Any
+	Files                   int
+	Bytes                   int
+}
+
+// addModels counts the compiled models, those with relationships and how their polymorphic
+// relationships were resolved
+func (stats *CompileStats) addModels(r *registry.Registry, config PydanticConfig) {
+	for _, model := range r.GetAllModels() {
+		stats.Models++
+		if len(model.Related) > 0 {
+			stats.ModelsWithRelationships++
+		}
+		for _, relation := range model.Related {
+			if !yamlops.IsRelationPoly(string(relation.Type)) {
+				continue
+			}
+			if _, err := polymorphicNavType(relation, r, config); err == nil {
+				stats.PolymorphicResolved++
+			} else {
+				stats.PolymorphicFallbacks++
+			}
+		}
+	}
+}
+
+// addFiles counts the generated files and their total size
+func (stats *CompileStats) addFiles(files map[string]string) {
+	for _, content := range files {
+		stats.Files++
+		stats.Bytes += len(content)
+	}
+}
+
+// String renders the statistics as the summary printed in verbose mode
+func (stats CompileStats) String() string {
+	var sb strings.Builder
+	sb.WriteString("Compilation summary:\n")
+	fmt.Fprintf(&sb, "  Enums:      %d\n", stats.Enums)
+	fmt.Fprintf(&sb, "  Models:     %d (%d with relationships)\n", stats.Models, stats.ModelsWithRelationships)
+	fmt.Fprintf(&sb, "  Structures: %d\n", stats.Structures)
+	fmt.Fprintf(&sb, "  Entities:   %d\n", stats.Entities)
+	fmt.Fprintf(&sb, "  Polymorphic relationships: %d resolved, %d fell back to Any\n", stats.PolymorphicResolved, stats.PolymorphicFallbacks)
+	fmt.Fprintf(&sb, "  Files:      %d (%d bytes)\n", stats.Files, stats.Bytes)
+	return sb.String()
+}
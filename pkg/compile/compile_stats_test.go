@@ -0,0 +1,105 @@
+package compile_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestCompileToMemoryWithStats() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "polymorphic"), "")
+
+	files, stats, err := compile.CompileToMemoryWithStats(config)
+
+	suite.Require().NoError(err)
+	totalBytes := 0
+	for _, content := range files {
+		totalBytes += len(content)
+	}
+	suite.Equal(compile.CompileStats{
+		Enums:                   1,
+		Models:                  4,
+		ModelsWithRelationships: 4,
+		PolymorphicResolved:     3,
+		Files:                   len(files),
+		Bytes:                   totalBytes,
+	}, stats)
+}
+
+func (suite *CompileTestSuite) TestCompileToMemoryWithStats_PolymorphicFallbacks() {
+	// Post has a polymorphic relationship with neither for candidates nor a through relationship
+	postRegistryPath := suite.T().TempDir()
+	suite.Require().NoError(os.Mkdir(filepath.Join(postRegistryPath, "models"), 0755))
+	post := "name: Post\nfields:\n  ID:\n    type: AutoIncrement\nidentifiers:\n  primary: ID\nrelated:\n  Attachment:\n    type: HasOnePoly\n"
+	suite.Require().NoError(os.WriteFile(filepath.Join(postRegistryPath, "models", "post.mod"), []byte(post), 0644))
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "polymorphic"), "", postRegistryPath)
+
+	_, stats, err := compile.CompileToMemoryWithStats(config)
+
+	suite.Require().NoError(err)
+	suite.Equal(5, stats.Models)
+	suite.Equal(3, stats.PolymorphicResolved)
+	suite.Equal(1, stats.PolymorphicFallbacks)
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_SilentWithoutVerbose() {
+	var output bytes.Buffer
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.Output = &output
+
+	_, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Empty(output.String())
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_VerboseProgress() {
+	var output bytes.Buffer
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.Verbose = true
+	config.Output = &output
+
+	_, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(output.String(), "Compiling enums...\n")
+	suite.Contains(output.String(), "Circular dependencies detected in models:\n")
+	suite.Contains(output.String(), "Compiling entities...\n")
+	suite.NotContains(output.String(), "Compilation summary")
+}
+
+func (suite *CompileTestSuite) TestMorpheToPydantic_VerboseSummary() {
+	var output bytes.Buffer
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), suite.T().TempDir())
+	config.Verbose = true
+	config.Output = &output
+
+	suite.Require().NoError(compile.MorpheToPydantic(config))
+
+	suite.Contains(output.String(), "Compilation summary:\n  Enums:      2\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStats_String() {
+	stats := compile.CompileStats{
+		Enums:                   2,
+		Models:                  3,
+		ModelsWithRelationships: 2,
+		Structures:              1,
+		Entities:                3,
+		PolymorphicResolved:     1,
+		PolymorphicFallbacks:    1,
+		Files:                   12,
+		Bytes:                   4096,
+	}
+
+	suite.Equal(`Compilation summary:
+  Enums:      2
+  Models:     3 (2 with relationships)
+  Structures: 1
+  Entities:   3
+  Polymorphic relationships: 1 resolved, 1 fell back to Any
+  Files:      12 (4096 bytes)
+`, stats.String())
+}
@@ -1,6 +1,7 @@
 package compile
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -13,6 +14,12 @@ import (
 
 // CompileStructure converts a Morphe structure to the target format
 func CompileStructure(structure yaml.Structure, r *registry.Registry) (*formatdef.Struct, error) {
+	return compileStructure(structure, r, PydanticConfig{})
+}
+
+// compileStructure converts a Morphe structure using the custom type mappings and strictness
+// of the format config
+func compileStructure(structure yaml.Structure, r *registry.Registry, config PydanticConfig) (*formatdef.Struct, error) {
 	// Create the struct definition
 	formatStruct := &formatdef.Struct{
 		Name:   structure.Name,
@@ -27,138 +34,443 @@ func CompileStructure(structure yaml.Structure, r *registry.Registry) (*formatde
 	sort.Strings(fieldNames)
 
 	// Add fields from the structure definition in sorted order
+	resolver := config.typeResolver()
 	for _, fieldName := range fieldNames {
 		field := structure.Fields[fieldName]
-		// Map field type to format type
-		fieldType, err := typemap.MorpheStructureFieldToFormatType(field.Type, fieldName, r)
-		if err != nil {
-			return nil, fmt.Errorf("failed to map field type for %s: %w", fieldName, err)
+		// Map field type to format type through the resolution sources, then structure composition
+		fieldType, resolved := resolver.Resolve(yaml.ModelFieldType(field.Type))
+		if !resolved {
+			mappedType, err := typemap.MorpheStructureFieldToFormatType(field.Type, fieldName, r, config.StrictTypes)
+			var unmappedErr *typemap.UnmappedTypeError
+			if errors.As(err, &unmappedErr) {
+				return nil, &UnmappedFieldTypeError{Owner: structure.Name, Field: fieldName, Type: unmappedErr.Type}
+			}
+			if err != nil {
+				return nil, &TypeMapError{Owner: structure.Name, Field: fieldName, Err: err}
+			}
+			fieldType = mappedType
+		}
+		fieldType = config.pydanticFieldType(fieldType)
+		if hasAttribute(field.Attributes, "list") {
+			fieldType = formatdef.ArrayType{ElementType: fieldType}
+		}
+		if hasAttribute(field.Attributes, "map") {
+			fieldType = formatdef.DictType{KeyType: formatdef.TypeString, ValueType: fieldType}
 		}
 
+		// A required self reference could never be constructed, so it is always nullable, and
+		// grouped fields are only required through their group validator
+		together := attributeValues(field.Attributes, "together")
+		oneOf := attributeValues(field.Attributes, "oneof")
 		formatField := formatdef.Field{
-			Name:       fieldName,
-			Type:       fieldType,
-			IsOptional: hasAttribute(field.Attributes, "optional"),
+			Name: fieldName,
+			Type: fieldType,
+			IsOptional: hasAttribute(field.Attributes, "optional") || isSelfReference(fieldType, structure.Name) ||
+				len(together) > 0 || len(oneOf) > 0,
+			IsClassVar: hasAttribute(field.Attributes, "classvar") || hasAttribute(field.Attributes, "const"),
+			IsComputed: hasAttribute(field.Attributes, "computed"),
+			IsRoot:     hasAttribute(field.Attributes, "root"),
+			Together:   together,
+			OneOf:      oneOf,
+		}
+		if description, ok := attributeValue(field.Attributes, "description"); ok {
+			formatField.Description = description
+		}
+		if defaultValue, hasDefault := attributeValue(field.Attributes, "default"); hasDefault {
+			if err := checkNumericAttribute(structure.Name, fieldName, "default", defaultValue, fieldType); err != nil {
+				return nil, err
+			}
+			formatField.Default = renderDefaultValue(defaultValue, fieldType)
 		}
 		formatStruct.Fields = append(formatStruct.Fields, formatField)
 	}
 
+	if rootField, isRoot := structureRootField(formatStruct); isRoot && len(formatStruct.Fields) > 1 {
+		return nil, &RootStructureFieldsError{Structure: structure.Name, Field: rootField.Name}
+	}
+	for _, group := range structureFieldGroups(formatStruct.Fields, config) {
+		if len(group.fieldNames) < 2 {
+			return nil, &FieldGroupTooSmallError{Structure: structure.Name, Group: group.name}
+		}
+	}
+
 	return formatStruct, nil
 }
 
 // CompileAllStructures compiles all structures and writes them using the writer
 func CompileAllStructures(config MorpheCompileConfig, r *registry.Registry, writer *MorpheWriter) error {
+	writer.useConfig(config.FormatConfig)
+
 	structureContents := make(map[string][]byte)
 
 	// Process each structure in the registry
 	for structureName, structure := range r.GetAllStructures() {
 		// Compile the structure
-		compiledStructure, err := CompileStructure(structure, r)
+		compiledStructure, err := compileStructure(structure, r, config.FormatConfig)
 		if err != nil {
 			return fmt.Errorf("failed to compile structure %s: %w", structureName, err)
 		}
 
-		// Generate the content for this structure
-		content := generateStructureContent(compiledStructure, config.FormatConfig)
+		// Python 3.9+ targets use the builtin list/dict generics
+		if config.FormatConfig.PythonVersionAtLeast(3, 9) {
+			compiledStructure.UseBuiltinGenerics()
+		}
+
+		// Generate the content for this structure; collection structures are always root models
+		if rootField, isRoot := structureRootField(compiledStructure); isRoot {
+			structureContents[structureName] = generateStructureRootContent(compiledStructure, rootField, config.FormatConfig, r)
+			continue
+		}
+		invariants := config.MorpheConfig.Structures.Invariants[structureName]
+		if config.MorpheConfig.Structures.UseDataclass {
+			structureContents[structureName] = generateStructureDataclassContent(compiledStructure, config.FormatConfig, invariants, r)
+			continue
+		}
+		content := generateStructureContent(compiledStructure, config.FormatConfig, invariants, r)
 		structureContents[structureName] = content
 	}
 
+	for structureName := range config.MorpheConfig.Structures.Invariants {
+		if _, exists := r.GetAllStructures()[structureName]; !exists {
+			return ErrStructureNotFound(structureName)
+		}
+	}
+
 	// Write all structure contents
 	return writer.WriteAllStructures(structureContents)
 }
 
 // generateStructureContent generates Python structure as a DTO with concrete fields
-func generateStructureContent(structure *formatdef.Struct, config PydanticConfig) []byte {
-	cb := formatdef.NewContentBuilder("    ")
+func generateStructureContent(structure *formatdef.Struct, config PydanticConfig, invariants []string, r *registry.Registry) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
 
 	// Add imports
-	if config.PydanticV2 {
-		cb.Line("from pydantic import BaseModel, Field")
-	} else {
-		cb.Line("from pydantic import BaseModel")
+	imports := NewImportTracker(r)
+	imports.SetFileNaming(config.FileNaming)
+	imports.AddPydantic("BaseModel")
+	// Fields aliased to their Morphe key stay populatable by name
+	populateByName := config.hasFieldKeyAliases(structure.Fields)
+	if config.PydanticV2 || hasMutableDefault(structure.Fields) || populateByName {
+		imports.AddPydantic("Field")
 	}
-
-	if config.AddTypeHints {
-		imports := []string{"Optional"}
-		hasDate := false
-		hasDict := false
-		hasList := false
-
-		// Check if we need additional imports
-		for _, field := range structure.Fields {
-			typeName := field.Type.GetName()
-			if typeName == "datetime" {
-				hasDate = true
-			} else if typeName == "Dict[str, Any]" {
-				hasDict = true
-			} else if len(typeName) > 5 && typeName[:5] == "List[" {
-				hasList = true
-			}
-		}
-
-		if hasDict {
-			imports = append(imports, "Dict", "Any")
-		}
-		if hasList {
-			imports = append(imports, "List")
-		}
-
-		if len(imports) > 0 {
-			cb.Line("from typing import %s", formatdef.FormatList(imports, ", "))
-		}
-
-		if hasDate {
-			cb.Line("from datetime import datetime")
+	awareDatetimeFields := awareDatetimeFieldNames(structure.Fields, config)
+	if len(awareDatetimeFields) > 0 {
+		imports.AddPydantic("validator")
+	}
+	fieldGroups := structureFieldGroups(structure.Fields, config)
+	if len(invariants) > 0 || len(fieldGroups) > 0 {
+		if config.PydanticV2 {
+			imports.AddPydantic("model_validator")
+		} else {
+			imports.AddPydantic("root_validator")
 		}
 	}
-
-	cb.Line("")
+	// Pydantic fields need their annotations, so structures keep them and their imports even
+	// without type hints
+	trackStructureImports(imports, structure, config)
+	imports.Generate(cb)
 	cb.Line("")
 
 	// Generate class
 	cb.Line("class %s(BaseModel):", structure.Name)
 	cb.Indent()
 
+	// Add fields
+	fields := structure.Fields
+	if config.SortRequiredFirst {
+		fields = requiredFieldsFirst(fields)
+	}
+
 	// Add docstring
-	cb.Line(`"""%s data transfer object."""`, structure.Name)
+	writeClassDocstring(cb, structure.Name+" data transfer object.", structureDocstringFields(fields, config, config.AddTypeHints), config.DocstringStyle)
 
-	// Add fields
-	for _, field := range structure.Fields {
-		fieldName := SanitizePythonIdentifier(formatdef.ToSnakeCase(field.Name))
-		fieldType := field.Type.GetName()
-		if field.IsOptional {
-			cb.Line("%s: Optional[%s] = None", fieldName, fieldType)
+	selfReferencing := false
+	for _, field := range fields {
+		fieldName := config.pythonFieldName(field.Name)
+		fieldType := structureFieldType(field, structure.Name)
+		selfReferencing = selfReferencing || isSelfReference(field.Type, structure.Name)
+		if field.IsClassVar {
+			// Class-level constants are not Pydantic fields
+			if field.Default != "" {
+				cb.Line("%s: ClassVar[%s] = %s", fieldName, fieldType, field.Default)
+			} else {
+				cb.Line("%s: ClassVar[%s]", fieldName, fieldType)
+			}
 		} else {
-			cb.Line("%s: %s", fieldName, fieldType)
+			// Fields keep their original Morphe key as the alias; mutable defaults are built per
+			// instance by a default factory
+			annotation, defaultValue := nullableAnnotation(field, fieldType)
+			kwargs := config.fieldKeyAliasKwargs(field)
+			if isMutableDefault(field.Default) {
+				defaultValue = ""
+				kwargs = append([]string{"default_factory=lambda: " + field.Default}, kwargs...)
+			} else if field.Default != "" {
+				defaultValue = field.Default
+			}
+			if value := fieldValue(defaultValue, kwargs); value != "" {
+				cb.Line("%s: %s = %s", fieldName, annotation, value)
+			} else {
+				cb.Line("%s: %s", fieldName, annotation)
+			}
 		}
 	}
 
-	// Add Pydantic config if using enums
+	writeAwareDatetimeValidator(cb, awareDatetimeFields)
+
+	// Add cross-field invariant validators
+	generateInvariantValidators(cb, structure, invariants, config)
+
+	// Add field group validators
+	writeFieldGroupValidators(cb, fieldGroups, config)
+
+	// Add Pydantic config if using enums or aliasing fields
+	var configOptions []modelConfigOption
 	if config.PydanticV2 {
-		needsConfig := false
 		for _, field := range structure.Fields {
-			if _, ok := field.Type.(formatdef.BasicType); ok {
+			if _, ok := field.Type.(formatdef.BasicType); ok && !isSelfReference(field.Type, structure.Name) {
 				typeName := field.Type.GetName()
 				// Check if it's an enum
 				if typeName != "str" && typeName != "int" && typeName != "float" && typeName != "bool" &&
-					typeName != "datetime" && typeName != "Dict[str, Any]" && !strings.Contains(typeName, "[") {
-					needsConfig = true
+					!isDatetimeType(typeName) && typeName != formatdef.TypeAwareDatetime.Name && !strings.Contains(typeName, "[") {
+					configOptions = append(configOptions, validateAssignmentOption, useEnumValuesOption)
 					break
 				}
 			}
 		}
+	}
+	if populateByName {
+		configOptions = append(configOptions, populateByNameOption)
+	}
+
+	if len(configOptions) > 0 && config.PydanticV2 {
+		cb.Line("")
+		cb.Line("model_config = {")
+		cb.Indent()
+		for _, option := range configOptions {
+			cb.Line(`"%s": True,`, option.V2Key)
+		}
+		cb.Dedent()
+		cb.Line("}")
+	} else if len(configOptions) > 0 {
+		cb.Line("")
+		cb.Line("class Config:")
+		cb.Indent()
+		for _, option := range configOptions {
+			cb.Line("%s = True", option.V1Key)
+		}
+		cb.Dedent()
+	}
+
+	cb.Dedent()
+
+	// Resolve the quoted self references once the class exists
+	if selfReferencing {
+		cb.Line("")
+		cb.Line("")
+		if config.PydanticV2 {
+			cb.Line("%s.model_rebuild()", structure.Name)
+		} else {
+			cb.Line("%s.update_forward_refs()", structure.Name)
+		}
+	}
+
+	return cb.Build()
+}
+
+// isSelfReference reports whether a field type refers to the structure declaring it, directly or
+// as the element of a list
+func isSelfReference(fieldType formatdef.Type, structureName string) bool {
+	switch t := fieldType.(type) {
+	case formatdef.ArrayType:
+		return isSelfReference(t.ElementType, structureName)
+	case formatdef.BasicType:
+		return t.Name == structureName
+	}
+	return false
+}
+
+// structureFieldType renders a structure field's type, quoting self references as forward
+// references since the class name is not bound until its body has been evaluated
+func structureFieldType(field formatdef.Field, structureName string) string {
+	if isSelfReference(field.Type, structureName) {
+		return quotedNavType(field.Type).GetName()
+	}
+	return field.Type.GetName()
+}
+
+// structureRootField returns the field marked "root" of a structure that is semantically a list
+// or map rather than an object with named fields
+func structureRootField(structure *formatdef.Struct) (formatdef.Field, bool) {
+	for _, field := range structure.Fields {
+		if field.IsRoot {
+			return field, true
+		}
+	}
+	return formatdef.Field{}, false
+}
+
+// generateStructureRootContent generates a collection structure as a RootModel[T] (Pydantic v2)
+// or a BaseModel with a __root__ field (v1)
+func generateStructureRootContent(structure *formatdef.Struct, rootField formatdef.Field, config PydanticConfig, r *registry.Registry) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+
+	rootType, _ := nullableAnnotation(rootField, structureFieldType(rootField, structure.Name))
 
-		if needsConfig {
-			cb.Line("")
-			cb.Line("model_config = {")
-			cb.Indent()
-			cb.Line(`"validate_assignment": True,`)
-			cb.Line(`"use_enum_values": True,`)
-			cb.Dedent()
-			cb.Line("}")
+	// Add imports
+	imports := NewImportTracker(r)
+	imports.SetFileNaming(config.FileNaming)
+	if config.PydanticV2 {
+		imports.AddPydantic("RootModel")
+	} else {
+		imports.AddPydantic("BaseModel")
+	}
+	imports.TrackFieldType(rootType)
+	imports.AddStatement(config.customTypeImports(rootType)...)
+	imports.Generate(cb)
+	cb.Line("")
+
+	summary := structure.Name + " data transfer object."
+	if rootField.Description != "" {
+		summary = strings.Join(docstringLines(rootField.Description), " ")
+	}
+	if config.PydanticV2 {
+		cb.Line("class %s(RootModel[%s]):", structure.Name, rootType)
+		cb.Indent()
+		writeClassDocstring(cb, summary, nil, config.DocstringStyle)
+	} else {
+		cb.Line("class %s(BaseModel):", structure.Name)
+		cb.Indent()
+		writeClassDocstring(cb, summary, nil, config.DocstringStyle)
+		cb.Line("__root__: %s", rootType)
+	}
+	cb.Dedent()
+
+	if isSelfReference(rootField.Type, structure.Name) {
+		cb.Line("")
+		cb.Line("")
+		if config.PydanticV2 {
+			cb.Line("%s.model_rebuild()", structure.Name)
+		} else {
+			cb.Line("%s.update_forward_refs()", structure.Name)
 		}
 	}
 
+	return cb.Build()
+}
+
+// requiredFieldsFirst returns the fields with required instance fields ahead of optional fields
+// and class variables, keeping the declared order within each group
+func requiredFieldsFirst(fields []formatdef.Field) []formatdef.Field {
+	sorted := append([]formatdef.Field{}, fields...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return isRequiredField(sorted[i]) && !isRequiredField(sorted[j])
+	})
+	return sorted
+}
+
+// isRequiredField reports whether a structure field must be passed to the constructor
+func isRequiredField(field formatdef.Field) bool {
+	return !field.IsOptional && !field.IsClassVar && field.Default == ""
+}
+
+// isMutableDefault reports whether a rendered default is a list or dict literal, which would be
+// shared by every instance if assigned directly
+func isMutableDefault(defaultValue string) bool {
+	return strings.HasPrefix(defaultValue, "[") || strings.HasPrefix(defaultValue, "{")
+}
+
+// hasMutableDefault reports whether any instance field of a structure has a mutable default
+func hasMutableDefault(fields []formatdef.Field) bool {
+	for _, field := range fields {
+		if !field.IsClassVar && isMutableDefault(field.Default) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultExpression renders the value assigned to a field with a default; mutable defaults are
+// built per instance by a default factory of the given field function (Field or field)
+func defaultExpression(defaultValue, fieldFunc string) string {
+	if isMutableDefault(defaultValue) {
+		return fmt.Sprintf("%s(default_factory=lambda: %s)", fieldFunc, defaultValue)
+	}
+	return defaultValue
+}
+
+// trackStructureImports tracks the imports used by a structure's field types and class variables
+func trackStructureImports(imports *ImportTracker, structure *formatdef.Struct, config PydanticConfig) {
+	imports.AddTyping("Optional")
+	for _, field := range structure.Fields {
+		if field.IsClassVar {
+			imports.AddTyping("ClassVar")
+		}
+		typeName := field.Type.GetName()
+		imports.TrackFieldType(typeName)
+		imports.AddStatement(config.customTypeImports(typeName)...)
+	}
+}
+
+// generateStructureDataclassContent generates a Python structure as a standard library dataclass.
+// Fields without defaults come first, since dataclass constructor arguments with defaults must
+// follow those without; computed fields are excluded from the constructor with field(init=False).
+// Invariants and field groups are checked by __post_init__.
+func generateStructureDataclassContent(structure *formatdef.Struct, config PydanticConfig, invariants []string, r *registry.Registry) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+
+	// Add imports
+	hasComputed := false
+	for _, field := range structure.Fields {
+		hasComputed = hasComputed || field.IsComputed
+	}
+	imports := NewImportTracker(r)
+	imports.SetFileNaming(config.FileNaming)
+	imports.AddFrom("dataclasses", "dataclass")
+	if hasComputed || hasMutableDefault(structure.Fields) {
+		imports.AddFrom("dataclasses", "field")
+	}
+	trackStructureImports(imports, structure, config)
+	imports.Generate(cb)
+	cb.Line("")
+
+	// Generate class
+	cb.Line("@dataclass")
+	cb.Line("class %s:", structure.Name)
+	cb.Indent()
+
+	// Add docstring
+	writeClassDocstring(cb, structure.Name+" data transfer object.", structureDocstringFields(requiredFieldsFirst(structure.Fields), config, true), config.DocstringStyle)
+
+	var required, defaulted []string
+	for _, structureField := range structure.Fields {
+		fieldName := config.pythonFieldName(structureField.Name)
+		fieldType := structureFieldType(structureField, structure.Name)
+		switch {
+		case structureField.IsClassVar && structureField.Default != "":
+			defaulted = append(defaulted, fmt.Sprintf("%s: ClassVar[%s] = %s", fieldName, fieldType, structureField.Default))
+		case structureField.IsClassVar:
+			defaulted = append(defaulted, fmt.Sprintf("%s: ClassVar[%s]", fieldName, fieldType))
+		case structureField.IsComputed && structureField.IsOptional:
+			defaulted = append(defaulted, fmt.Sprintf("%s: Optional[%s] = field(init=False, default=None)", fieldName, fieldType))
+		case structureField.IsComputed:
+			defaulted = append(defaulted, fmt.Sprintf("%s: %s = field(init=False)", fieldName, fieldType))
+		default:
+			annotation, defaultValue := nullableAnnotation(structureField, fieldType)
+			if structureField.Default != "" {
+				defaultValue = defaultExpression(structureField.Default, "field")
+			}
+			if defaultValue != "" {
+				defaulted = append(defaulted, fmt.Sprintf("%s: %s = %s", fieldName, annotation, defaultValue))
+			} else {
+				required = append(required, fmt.Sprintf("%s: %s", fieldName, annotation))
+			}
+		}
+	}
+	for _, line := range append(required, defaulted...) {
+		cb.Line("%s", line)
+	}
+
+	writeDataclassPostInit(cb, structure, invariants, structureFieldGroups(structure.Fields, config), config)
 	cb.Dedent()
 
 	return cb.Build()
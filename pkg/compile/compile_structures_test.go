@@ -0,0 +1,819 @@
+package compile_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestCompileStructure_ClassVar() {
+	r := registry.NewRegistry()
+	r.SetStructure("ApiEnvelope", yaml.Structure{
+		Name: "ApiEnvelope",
+		Fields: map[string]yaml.StructureField{
+			"Payload":    {Type: yaml.StructureFieldTypeString},
+			"Version":    {Type: yaml.StructureFieldTypeString, Attributes: []string{"classvar", "default:v1"}},
+			"MaxRetries": {Type: yaml.StructureFieldTypeInteger, Attributes: []string{"const", "default:3"}},
+			"Strict":     {Type: yaml.StructureFieldTypeBoolean, Attributes: []string{"classvar", "default:true"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, r, "structures/api_envelope.py")
+
+	suite.Contains(content, "from typing import ClassVar, Optional\n")
+	suite.Contains(content, "    max_retries: ClassVar[int] = 3\n")
+	suite.Contains(content, "    payload: str = Field(alias=\"Payload\")\n")
+	suite.Contains(content, "    strict: ClassVar[bool] = True\n")
+	suite.Contains(content, `    version: ClassVar[str] = "v1"`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_NumericDefaultNotANumber() {
+	r := registry.NewRegistry()
+	r.SetStructure("ApiEnvelope", yaml.Structure{
+		Name: "ApiEnvelope",
+		Fields: map[string]yaml.StructureField{
+			"MaxRetries": {Type: yaml.StructureFieldTypeInteger, Attributes: []string{"const", "default:3.5"}},
+		},
+	})
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllStructures(compile.DefaultMorpheCompileConfig("", ""), r, writer)
+
+	var valueErr *compile.AttributeValueError
+	suite.Require().ErrorAs(err, &valueErr)
+	suite.Equal("ApiEnvelope", valueErr.Owner)
+	suite.Equal("MaxRetries", valueErr.Field)
+	suite.ErrorContains(err, "invalid default:3.5 attribute on ApiEnvelope.MaxRetries: not a valid int literal")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_ClassVarNotImportedWhenUnused() {
+	r := registry.NewRegistry()
+	r.SetStructure("Point", yaml.Structure{
+		Name: "Point",
+		Fields: map[string]yaml.StructureField{
+			"X": {Type: yaml.StructureFieldTypeFloat},
+			"Y": {Type: yaml.StructureFieldTypeFloat},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, r, "structures/point.py")
+
+	suite.NotContains(content, "ClassVar")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DateAndTimeTypes() {
+	r := registry.NewRegistry()
+	r.SetStructure("Shift", yaml.Structure{
+		Name: "Shift",
+		Fields: map[string]yaml.StructureField{
+			"Day":   {Type: yaml.StructureFieldTypeDate},
+			"Start": {Type: yaml.StructureFieldTypeTime},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, r, "structures/shift.py")
+
+	suite.Contains(content, "from datetime import date, time\n")
+	suite.Contains(content, "    day: date = Field(alias=\"Day\")\n")
+	suite.Contains(content, "    start: time = Field(alias=\"Start\")\n")
+	suite.NotContains(content, "use_enum_values")
+}
+
+// newPeriodRegistry builds a registry with a Period structure bounded by a start and end
+func newPeriodRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("Period", yaml.Structure{
+		Name: "Period",
+		Fields: map[string]yaml.StructureField{
+			"Start":    {Type: yaml.StructureFieldTypeInteger},
+			"End":      {Type: yaml.StructureFieldTypeInteger},
+			"Capacity": {Type: yaml.StructureFieldTypeInteger},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_InvariantsPydanticV2() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.Invariants = map[string][]string{
+		"Period": {"Start < End", "Capacity >= 0", "sum(Start, End) is even"},
+	}
+
+	content := suite.generateSource(config, newPeriodRegistry(), "structures/period.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, model_validator\n")
+	suite.Contains(content, `
+    @model_validator(mode="after")
+    def check_invariant_1(self):
+        """Invariant: Start < End"""
+        if not (self.start < self.end):
+            raise ValueError("invariant violated: Start < End")
+        return self
+
+    @model_validator(mode="after")
+    def check_invariant_2(self):
+        """Invariant: Capacity >= 0"""
+        if not (self.capacity >= 0):
+            raise ValueError("invariant violated: Capacity >= 0")
+        return self
+
+    @model_validator(mode="after")
+    def check_invariant_3(self):
+        """Invariant: sum(Start, End) is even"""
+        # TODO: translate this invariant to Python and raise ValueError when it is violated
+        return self
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_InvariantsPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.MorpheConfig.Structures.Invariants = map[string][]string{"Period": {"Start < End"}}
+
+	content := suite.generateSource(config, newPeriodRegistry(), "structures/period.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, root_validator\n")
+	suite.Contains(content, `
+    @root_validator(skip_on_failure=True)
+    def check_invariant_1(cls, values):
+        """Invariant: Start < End"""
+        if not (values.get("start") < values.get("end")):
+            raise ValueError("invariant violated: Start < End")
+        return values
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_InvariantsFieldCaseAsIs() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FieldCase = compile.FieldCaseAsIs
+	config.MorpheConfig.Structures.Invariants = map[string][]string{"Period": {"Start < End"}}
+
+	content := suite.generateSource(config, newPeriodRegistry(), "structures/period.py")
+
+	suite.Contains(content, "    Capacity: int\n    End: int\n    Start: int\n")
+	suite.Contains(content, "        if not (self.Start < self.End):\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_InvariantsUnknownStructure() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.Invariants = map[string][]string{"Window": {"Start < End"}}
+
+	writer := compile.NewMorpheWriter(suite.T().TempDir())
+	err := compile.CompileAllStructures(config, newPeriodRegistry(), writer)
+
+	suite.EqualError(err, "structure not found: Window")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DataclassComputedField() {
+	r := registry.NewRegistry()
+	r.SetStructure("Invoice", yaml.Structure{
+		Name: "Invoice",
+		Fields: map[string]yaml.StructureField{
+			"Net":   {Type: yaml.StructureFieldTypeFloat},
+			"Note":  {Type: yaml.StructureFieldTypeString, Attributes: []string{"optional"}},
+			"Tax":   {Type: yaml.StructureFieldTypeFloat},
+			"Total": {Type: yaml.StructureFieldTypeFloat, Attributes: []string{"computed"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.UseDataclass = true
+
+	content := suite.generateSource(config, r, "structures/invoice.py")
+
+	suite.Equal(`# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from dataclasses import dataclass, field
+from typing import Optional
+
+
+@dataclass
+class Invoice:
+    """Invoice data transfer object."""
+    net: float
+    tax: float
+    note: Optional[str] = None
+    total: float = field(init=False)
+`, content)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_SortRequiredFirst() {
+	r := registry.NewRegistry()
+	r.SetStructure("Address", yaml.Structure{
+		Name: "Address",
+		Fields: map[string]yaml.StructureField{
+			"City":    {Type: yaml.StructureFieldTypeString},
+			"Country": {Type: yaml.StructureFieldTypeString, Attributes: []string{"classvar", "default:NZ"}},
+			"Line2":   {Type: yaml.StructureFieldTypeString, Attributes: []string{"optional"}},
+			"Street":  {Type: yaml.StructureFieldTypeString},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.SortRequiredFirst = true
+
+	content := suite.generateSource(config, r, "structures/address.py")
+
+	suite.Contains(content, `    city: str = Field(alias="City")
+    street: str = Field(alias="Street")
+    country: ClassVar[str] = "NZ"
+    line2: Optional[str] = Field(default=None, alias="Line2")
+`)
+}
+
+func newDescribedPointRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("Point", yaml.Structure{
+		Name: "Point",
+		Fields: map[string]yaml.StructureField{
+			"X": {Type: yaml.StructureFieldTypeFloat, Attributes: []string{"description:Horizontal offset"}},
+			"Y": {Type: yaml.StructureFieldTypeFloat},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DocstringStyleGoogle() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleGoogle
+
+	content := suite.generateSource(config, newDescribedPointRegistry(), "structures/point.py")
+
+	suite.Contains(content, `    """Point data transfer object.
+
+    Attributes:
+        x (float): Horizontal offset
+        y (float)
+    """
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DocstringStyleNumPy() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleNumPy
+
+	content := suite.generateSource(config, newDescribedPointRegistry(), "structures/point.py")
+
+	suite.Contains(content, `    """Point data transfer object.
+
+    Attributes
+    ----------
+    x : float
+        Horizontal offset
+    y : float
+    """
+`)
+}
+
+// newLongDescriptionRegistry builds a structure whose field descriptions need wrapping, span
+// several lines or contain characters to escape
+func newLongDescriptionRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("Point", yaml.Structure{
+		Name: "Point",
+		Fields: map[string]yaml.StructureField{
+			"X": {Type: yaml.StructureFieldTypeFloat, Attributes: []string{"description:Horizontal offset from the origin of the canvas, measured in device independent pixels"}},
+			"Y": {Type: yaml.StructureFieldTypeFloat, Attributes: []string{"description:Vertical offset\nGrows downwards"}},
+			"Z": {Type: yaml.StructureFieldTypeFloat, Attributes: []string{`description:Depth, never """quoted""" in C:\layers`}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DocstringStyleGoogleWrapsDescriptions() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleGoogle
+
+	content := suite.generateSource(config, newLongDescriptionRegistry(), "structures/point.py")
+
+	suite.Contains(content, `    """Point data transfer object.
+
+    Attributes:
+        x (float): Horizontal offset from the origin of the canvas, measured in device
+            independent pixels
+        y (float): Vertical offset
+            Grows downwards
+        z (float): Depth, never \"\"\"quoted\"\"\" in C:\\layers
+    """
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DocstringStyleNumPyWrapsDescriptions() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleNumPy
+
+	content := suite.generateSource(config, newLongDescriptionRegistry(), "structures/point.py")
+
+	suite.Contains(content, `    x : float
+        Horizontal offset from the origin of the canvas, measured in device independent
+        pixels
+    y : float
+        Vertical offset
+        Grows downwards
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DocstringDescriptionsUnwrapped() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DocstringStyle = compile.DocstringStyleGoogle
+	config.FormatConfig.MaxLineLength = 0
+
+	content := suite.generateSource(config, newLongDescriptionRegistry(), "structures/point.py")
+
+	suite.Contains(content, "        x (float): Horizontal offset from the origin of the canvas, measured in device independent pixels\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DocstringStylePlainByDefault() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newDescribedPointRegistry(), "structures/point.py")
+
+	suite.Contains(content, `    """Point data transfer object."""`+"\n")
+	suite.NotContains(content, "Attributes")
+}
+
+func newMutableDefaultsRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("SearchQuery", yaml.Structure{
+		Name: "SearchQuery",
+		Fields: map[string]yaml.StructureField{
+			"Term":    {Type: yaml.StructureFieldTypeString},
+			"Limit":   {Type: yaml.StructureFieldTypeInteger, Attributes: []string{"default:20"}},
+			"Tags":    {Type: yaml.StructureFieldType("Tags"), Attributes: []string{"default:[]"}},
+			"Filters": {Type: yaml.StructureFieldType("Filters"), Attributes: []string{`default:{"archived": False}`}},
+		},
+	})
+	return r
+}
+
+func newMutableDefaultsConfig() compile.MorpheCompileConfig {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"Tags":    {Type: "list"},
+		"Filters": {Type: "dict"},
+	}
+	return config
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_MutableDefaults() {
+	content := suite.generateSource(newMutableDefaultsConfig(), newMutableDefaultsRegistry(), "structures/search_query.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    tags: list = Field(default_factory=lambda: [], alias=\"Tags\")\n")
+	suite.Contains(content, `    filters: dict = Field(default_factory=lambda: {"archived": False}, alias="Filters")`+"\n")
+	suite.Contains(content, "    limit: int = Field(default=20, alias=\"Limit\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_MutableDefaultsPydanticV1() {
+	config := newMutableDefaultsConfig()
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newMutableDefaultsRegistry(), "structures/search_query.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    tags: list = Field(default_factory=lambda: [], alias=\"Tags\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DataclassMutableDefaults() {
+	config := newMutableDefaultsConfig()
+	config.MorpheConfig.Structures.UseDataclass = true
+
+	content := suite.generateSource(config, newMutableDefaultsRegistry(), "structures/search_query.py")
+
+	suite.Contains(content, "from dataclasses import dataclass, field\n")
+	suite.Contains(content, "    term: str\n")
+	suite.Contains(content, "    tags: list = field(default_factory=lambda: [])\n")
+}
+
+func newPayloadRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("Webhook", yaml.Structure{
+		Name: "Webhook",
+		Fields: map[string]yaml.StructureField{
+			"Event":   {Type: yaml.StructureFieldTypeString},
+			"Payload": {Type: yaml.StructureFieldType("JSON")},
+		},
+	})
+	r.SetModel("AuditLog", yaml.Model{
+		Name: "AuditLog",
+		Fields: map[string]yaml.ModelField{
+			"ID":      {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Payload": {Type: yaml.ModelFieldType("JSON")},
+		},
+		Identifiers: map[string]yaml.ModelIdentifier{
+			"primary": {Fields: []string{"ID"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_JSONField() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	r := newPayloadRegistry()
+
+	structure := suite.generateSource(config, r, "structures/webhook.py")
+	model := suite.generateSource(config, r, "models/audit_log.py")
+
+	suite.Contains(structure, "from typing import Any, Dict, Optional\n")
+	suite.Contains(structure, "    payload: Dict[str, Any] = Field(alias=\"Payload\")\n")
+	suite.Contains(model, "from typing import Any, Dict, Optional\n")
+	suite.Contains(model, "    payload: Dict[str, Any] = Field(alias=\"Payload\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_JSONValueField() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.JSONType = compile.JSONTypeJSONValue
+	r := newPayloadRegistry()
+
+	structure := suite.generateSource(config, r, "structures/webhook.py")
+	model := suite.generateSource(config, r, "models/audit_log.py")
+
+	suite.Contains(structure, "from pydantic import BaseModel, Field, JsonValue\n")
+	suite.Contains(structure, "    payload: JsonValue = Field(alias=\"Payload\")\n")
+	suite.NotContains(structure, "Dict")
+	suite.Contains(model, "from pydantic import BaseModel, Field, JsonValue\n")
+	suite.Contains(model, "    payload: JsonValue = Field(alias=\"Payload\")\n")
+}
+
+func (suite *CompileTestSuite) TestValidate_JSONValueRequiresPydanticV2() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+	config.FormatConfig.JSONType = compile.JSONTypeJSONValue
+
+	suite.EqualError(config.Validate(), "invalid jsonType: jsonvalue requires Pydantic v2")
+}
+
+func newStatusChangeRegistry() *registry.Registry {
+	r := newStatusRegistry()
+	r.SetStructure("StatusChange", yaml.Structure{
+		Name: "StatusChange",
+		Fields: map[string]yaml.StructureField{
+			"Reason": {Type: yaml.StructureFieldTypeString},
+			"Status": {Type: yaml.StructureFieldType("AccountStatus")},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_EnumFieldImport() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newStatusChangeRegistry(), "structures/status_change.py")
+
+	suite.Contains(content, `from typing import Optional
+
+from pydantic import BaseModel, Field
+
+from ..enums.account_status import AccountStatus
+
+
+class StatusChange(BaseModel):
+`)
+	suite.Contains(content, "    status: AccountStatus = Field(alias=\"Status\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_EnumFieldImportWithoutTypeHints() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.AddTypeHints = false
+
+	content := suite.generateSource(config, newStatusChangeRegistry(), "structures/status_change.py")
+
+	suite.Contains(content, "from ..enums.account_status import AccountStatus\n")
+	suite.Contains(content, "    status: AccountStatus = Field(alias=\"Status\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_EnumListFieldImport() {
+	r := newStatusChangeRegistry()
+	r.SetStructure("StatusHistory", yaml.Structure{
+		Name: "StatusHistory",
+		Fields: map[string]yaml.StructureField{
+			"Statuses": {Type: yaml.StructureFieldType("AccountStatus"), Attributes: []string{"list"}},
+		},
+	})
+
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), r, "structures/status_history.py")
+
+	suite.Contains(content, "from ..enums.account_status import AccountStatus\n")
+	suite.Contains(content, "    statuses: List[AccountStatus] = Field(alias=\"Statuses\")\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DataclassEnumFieldImport() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.UseDataclass = true
+
+	content := suite.generateSource(config, newStatusChangeRegistry(), "structures/status_change.py")
+
+	suite.Contains(content, "from dataclasses import dataclass\nfrom typing import Optional\n\nfrom ..enums.account_status import AccountStatus\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_OptionalMatchesModel() {
+	r := newProfileRegistry()
+	r.SetStructure("ProfileInput", yaml.Structure{
+		Name: "ProfileInput",
+		Fields: map[string]yaml.StructureField{
+			"ExternalID": {Type: yaml.StructureFieldTypeString},
+			"Nickname":   {Type: yaml.StructureFieldTypeString, Attributes: []string{"optional"}},
+		},
+	})
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	model := suite.generateSource(config, r, "models/profile.py")
+	structure := suite.generateSource(config, r, "structures/profile_input.py")
+
+	suite.Contains(model, "    nickname: Optional[str] = Field(default=None, alias=\"Nickname\")\n")
+	suite.Contains(structure, "    nickname: Optional[str] = Field(default=None, alias=\"Nickname\")\n")
+	suite.Contains(structure, "    external_id: str = Field(alias=\"ExternalID\")\n")
+}
+func (suite *CompileTestSuite) TestCompileStructure_FieldKeyAliasesPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newPeriodRegistry(), "structures/period.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field\n")
+	suite.Contains(content, "    start: int = Field(alias=\"Start\")\n")
+	suite.Contains(content, `
+    class Config:
+        allow_population_by_field_name = True
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_FieldKeyAliasesDisabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DisableFieldKeyAliases = true
+
+	content := suite.generateSource(config, newPeriodRegistry(), "structures/period.py")
+
+	suite.Contains(content, "    start: int\n")
+	suite.NotContains(content, "alias=")
+	suite.NotContains(content, "model_config")
+}
+
+func newTreeNodeRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("TreeNode", yaml.Structure{
+		Name: "TreeNode",
+		Fields: map[string]yaml.StructureField{
+			"Children": {Type: yaml.StructureFieldType("TreeNode"), Attributes: []string{"list"}},
+			"Label":    {Type: yaml.StructureFieldTypeString},
+			"Parent":   {Type: yaml.StructureFieldType("TreeNode")},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_SelfReference() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newTreeNodeRegistry(), "structures/tree_node.py")
+
+	suite.Contains(content, "    parent: Optional[\"TreeNode\"] = Field(default=None, alias=\"Parent\")\n")
+	suite.NotContains(content, "import TreeNode")
+	suite.NotContains(content, "use_enum_values")
+	suite.True(strings.HasSuffix(content, "\n\n\nTreeNode.model_rebuild()\n"))
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_SelfReferenceList() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newTreeNodeRegistry(), "structures/tree_node.py")
+
+	suite.Contains(content, "    children: Optional[List[\"TreeNode\"]] = Field(default=None, alias=\"Children\")\n")
+	suite.Contains(content, "from typing import List, Optional\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_SelfReferencePydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newTreeNodeRegistry(), "structures/tree_node.py")
+
+	suite.True(strings.HasSuffix(content, "\n\n\nTreeNode.update_forward_refs()\n"))
+	suite.NotContains(content, "model_rebuild")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DataclassSelfReference() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.UseDataclass = true
+
+	content := suite.generateSource(config, newTreeNodeRegistry(), "structures/tree_node.py")
+
+	suite.Contains(content, "    label: str\n    children: Optional[List[\"TreeNode\"]] = None\n    parent: Optional[\"TreeNode\"] = None\n")
+	suite.NotContains(content, "model_rebuild")
+}
+
+// newPaymentRegistry builds a structure with an all-or-none card group and an exactly-one
+// payment method group
+func newPaymentRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("Payment", yaml.Structure{
+		Name: "Payment",
+		Fields: map[string]yaml.StructureField{
+			"Amount":      {Type: yaml.StructureFieldTypeFloat},
+			"CardNumber":  {Type: yaml.StructureFieldTypeString, Attributes: []string{"together:card", "oneof:method"}},
+			"CardExpiry":  {Type: yaml.StructureFieldTypeString, Attributes: []string{"together:card"}},
+			"IbanAccount": {Type: yaml.StructureFieldTypeString, Attributes: []string{"oneof:method"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_FieldGroupsPydanticV2() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+
+	content := suite.generateSource(config, newPaymentRegistry(), "structures/payment.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, model_validator\n")
+	suite.Contains(content, "    card_expiry: Optional[str] = Field(default=None, alias=\"CardExpiry\")\n")
+	suite.Contains(content, `
+    @model_validator(mode="after")
+    def check_card_together(self):
+        """Field group card: must be provided together or not at all."""
+        provided = [
+            name for name in ("card_expiry", "card_number") if getattr(self, name) is not None
+        ]
+        if provided and len(provided) != 2:
+            raise ValueError("card_expiry and card_number must be provided together")
+        return self
+
+    @model_validator(mode="after")
+    def check_method_one_of(self):
+        """Field group method: exactly one must be provided."""
+        provided = [
+            name for name in ("card_number", "iban_account") if getattr(self, name) is not None
+        ]
+        if len(provided) != 1:
+            raise ValueError(
+                "exactly one of card_number or iban_account must be provided"
+            )
+        return self
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_FieldGroupsPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newPaymentRegistry(), "structures/payment.py")
+
+	suite.Contains(content, "from pydantic import BaseModel, Field, root_validator\n")
+	suite.Contains(content, `
+    @root_validator(skip_on_failure=True)
+    def check_method_one_of(cls, values):
+        """Field group method: exactly one must be provided."""
+        provided = [
+            name for name in ("card_number", "iban_account") if values.get(name) is not None
+        ]
+        if len(provided) != 1:
+            raise ValueError(
+                "exactly one of card_number or iban_account must be provided"
+            )
+        return values
+`)
+}
+func (suite *CompileTestSuite) TestCompileStructure_DataclassFieldGroups() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.UseDataclass = true
+	config.MorpheConfig.Structures.Invariants = map[string][]string{"Payment": {"Amount > 0"}}
+
+	content := suite.generateSource(config, newPaymentRegistry(), "structures/payment.py")
+
+	suite.NotContains(content, "from pydantic")
+	suite.Contains(content, `
+    def __post_init__(self):
+        """Check the invariants and field groups of the structure."""
+        # Invariant: Amount > 0
+        if not (self.amount > 0):
+            raise ValueError("invariant violated: Amount > 0")
+        # Field group card
+        provided = [
+            name for name in ("card_expiry", "card_number") if getattr(self, name) is not None
+        ]
+        if provided and len(provided) != 2:
+            raise ValueError("card_expiry and card_number must be provided together")
+        # Field group method
+        provided = [
+            name for name in ("card_number", "iban_account") if getattr(self, name) is not None
+        ]
+        if len(provided) != 1:
+            raise ValueError(
+                "exactly one of card_number or iban_account must be provided"
+            )
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DataclassWithoutChecks() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Structures.UseDataclass = true
+
+	content := suite.generateSource(config, newPeriodRegistry(), "structures/period.py")
+
+	suite.NotContains(content, "__post_init__")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_FieldGroupTooSmall() {
+	r := registry.NewRegistry()
+	r.SetStructure("Payment", yaml.Structure{
+		Name: "Payment",
+		Fields: map[string]yaml.StructureField{
+			"Amount":     {Type: yaml.StructureFieldTypeFloat},
+			"CardNumber": {Type: yaml.StructureFieldTypeString, Attributes: []string{"oneof:method"}},
+		},
+	})
+
+	_, err := compile.CompileStructure(r.GetAllStructures()["Payment"], r)
+
+	var groupErr *compile.FieldGroupTooSmallError
+	suite.Require().True(errors.As(err, &groupErr))
+	suite.Equal("Payment", groupErr.Structure)
+	suite.Equal("method", groupErr.Group)
+	suite.ErrorContains(err, "structure Payment field group method needs at least two fields")
+}
+
+// newRootStructureRegistry builds a list-root Statuses structure of AccountStatus values and a
+// dict-root Scores structure of integers keyed by name
+func newRootStructureRegistry() *registry.Registry {
+	r := newStatusRegistry()
+	r.SetStructure("Statuses", yaml.Structure{
+		Name: "Statuses",
+		Fields: map[string]yaml.StructureField{
+			"Items": {Type: yaml.StructureFieldType("AccountStatus"), Attributes: []string{"root", "list"}},
+		},
+	})
+	r.SetStructure("Scores", yaml.Structure{
+		Name: "Scores",
+		Fields: map[string]yaml.StructureField{
+			"Entries": {Type: yaml.StructureFieldTypeInteger, Attributes: []string{"root", "map", "description:Scores by player name"}},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_ListRoot() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newRootStructureRegistry(), "structures/statuses.py")
+
+	suite.Contains(content, `from typing import List
+
+from pydantic import RootModel
+
+from ..enums.account_status import AccountStatus
+
+
+class Statuses(RootModel[List[AccountStatus]]):
+    """Statuses data transfer object."""
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_ListRootPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newRootStructureRegistry(), "structures/statuses.py")
+
+	suite.Contains(content, "from pydantic import BaseModel\n")
+	suite.Contains(content, `
+class Statuses(BaseModel):
+    """Statuses data transfer object."""
+    __root__: List[AccountStatus]
+`)
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DictRoot() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PythonVersion = "3.9"
+
+	content := suite.generateSource(config, newRootStructureRegistry(), "structures/scores.py")
+
+	suite.Contains(content, `from pydantic import RootModel
+
+
+class Scores(RootModel[dict[str, int]]):
+    """Scores by player name"""
+`)
+	suite.NotContains(content, "typing")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_DictRootPydanticV1() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.PydanticV2 = false
+
+	content := suite.generateSource(config, newRootStructureRegistry(), "structures/scores.py")
+
+	suite.Contains(content, "from typing import Dict\n\nfrom pydantic import BaseModel\n")
+	suite.Contains(content, "    __root__: Dict[str, int]\n")
+}
+
+func (suite *CompileTestSuite) TestCompileStructure_RootWithOtherFields() {
+	r := registry.NewRegistry()
+	r.SetStructure("Statuses", yaml.Structure{
+		Name: "Statuses",
+		Fields: map[string]yaml.StructureField{
+			"Items": {Type: yaml.StructureFieldTypeString, Attributes: []string{"root", "list"}},
+			"Total": {Type: yaml.StructureFieldTypeInteger},
+		},
+	})
+
+	_, err := compile.CompileStructure(r.GetAllStructures()["Statuses"], r)
+
+	var rootErr *compile.RootStructureFieldsError
+	suite.Require().True(errors.As(err, &rootErr))
+	suite.Equal("Statuses", rootErr.Structure)
+	suite.Equal("Items", rootErr.Field)
+	suite.ErrorContains(err, "structure Statuses root field Items must be its only field")
+}
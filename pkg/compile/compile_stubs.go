@@ -0,0 +1,65 @@
+package compile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// generateModelStubContent generates a .pyi stub for a model with an explicit keyword-only
+// __init__ signature, so editors see precise constructor parameters
+func generateModelStubContent(model *formatdef.Struct, config PydanticConfig, morpheConfig cfg.MorpheConfig, r *registry.Registry) []byte {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+
+	fieldDecls, counts := modelFieldDecls(model, config, morpheConfig)
+
+	// Stubs are always typed; untyped fields fall back to Any
+	imports := NewImportTracker(r)
+	imports.SetFileNaming(config.FileNaming)
+	imports.SetCurrentModel(model.Name)
+	baseClass := addBaseClassImport(imports, model.Name, morpheConfig.Models, config.FileNaming)
+	for i, decl := range fieldDecls {
+		if decl.Annotation == "" {
+			fieldDecls[i].Annotation = "Any"
+		}
+		imports.TrackFieldType(fieldDecls[i].Annotation)
+		imports.AddStatement(config.customTypeImports(fieldDecls[i].Annotation)...)
+		if strings.Contains(fieldDecls[i].Annotation, "Unset") {
+			imports.AddFrom(".."+sentinelsModuleName, "Unset")
+		}
+	}
+
+	imports.Generate(cb)
+	cb.Line("")
+
+	cb.Line("class %s(%s):", model.Name, baseClass)
+	cb.Indent()
+
+	params := []string{"self"}
+	if len(fieldDecls) > 0 {
+		params = append(params, "*")
+	}
+	for _, decl := range fieldDecls {
+		cb.Line("%s: %s", decl.Name, decl.Annotation)
+		param := fmt.Sprintf("%s: %s", decl.Name, decl.Annotation)
+		if !decl.Required() {
+			param += " = ..."
+		}
+		params = append(params, param)
+	}
+
+	cb.Line("")
+	cb.Line("def __init__(%s) -> None: ...", strings.Join(params, ", "))
+
+	for _, count := range counts {
+		cb.Line("")
+		cb.Line("@property")
+		cb.Line("def %s(self) -> int: ...", count.Property)
+	}
+	cb.Dedent()
+
+	return cb.Build()
+}
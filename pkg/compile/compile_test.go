@@ -46,6 +46,31 @@ func (suite *CompileTestSuite) TearDownTest() {
 	suite.TestDirPath = ""
 }
 
+// generateSource compiles an in-memory registry into a temporary directory and returns
+// the content of the generated file at relPath
+func (suite *CompileTestSuite) generateSource(config compile.MorpheCompileConfig, r *registry.Registry, relPath string) string {
+	outputDirPath := suite.T().TempDir()
+	config.OutputPath = outputDirPath
+	writer := compile.NewMorpheWriter(outputDirPath)
+
+	if r.HasEnums() {
+		suite.Require().NoError(compile.CompileAllEnums(config, r, writer))
+	}
+	if r.HasModels() {
+		suite.Require().NoError(compile.CompileAllModels(config, r, writer))
+	}
+	if r.HasStructures() {
+		suite.Require().NoError(compile.CompileAllStructures(config, r, writer))
+	}
+	if r.HasEntities() {
+		suite.Require().NoError(compile.CompileAllEntities(config, r, writer))
+	}
+
+	content, readErr := os.ReadFile(filepath.Join(outputDirPath, relPath))
+	suite.Require().NoError(readErr)
+	return string(content)
+}
+
 func (suite *CompileTestSuite) TestMorpheToPydantic() {
 	workingDirPath := suite.TestDirPath + "/working"
 	suite.Nil(os.Mkdir(workingDirPath, 0755))
@@ -161,6 +186,36 @@ func (suite *CompileTestSuite) TestMorpheToPydantic() {
 	suite.FileEquals(entityPath1, gtEntityPath1)
 }
 
+// TestMorpheToPydantic_Polymorphic compiles the polymorphic registry and compares every
+// generated file against its Black-formatted ground truth.
+func (suite *CompileTestSuite) TestMorpheToPydantic_Polymorphic() {
+	workingDirPath := suite.TestDirPath + "/working-polymorphic"
+	suite.Nil(os.Mkdir(workingDirPath, 0755))
+	defer os.RemoveAll(workingDirPath)
+
+	registryPath := filepath.Join(suite.TestDirPath, "registry", "polymorphic")
+	gtDirPath := filepath.Join(suite.TestDirPath, "ground-truth", "compile-polymorphic")
+	config := compile.DefaultMorpheCompileConfig(registryPath, workingDirPath)
+
+	compileErr := compile.MorpheToPydantic(config)
+	suite.NoError(compileErr)
+
+	walkErr := filepath.Walk(gtDirPath, func(gtPath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, relErr := filepath.Rel(gtDirPath, gtPath)
+		if relErr != nil {
+			return relErr
+		}
+		genPath := filepath.Join(workingDirPath, relPath)
+		suite.FileExists(genPath)
+		suite.FileEquals(genPath, gtPath)
+		return nil
+	})
+	suite.NoError(walkErr)
+}
+
 // TestGroundTruthRegeneration ensures ground truth can be regenerated consistently
 func (suite *CompileTestSuite) TestGroundTruthRegeneration() {
 	// This test verifies that the ground truth files match current generation
@@ -309,3 +364,130 @@ func (suite *CompileTestSuite) TestCompileStructure_StructureComposition() {
 	suite.True(ok, "LineItem type should be BasicType (structure reference)")
 	suite.Equal("InvoiceLineItem", lineItemType.Name)
 }
+
+// TestMorpheToPydantic_MultipleRegistries verifies that registries split across several roots
+// are merged before compilation and produce the same output as a single combined registry.
+func (suite *CompileTestSuite) TestMorpheToPydantic_MultipleRegistries() {
+	workingDirPath := suite.TestDirPath + "/working-multi"
+	suite.Nil(os.Mkdir(workingDirPath, 0755))
+	defer os.RemoveAll(workingDirPath)
+
+	sharedRegistryPath := filepath.Join(suite.TestDirPath, "registry", "multi", "shared")
+	serviceRegistryPath := filepath.Join(suite.TestDirPath, "registry", "multi", "service")
+	config := compile.DefaultMorpheCompileConfig(sharedRegistryPath, workingDirPath, serviceRegistryPath)
+
+	compileErr := compile.MorpheToPydantic(config)
+	suite.NoError(compileErr)
+
+	suite.FileEquals(workingDirPath+"/enums/nationality.py", suite.TestGroundTruthDirPath+"/enums/nationality.py")
+	suite.FileEquals(workingDirPath+"/structures/address.py", suite.TestGroundTruthDirPath+"/structures/address.py")
+	suite.FileEquals(workingDirPath+"/models/person.py", suite.TestGroundTruthDirPath+"/models/person.py")
+	suite.FileEquals(workingDirPath+"/entities/person.py", suite.TestGroundTruthDirPath+"/entities/person.py")
+}
+
+// TestMorpheToPydantic_MultipleRegistriesDuplicateName verifies that a type declared in more than
+// one registry root is rejected instead of silently overwritten.
+func (suite *CompileTestSuite) TestMorpheToPydantic_MultipleRegistriesDuplicateName() {
+	workingDirPath := suite.TestDirPath + "/working-multi-duplicate"
+	suite.Nil(os.Mkdir(workingDirPath, 0755))
+	defer os.RemoveAll(workingDirPath)
+
+	minimalRegistryPath := filepath.Join(suite.TestDirPath, "registry", "minimal")
+	sharedRegistryPath := filepath.Join(suite.TestDirPath, "registry", "multi", "shared")
+	config := compile.DefaultMorpheCompileConfig(minimalRegistryPath, workingDirPath, sharedRegistryPath)
+
+	compileErr := compile.MorpheToPydantic(config)
+	suite.Error(compileErr)
+	suite.Contains(compileErr.Error(), "already exists in registry")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files, "models/__init__.py")
+	suite.Contains(files, "entities/person.py")
+	suite.Equal("", files["py.typed"])
+	for relPath, content := range files {
+		expected, readErr := os.ReadFile(filepath.Join(suite.TestGroundTruthDirPath, filepath.FromSlash(relPath)))
+		suite.Require().NoError(readErr, relPath)
+		suite.Equal(string(expected), content, relPath)
+	}
+}
+
+func (suite *CompileTestSuite) TestMorpheToPydantic_PyTyped() {
+	workingDirPath := suite.T().TempDir()
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), workingDirPath)
+
+	suite.Require().NoError(compile.MorpheToPydantic(config))
+
+	suite.FileExists(filepath.Join(workingDirPath, "py.typed"))
+	suite.FileExists(filepath.Join(workingDirPath, "models", "__init__.py"))
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_PyTypedDisabled() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.EmitPyTyped = false
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.NotContains(files, "py.typed")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_OnlyWithDependencies() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.Only = []string{"ContactInfo"}
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	for _, relPath := range []string{"models/contact_info.py", "models/person.py", "models/company.py", "enums/nationality.py"} {
+		suite.Require().Contains(files, relPath)
+		expected, readErr := os.ReadFile(filepath.Join(suite.TestGroundTruthDirPath, filepath.FromSlash(relPath)))
+		suite.Require().NoError(readErr, relPath)
+		suite.Equal(string(expected), files[relPath], relPath)
+	}
+	suite.NotContains(files, "enums/universal_number.py")
+	suite.NotContains(files, "structures/address.py")
+	suite.NotContains(files, "entities/person.py")
+	suite.NotContains(files, "entities/company.py")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_OnlyEnumAndStructure() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.Only = []string{"Nationality", "Address"}
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files, "enums/nationality.py")
+	suite.Contains(files, "structures/address.py")
+	suite.NotContains(files, "enums/universal_number.py")
+	suite.NotContains(files, "models/person.py")
+	suite.NotContains(files, "entities/person.py")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_OnlyEntityWithRelatedEntities() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.Only = []string{"Person"}
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files, "entities/person.py")
+	suite.Contains(files, "entities/company.py")
+	suite.Contains(files, "models/contact_info.py")
+	suite.NotContains(files, "structures/address.py")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_OnlyUnknownType() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.Only = []string{"Invoice"}
+
+	_, err := compile.CompileToMemory(config)
+
+	suite.ErrorContains(err, "type not found: Invoice")
+}
@@ -0,0 +1,147 @@
+package compile
+
+import (
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// Class docstring styles
+const (
+	DocstringStylePlain  = "plain"
+	DocstringStyleGoogle = "google"
+	DocstringStyleNumPy  = "numpy"
+)
+
+// docstringField is an attribute listed in a class docstring
+type docstringField struct {
+	Name        string
+	Type        string // Empty when type hints are disabled
+	Description string
+}
+
+// writeClassDocstring writes a class docstring: the one-line summary in the plain style, or the
+// summary followed by an Attributes section in the Google and NumPy (Sphinx Napoleon) styles
+func writeClassDocstring(cb *formatdef.ContentBuilder, summary string, fields []docstringField, style string) {
+	if len(fields) == 0 || (style != DocstringStyleGoogle && style != DocstringStyleNumPy) {
+		cb.Line(`"""%s"""`, summary)
+		return
+	}
+
+	cb.Line(`"""%s`, summary)
+	cb.Line("")
+	if style == DocstringStyleGoogle {
+		cb.Line("Attributes:")
+		cb.Indent()
+		for _, field := range fields {
+			item := field.Name
+			if field.Type != "" {
+				item += " (" + field.Type + ")"
+			}
+			if field.Description == "" {
+				cb.Line("%s", item)
+				continue
+			}
+			// Continuation lines hang one level below the attribute
+			item += ": "
+			firstWidth := cb.LineWidth() - len(item)
+			cb.Indent()
+			lines := wrapDocstringText(field.Description, firstWidth, cb.LineWidth())
+			cb.Dedent()
+			cb.Line("%s", item+lines[0])
+			cb.Indent()
+			for _, line := range lines[1:] {
+				cb.Line("%s", line)
+			}
+			cb.Dedent()
+		}
+		cb.Dedent()
+	} else {
+		cb.Line("Attributes")
+		cb.Line("----------")
+		for _, field := range fields {
+			if field.Type != "" {
+				cb.Line("%s : %s", field.Name, field.Type)
+			} else {
+				cb.Line("%s", field.Name)
+			}
+			if field.Description != "" {
+				cb.Indent()
+				for _, line := range wrapDocstringText(field.Description, cb.LineWidth(), cb.LineWidth()) {
+					cb.Line("%s", line)
+				}
+				cb.Dedent()
+			}
+		}
+	}
+	cb.Line(`"""`)
+}
+
+// wrapDocstringText escapes a description for a docstring and word-wraps it, the first line to
+// firstWidth and the others to width; line breaks in the description are kept and a width of 0
+// or less leaves lines unwrapped
+func wrapDocstringText(text string, firstWidth int, width int) []string {
+	var lines []string
+	for _, paragraph := range docstringLines(text) {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			lineWidth := width
+			if len(lines) == 0 {
+				lineWidth = firstWidth
+			}
+			if line != "" && width > 0 && len(line)+1+len(word) > lineWidth {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// modelDocstringFields lists the declared attributes of a model with their field descriptions
+func modelDocstringFields(model *formatdef.Struct, decls []modelFieldDecl, config PydanticConfig) []docstringField {
+	descriptions := make(map[string]string)
+	for _, field := range model.Fields {
+		if !strings.HasPrefix(field.Name, "_nav_") && field.Description != "" {
+			descriptions[config.pythonFieldName(field.Name)] = field.Description
+		}
+	}
+	fields := make([]docstringField, 0, len(decls))
+	for _, decl := range decls {
+		fields = append(fields, docstringField{Name: decl.Name, Type: decl.Annotation, Description: descriptions[decl.Name]})
+	}
+	return fields
+}
+
+// structureDocstringFields lists the attributes of a structure with their field descriptions
+func structureDocstringFields(fields []formatdef.Field, config PydanticConfig, typeHints bool) []docstringField {
+	docFields := make([]docstringField, 0, len(fields))
+	for _, field := range fields {
+		docField := docstringField{
+			Name:        config.pythonFieldName(field.Name),
+			Description: field.Description,
+		}
+		if typeHints {
+			switch {
+			case field.IsClassVar:
+				docField.Type = "ClassVar[" + field.Type.GetName() + "]"
+			case field.IsOptional:
+				docField.Type = "Optional[" + field.Type.GetName() + "]"
+			default:
+				docField.Type = field.Type.GetName()
+			}
+		}
+		docFields = append(docFields, docField)
+	}
+	return docFields
+}
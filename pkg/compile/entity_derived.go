@@ -0,0 +1,98 @@
+package compile
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// derivedTokenPattern splits a derived field expression into string literals, names, numbers,
+// whitespace and operators
+var derivedTokenPattern = regexp.MustCompile(`"[^"\\]*"|'[^'\\]*'|[A-Za-z_][A-Za-z0-9_]*|\d+(\.\d+)?|\s+|[-+*/%(),<>=!]+`)
+
+// derivedNames are the Python names a derived field expression may use besides entity fields
+var derivedNames = map[string]bool{
+	"and": true, "or": true, "not": true, "if": true, "else": true, "in": true,
+	"True": true, "False": true, "None": true,
+	"str": true, "int": true, "float": true, "len": true, "abs": true, "round": true, "min": true, "max": true,
+}
+
+// translateDerivedExpression translates a derived field expression to Python, reading the other
+// entity fields from self. It reports false when the expression uses anything else, so the
+// property is emitted as a stub instead.
+func translateDerivedExpression(expression string, entity *formatdef.Struct, fieldName string, config PydanticConfig) (string, bool) {
+	var b strings.Builder
+	end := 0
+	for _, loc := range derivedTokenPattern.FindAllStringIndex(expression, -1) {
+		if loc[0] != end {
+			return "", false
+		}
+		end = loc[1]
+
+		token := expression[loc[0]:loc[1]]
+		switch {
+		case token[0] == '_' || token[0] >= 'A' && token[0] <= 'Z' || token[0] >= 'a' && token[0] <= 'z':
+			if ref, isField := derivedFieldRef(token, entity, fieldName, config); isField {
+				b.WriteString(ref)
+			} else if literal, isKeyword := invariantLiterals[token]; isKeyword {
+				b.WriteString(literal)
+			} else if derivedNames[token] {
+				b.WriteString(token)
+			} else {
+				return "", false
+			}
+		default:
+			b.WriteString(token)
+		}
+	}
+	if end != len(expression) || strings.TrimSpace(expression) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(b.String()), true
+}
+
+// derivedFieldRef renders a reference to another entity field, or reports false for non-fields
+func derivedFieldRef(name string, entity *formatdef.Struct, fieldName string, config PydanticConfig) (string, bool) {
+	if name == fieldName {
+		return "", false
+	}
+	for _, field := range entity.Fields {
+		if field.Name == name {
+			return "self." + config.pythonFieldName(field.Name), true
+		}
+	}
+	return "", false
+}
+
+// generateDerivedProperties adds a property per computed entity field, decorated with
+// @computed_field in Pydantic v2 so it is included when serializing
+func generateDerivedProperties(cb *formatdef.ContentBuilder, entity *formatdef.Struct, config PydanticConfig) {
+	for _, field := range entity.Fields {
+		if !field.IsComputed {
+			continue
+		}
+		propertyName := config.pythonFieldName(field.Name)
+		returnType := field.Type.GetName()
+		if field.IsOptional {
+			returnType = "Optional[" + returnType + "]"
+		}
+
+		cb.Line("")
+		if config.PydanticV2 {
+			cb.Line("@computed_field")
+		}
+		cb.Line("@property")
+		cb.Line("def %s(self) -> %s:", propertyName, returnType)
+		cb.Indent()
+		if body, ok := translateDerivedExpression(field.Expression, entity, field.Name, config); ok {
+			cb.Line(`"""Derived: %s"""`, strings.Join(docstringLines(field.Expression), " "))
+			cb.Line("return %s", body)
+		} else {
+			cb.Line(`"""Derived %s value."""`, propertyName)
+			cb.Line("# TODO: derive this value from the entity fields")
+			cb.Line("raise NotImplementedError")
+		}
+		cb.Dedent()
+	}
+}
@@ -0,0 +1,113 @@
+package compile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// fieldGroup is a set of structure fields validated together, declared through "together:<group>"
+// (all or none) or "oneof:<group>" (exactly one) attributes
+type fieldGroup struct {
+	name       string
+	exactlyOne bool
+	fieldNames []string // Python field names in field order
+}
+
+// structureFieldGroups collects the field groups of a structure, "together" groups first, each
+// kind sorted by group name
+func structureFieldGroups(fields []formatdef.Field, config PydanticConfig) []fieldGroup {
+	together := map[string][]string{}
+	oneOf := map[string][]string{}
+	for _, field := range fields {
+		fieldName := config.pythonFieldName(field.Name)
+		for _, group := range field.Together {
+			together[group] = append(together[group], fieldName)
+		}
+		for _, group := range field.OneOf {
+			oneOf[group] = append(oneOf[group], fieldName)
+		}
+	}
+
+	var groups []fieldGroup
+	for _, kind := range []struct {
+		members    map[string][]string
+		exactlyOne bool
+	}{{together, false}, {oneOf, true}} {
+		names := make([]string, 0, len(kind.members))
+		for name := range kind.members {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			groups = append(groups, fieldGroup{name: name, exactlyOne: kind.exactlyOne, fieldNames: kind.members[name]})
+		}
+	}
+	return groups
+}
+
+// joinFieldNames lists field names as "a, b and c" (or "a, b or c")
+func joinFieldNames(fieldNames []string, conjunction string) string {
+	if len(fieldNames) < 2 {
+		return strings.Join(fieldNames, "")
+	}
+	return strings.Join(fieldNames[:len(fieldNames)-1], ", ") + " " + conjunction + " " + fieldNames[len(fieldNames)-1]
+}
+
+// writeFieldGroupValidators adds one validator method per field group raising ValueError when
+// the group invariant doesn't hold: a @model_validator(mode="after") in Pydantic v2, or a
+// @root_validator in v1
+func writeFieldGroupValidators(cb *formatdef.ContentBuilder, groups []fieldGroup, config PydanticConfig) {
+	for _, group := range groups {
+		suffix, summary := "together", "must be provided together or not at all"
+		if group.exactlyOne {
+			suffix, summary = "one_of", "exactly one must be provided"
+		}
+		methodName := fmt.Sprintf("check_%s_%s", formatdef.ToSnakeCase(group.name), suffix)
+
+		cb.Line("")
+		if config.PydanticV2 {
+			cb.Line(`@model_validator(mode="after")`)
+			cb.Line("def %s(self):", methodName)
+		} else {
+			cb.Line("@root_validator(skip_on_failure=True)")
+			cb.Line("def %s(cls, values):", methodName)
+		}
+		cb.Indent()
+		cb.Line(`"""Field group %s: %s."""`, group.name, summary)
+		writeFieldGroupCheck(cb, group, config.PydanticV2)
+		if config.PydanticV2 {
+			cb.Line("return self")
+		} else {
+			cb.Line("return values")
+		}
+		cb.Dedent()
+	}
+}
+
+// writeFieldGroupCheck writes the statements raising ValueError when a field group invariant
+// doesn't hold, reading the fields from self or, in a Pydantic v1 validator, the values dict
+func writeFieldGroupCheck(cb *formatdef.ContentBuilder, group fieldGroup, fromSelf bool) {
+	quoted := make([]string, 0, len(group.fieldNames))
+	for _, fieldName := range group.fieldNames {
+		quoted = append(quoted, fmt.Sprintf("%q", fieldName))
+	}
+
+	if fromSelf {
+		cb.Line("provided = [name for name in (%s) if getattr(self, name) is not None]", strings.Join(quoted, ", "))
+	} else {
+		cb.Line("provided = [name for name in (%s) if values.get(name) is not None]", strings.Join(quoted, ", "))
+	}
+	if group.exactlyOne {
+		cb.Line("if len(provided) != 1:")
+		cb.Indent()
+		cb.Line("raise ValueError(%q)", "exactly one of "+joinFieldNames(group.fieldNames, "or")+" must be provided")
+	} else {
+		cb.Line("if provided and len(provided) != %d:", len(group.fieldNames))
+		cb.Indent()
+		cb.Line("raise ValueError(%q)", joinFieldNames(group.fieldNames, "and")+" must be provided together")
+	}
+	cb.Dedent()
+}
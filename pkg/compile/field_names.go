@@ -0,0 +1,115 @@
+package compile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// Field cases of generated Python attribute names
+const (
+	FieldCaseSnake = "snake"
+	FieldCaseAsIs  = "as_is"
+	FieldCaseCamel = "camel"
+)
+
+// pythonFieldName renders a Morphe field or relationship name as a Python attribute name in the
+// configured field case. An empty case falls back to snake_case.
+func (config PydanticConfig) pythonFieldName(morpheName string) string {
+	switch config.FieldCase {
+	case FieldCaseAsIs:
+		return SanitizePythonIdentifier(morpheName)
+	case FieldCaseCamel:
+		return SanitizePythonIdentifier(formatdef.ToCamelCase(formatdef.ToSnakeCase(morpheName)))
+	default:
+		return SanitizePythonIdentifier(formatdef.ToSnakeCase(morpheName))
+	}
+}
+
+// fieldKeyAlias returns the original Morphe key a field is aliased to, empty when its Python name
+// already matches that key, the compiler added the field or field key aliases are disabled
+func (config PydanticConfig) fieldKeyAlias(field formatdef.Field) string {
+	if config.DisableFieldKeyAliases || field.IsImplicit || config.pythonFieldName(field.Name) == field.Name {
+		return ""
+	}
+	return field.Name
+}
+
+// fieldKeyAliasKwargs renders the Field(...) keyword argument aliasing a field to its original
+// Morphe key (none when the field is not aliased)
+func (config PydanticConfig) fieldKeyAliasKwargs(field formatdef.Field) []string {
+	if alias := config.fieldKeyAlias(field); alias != "" {
+		return []string{fmt.Sprintf("alias=%q", alias)}
+	}
+	return nil
+}
+
+// hasFieldKeyAliases reports whether any field other than a class variable is aliased to its
+// original Morphe key, so the class needs populate by name
+func (config PydanticConfig) hasFieldKeyAliases(fields []formatdef.Field) bool {
+	for _, field := range fields {
+		if !field.IsClassVar && config.fieldKeyAlias(field) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldNamePatterns holds the expected shape of a field name for each naming convention
+var fieldNamePatterns = map[string]*regexp.Regexp{
+	cfg.FieldNameConventionCamelCase:  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	cfg.FieldNameConventionPascalCase: regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	cfg.FieldNameConventionSnakeCase:  regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+}
+
+// FieldNameConventionError lists the Morphe fields whose names break the configured convention
+type FieldNameConventionError struct {
+	Convention string
+	Fields     []string // Offending fields as "Type.Field", sorted
+}
+
+func (e *FieldNameConventionError) Error() string {
+	return fmt.Sprintf("field names do not follow %s: %s", e.Convention, strings.Join(e.Fields, ", "))
+}
+
+// ValidateFieldNameConvention checks every model, structure and entity field name against the
+// naming convention, returning a FieldNameConventionError listing all offending fields
+func ValidateFieldNameConvention(r *registry.Registry, convention string) error {
+	pattern, exists := fieldNamePatterns[convention]
+	if !exists {
+		return nil
+	}
+
+	var offending []string
+	check := func(typeName string, fieldName string) {
+		if !pattern.MatchString(fieldName) {
+			offending = append(offending, typeName+"."+fieldName)
+		}
+	}
+	for modelName, model := range r.GetAllModels() {
+		for fieldName := range model.Fields {
+			check(modelName, fieldName)
+		}
+	}
+	for structureName, structure := range r.GetAllStructures() {
+		for fieldName := range structure.Fields {
+			check(structureName, fieldName)
+		}
+	}
+	for entityName, entity := range r.GetAllEntities() {
+		for fieldName := range entity.Fields {
+			check(entityName, fieldName)
+		}
+	}
+
+	if len(offending) == 0 {
+		return nil
+	}
+	sort.Strings(offending)
+	return &FieldNameConventionError{Convention: convention, Fields: offending}
+}
@@ -0,0 +1,47 @@
+package compile_test
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestFileHeader_Default() {
+	content := suite.generateSource(compile.DefaultMorpheCompileConfig("", ""), newStatusRegistry(), "enums/account_status.py")
+
+	suite.True(strings.HasPrefix(content, "# Auto-generated by plugin-morphe-pydantic-types; do not edit.\n\nfrom enum import Enum\n"))
+}
+
+func (suite *CompileTestSuite) TestFileHeader_MultiLine() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.FileHeader = "DO NOT EDIT - generated by morphe\n\nCopyright (c) Acme Corp. All rights reserved."
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	header := "# DO NOT EDIT - generated by morphe\n#\n# Copyright (c) Acme Corp. All rights reserved.\n\n"
+	for path, content := range files {
+		if strings.HasSuffix(path, ".py") {
+			suite.True(strings.HasPrefix(content, header), path)
+		}
+	}
+}
+
+func (suite *CompileTestSuite) TestFileHeader_Disabled() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.DisableFileHeader = true
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.True(strings.HasPrefix(content, "from enum import Enum\n"))
+}
+
+func (suite *CompileTestSuite) TestFileHeader_EmptyUsesDefault() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FileHeader = ""
+
+	content := suite.generateSource(config, newStatusRegistry(), "enums/account_status.py")
+
+	suite.True(strings.HasPrefix(content, "# "+compile.DefaultFileHeader+"\n\n"))
+}
@@ -0,0 +1,24 @@
+package compile
+
+import "github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+
+// File naming strategies for generated modules
+const (
+	FileNamingSnake  = "snake"
+	FileNamingPascal = "pascal"
+	FileNamingAsIs   = "as_is"
+)
+
+// ModuleName returns the module (file) name of a type under a file naming strategy. The writer
+// and the import statements both go through it, so file names and import paths always agree.
+// An empty strategy falls back to snake_case.
+func ModuleName(typeName string, fileNaming string) string {
+	switch fileNaming {
+	case FileNamingPascal:
+		return formatdef.ToPascalCase(formatdef.ToSnakeCase(typeName))
+	case FileNamingAsIs:
+		return typeName
+	default:
+		return formatdef.ToSnakeCase(typeName)
+	}
+}
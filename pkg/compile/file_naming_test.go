@@ -0,0 +1,71 @@
+package compile_test
+
+import (
+	"path/filepath"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestModuleName() {
+	suite.Equal("contact_info", compile.ModuleName("ContactInfo", compile.FileNamingSnake))
+	suite.Equal("contact_info", compile.ModuleName("ContactInfo", ""))
+	suite.Equal("ContactInfo", compile.ModuleName("ContactInfo", compile.FileNamingPascal))
+	suite.Equal("UniversalNumber", compile.ModuleName("UniversalNumber", compile.FileNamingAsIs))
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_PascalFileNaming() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.FileNaming = compile.FileNamingPascal
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files, "models/ContactInfo.py")
+	suite.Contains(files, "enums/UniversalNumber.py")
+	suite.NotContains(files, "models/contact_info.py")
+	suite.Contains(files["models/__init__.py"], "from .ContactInfo import ContactInfo\n")
+	suite.Contains(files["models/Person.py"], "from ..enums.Nationality import Nationality\n")
+	suite.Contains(files["models/Person.py"], "    from .ContactInfo import ContactInfo\n")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_InvalidFileNaming() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.FileNaming = "kebab"
+
+	suite.EqualError(config.Validate(), "invalid fileNaming: kebab (must be 'snake', 'pascal' or 'as_is')")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_RootPackage() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.RootPackage = "mycompany.generated.schemas"
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files, "mycompany/__init__.py")
+	suite.Contains(files, "mycompany/generated/__init__.py")
+	suite.Contains(files, "mycompany/generated/schemas/__init__.py")
+	suite.Contains(files, "mycompany/generated/schemas/py.typed")
+	suite.Contains(files, "mycompany/generated/schemas/models/__init__.py")
+	suite.NotContains(files, "models/person.py")
+	suite.Contains(files["mycompany/generated/schemas/models/person.py"], "from ..enums.nationality import Nationality\n")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_RootPackageWithoutInit() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.RootPackage = "mycompany.generated"
+	config.FormatConfig.GenerateInit = false
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.NotContains(files, "mycompany/__init__.py")
+	suite.Contains(files, "mycompany/generated/models/person.py")
+}
+
+func (suite *CompileTestSuite) TestValidate_InvalidRootPackage() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.RootPackage = "mycompany.2nd.class"
+
+	suite.EqualError(config.Validate(), "invalid rootPackage: mycompany.2nd.class (must be a dotted path of Python identifiers)")
+}
@@ -0,0 +1,133 @@
+package compile
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// Import sections in isort order
+const (
+	importSectionFuture = iota
+	importSectionStdlib
+	importSectionThirdParty
+	importSectionFirstParty
+)
+
+// stdlibModules are the standard library modules generated code imports from
+var stdlibModules = map[string]bool{
+	"dataclasses": true,
+	"datetime":    true,
+	"decimal":     true,
+	"enum":        true,
+	"typing":      true,
+	"uuid":        true,
+}
+
+// importSection returns the isort section of a module. Relative imports are first party; any
+// module that isn't __future__ or a known standard library module is third party.
+func importSection(module string) int {
+	switch {
+	case module == "__future__":
+		return importSectionFuture
+	case strings.HasPrefix(module, "."):
+		return importSectionFirstParty
+	case stdlibModules[strings.Split(module, ".")[0]]:
+		return importSectionStdlib
+	default:
+		return importSectionThirdParty
+	}
+}
+
+// relativeDepth returns the number of leading dots of a relative module
+func relativeDepth(module string) int {
+	return len(module) - len(strings.TrimLeft(module, "."))
+}
+
+// importNameRank groups imported names the way isort orders them: constants, classes, then
+// functions and variables
+func importNameRank(name string) int {
+	switch {
+	case len(name) > 1 && strings.ToUpper(name) == name:
+		return 0
+	case name[:1] == strings.ToUpper(name[:1]):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sortImportNames sorts the names of a from-import by kind, then alphabetically ignoring case
+func sortImportNames(names []string) []string {
+	sorted := append([]string{}, names...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if rankI, rankJ := importNameRank(sorted[i]), importNameRank(sorted[j]); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return strings.ToLower(sorted[i]) < strings.ToLower(sorted[j])
+	})
+	return sorted
+}
+
+// importStatement is a single import line with the module it imports from
+type importStatement struct {
+	module string
+	plain  bool // "import x" rather than "from x import ..."
+	text   string
+}
+
+// parseImportStatement reads the module of a verbatim import statement
+func parseImportStatement(statement string) importStatement {
+	fields := strings.Fields(statement)
+	if len(fields) >= 2 && fields[0] == "import" {
+		return importStatement{module: fields[1], plain: true, text: statement}
+	}
+	if len(fields) >= 2 && fields[0] == "from" {
+		return importStatement{module: fields[1], text: statement}
+	}
+	return importStatement{text: statement}
+}
+
+// writeImportSections writes from-imports and verbatim import statements grouped into isort
+// sections (__future__, standard library, third party, first party) separated by blank lines.
+// Within a section, plain imports come before from-imports, each sorted by module ignoring case.
+func writeImportSections(cb *formatdef.ContentBuilder, from map[string][]string, statements []string) {
+	var all []importStatement
+	for module, names := range from {
+		if len(names) == 0 {
+			continue
+		}
+		all = append(all, importStatement{
+			module: module,
+			text:   "from " + module + " import " + strings.Join(sortImportNames(names), ", "),
+		})
+	}
+	for _, statement := range statements {
+		all = append(all, parseImportStatement(statement))
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if sectionI, sectionJ := importSection(all[i].module), importSection(all[j].module); sectionI != sectionJ {
+			return sectionI < sectionJ
+		}
+		if all[i].plain != all[j].plain {
+			return all[i].plain
+		}
+		// Relative imports from further up the package come first (from .. before from .)
+		if depthI, depthJ := relativeDepth(all[i].module), relativeDepth(all[j].module); depthI != depthJ {
+			return depthI > depthJ
+		}
+		if moduleI, moduleJ := strings.ToLower(all[i].module), strings.ToLower(all[j].module); moduleI != moduleJ {
+			return moduleI < moduleJ
+		}
+		return all[i].text < all[j].text
+	})
+
+	for i, statement := range all {
+		if i > 0 && importSection(statement.module) != importSection(all[i-1].module) {
+			cb.Line("")
+		}
+		cb.Line("%s", statement.text)
+	}
+}
@@ -1,6 +1,7 @@
 package compile
 
 import (
+	"regexp"
 	"sort"
 	"strings"
 
@@ -12,21 +13,79 @@ import (
 type ImportTracker struct {
 	pydantic []string
 	typing   []string
-	datetime bool
+	datetime []string
 	enums    map[string]bool
+	deferred map[string]bool // Enums imported under TYPE_CHECKING to break an import cycle
 	models   map[string]bool
+	from     map[string][]string
+	raw      []string // Verbatim import statements (e.g. for custom types)
 	registry *registry.Registry
+	current  string // Model being generated, never imported from its own module
+
+	fileNaming string // Module naming strategy for enum and model import paths
+
+	deferCyclicEnums bool  // Defer the enum imports of models in a relationship cycle
+	cyclic           *bool // Whether the current model is in a relationship cycle, once detected
 }
 
 // NewImportTracker creates a new import tracker
 func NewImportTracker(r *registry.Registry) *ImportTracker {
 	return &ImportTracker{
 		enums:    make(map[string]bool),
+		deferred: make(map[string]bool),
 		models:   make(map[string]bool),
+		from:     make(map[string][]string),
 		registry: r,
 	}
 }
 
+// SetFileNaming sets the file naming strategy used for enum and model import paths
+func (it *ImportTracker) SetFileNaming(fileNaming string) {
+	it.fileNaming = fileNaming
+}
+
+// SetCurrentModel marks the model whose module is being generated, so self references
+// don't produce a self-import
+func (it *ImportTracker) SetCurrentModel(modelName string) {
+	it.current = modelName
+}
+
+// SetDeferCyclicEnums enables importing the enums of a model in a relationship cycle under
+// TYPE_CHECKING, referenced by quoted annotations
+func (it *ImportTracker) SetDeferCyclicEnums(deferCyclicEnums bool) {
+	it.deferCyclicEnums = deferCyclicEnums
+}
+
+// enumImportCycle reports whether the current model's enum imports take part in an import
+// cycle, i.e. the model reaches itself through its relationships so its module may be
+// imported while a module of the cycle is only partially initialized
+func (it *ImportTracker) enumImportCycle() bool {
+	if !it.deferCyclicEnums || it.current == "" {
+		return false
+	}
+	if it.cyclic == nil {
+		cyclic := inCycle(it.current, buildDependencyGraph(it.registry.GetAllModels()))
+		it.cyclic = &cyclic
+	}
+	return *it.cyclic
+}
+
+// QuoteDeferredEnums quotes the enums imported under TYPE_CHECKING within a type annotation
+func (it *ImportTracker) QuoteDeferredEnums(annotation string) string {
+	if len(it.deferred) == 0 {
+		return annotation
+	}
+	return identifierPattern.ReplaceAllStringFunc(annotation, func(name string) string {
+		if it.deferred[name] {
+			return `"` + name + `"`
+		}
+		return name
+	})
+}
+
+// identifierPattern matches the names of an annotation, including already quoted ones
+var identifierPattern = regexp.MustCompile(`"?[A-Za-z_][A-Za-z0-9_]*"?`)
+
 // AddPydantic adds a pydantic import
 func (it *ImportTracker) AddPydantic(imports ...string) {
 	for _, imp := range imports {
@@ -45,6 +104,9 @@ func (it *ImportTracker) TrackFieldType(typeName string) {
 	if strings.Contains(typeName, "List[") {
 		it.AddTyping("List")
 	}
+	if strings.Contains(typeName, "Sequence[") {
+		it.AddTyping("Sequence")
+	}
 	if strings.Contains(typeName, "Union[") {
 		it.AddTyping("Union")
 	}
@@ -57,10 +119,22 @@ func (it *ImportTracker) TrackFieldType(typeName string) {
 	if strings.Contains(typeName, "Literal[") {
 		it.AddTyping("Literal")
 	}
+	if strings.Contains(typeName, "ForwardRef(") {
+		it.AddTyping("ForwardRef")
+	}
+
+	// Pydantic's recursive JSON type and timezone-aware datetime
+	for _, pydanticType := range []formatdef.Type{formatdef.TypeJSONValue, formatdef.TypeAwareDatetime} {
+		if containsString(extractAllInnerTypes(typeName), pydanticType.GetName()) {
+			it.AddPydantic(pydanticType.GetName())
+		}
+	}
 
-	// Check for datetime
-	if typeName == "datetime" || strings.Contains(typeName, "datetime") {
-		it.datetime = true
+	// Check for date, datetime and time
+	for _, name := range datetimeNames(typeName) {
+		if !containsString(it.datetime, name) {
+			it.datetime = append(it.datetime, name)
+		}
 	}
 
 	// Extract inner types and check if they're enums or models
@@ -69,10 +143,21 @@ func (it *ImportTracker) TrackFieldType(typeName string) {
 		if innerType != "" && !isBasicType(innerType) {
 			switch resolveFieldType(innerType, it.registry) {
 			case "enum":
-				it.enums[innerType] = true
+				if it.enumImportCycle() {
+					it.deferred[innerType] = true
+				} else {
+					it.enums[innerType] = true
+				}
 			case "model":
-				it.models[innerType] = true
-				it.AddTyping("TYPE_CHECKING")
+				if innerType != it.current {
+					it.models[innerType] = true
+				}
+			default:
+				// Enum Literal aliases live in their enum's module
+				enumName := strings.TrimSuffix(innerType, enumLiteralSuffix)
+				if enumName != innerType && resolveFieldType(enumName, it.registry) == "enum" {
+					it.AddFrom("..enums."+ModuleName(enumName, it.fileNaming), innerType)
+				}
 			}
 		}
 	}
@@ -87,49 +172,82 @@ func (it *ImportTracker) AddTyping(imports ...string) {
 	}
 }
 
-// Generate generates the import statements
-func (it *ImportTracker) Generate(cb *formatdef.ContentBuilder) {
-	// Pydantic imports
-	if len(it.pydantic) > 0 {
-		cb.Line("from pydantic import %s", strings.Join(it.pydantic, ", "))
+// AddFrom adds names imported from an arbitrary module (e.g. "..sentinels" or "myapp.base")
+func (it *ImportTracker) AddFrom(module string, names ...string) {
+	for _, name := range names {
+		if !containsString(it.from[module], name) {
+			it.from[module] = append(it.from[module], name)
+		}
 	}
+}
 
-	// Typing imports
-	if len(it.typing) > 0 {
-		sort.Strings(it.typing)
-		cb.Line("from typing import %s", strings.Join(it.typing, ", "))
+// AddStatement adds verbatim import statements
+func (it *ImportTracker) AddStatement(statements ...string) {
+	for _, statement := range statements {
+		if !containsString(it.raw, statement) {
+			it.raw = append(it.raw, statement)
+		}
 	}
+}
 
-	// Datetime
-	if it.datetime {
-		cb.Line("from datetime import datetime")
+// Generate generates the import statements, grouped and ordered the way isort expects:
+// standard library, then third party, then first-party relative imports. Models, and enums
+// deferred to break an import cycle, are imported under a TYPE_CHECKING guard after the import
+// sections.
+func (it *ImportTracker) Generate(cb *formatdef.ContentBuilder) {
+	from := make(map[string][]string)
+	from["pydantic"] = append(from["pydantic"], it.pydantic...)
+
+	// Typing imports, with TYPE_CHECKING only when the guard block below is emitted
+	for _, imp := range it.typing {
+		if imp != "TYPE_CHECKING" {
+			from["typing"] = append(from["typing"], imp)
+		}
+	}
+	if len(it.models) > 0 || len(it.deferred) > 0 {
+		from["typing"] = append(from["typing"], "TYPE_CHECKING")
 	}
 
+	from["datetime"] = append(from["datetime"], it.datetime...)
+
 	// Enums
-	if len(it.enums) > 0 {
-		var enumNames []string
-		for enum := range it.enums {
-			enumNames = append(enumNames, enum)
-		}
-		sort.Strings(enumNames)
-		for _, enumName := range enumNames {
-			cb.Line("from ..enums.%s import %s", formatdef.ToSnakeCase(enumName), enumName)
+	for enumName := range it.enums {
+		module := "..enums." + ModuleName(enumName, it.fileNaming)
+		from[module] = append(from[module], enumName)
+	}
+
+	// Other module imports
+	for module, names := range it.from {
+		for _, name := range names {
+			if !containsString(from[module], name) {
+				from[module] = append(from[module], name)
+			}
 		}
 	}
 
+	writeImportSections(cb, from, it.raw)
+
 	cb.Line("")
 
-	// Models under TYPE_CHECKING
-	if len(it.models) > 0 {
+	// Deferred enums and models under TYPE_CHECKING
+	if len(it.models) > 0 || len(it.deferred) > 0 {
 		cb.Line("if TYPE_CHECKING:")
 		cb.Indent()
+		var enumNames []string
+		for enumName := range it.deferred {
+			enumNames = append(enumNames, enumName)
+		}
+		sort.Strings(enumNames)
+		for _, enumName := range enumNames {
+			cb.Line("from ..enums.%s import %s", ModuleName(enumName, it.fileNaming), enumName)
+		}
 		var modelNames []string
 		for model := range it.models {
 			modelNames = append(modelNames, model)
 		}
 		sort.Strings(modelNames)
 		for _, modelName := range modelNames {
-			cb.Line("from .%s import %s", formatdef.ToSnakeCase(modelName), modelName)
+			cb.Line("from .%s import %s", ModuleName(modelName, it.fileNaming), modelName)
 		}
 		cb.Dedent()
 	}
@@ -147,7 +265,7 @@ func containsString(slice []string, item string) bool {
 }
 
 func isBasicType(typeName string) bool {
-	basicTypes := []string{"str", "int", "float", "bool", "datetime", "Any", "None"}
+	basicTypes := []string{"str", "int", "float", "bool", "date", "datetime", "time", "Any", "None", "list", "dict"}
 	for _, basic := range basicTypes {
 		if typeName == basic {
 			return true
@@ -156,12 +274,51 @@ func isBasicType(typeName string) bool {
 	return false
 }
 
-// extractAllInnerTypes extracts all type names from complex type expressions
+// isDatetimeType reports whether a type name is one of the datetime module's types
+func isDatetimeType(typeName string) bool {
+	return typeName == "date" || typeName == "datetime" || typeName == "time"
+}
+
+// datetimeNames returns the datetime module types referenced by a type expression
+func datetimeNames(typeName string) []string {
+	var names []string
+	for _, innerType := range extractAllInnerTypes(typeName) {
+		if isDatetimeType(innerType) && !containsString(names, innerType) {
+			names = append(names, innerType)
+		}
+	}
+	return names
+}
+
+// typingKeywords are the typing constructs that wrap type names in annotations; they are never
+// model or enum references
+var typingKeywords = map[string]bool{
+	"Optional":  true,
+	"List":      true,
+	"Sequence":  true,
+	"Union":     true,
+	"Dict":      true,
+	"Any":       true,
+	"Literal":   true,
+	"Annotated": true,
+	"ClassVar":  true,
+}
+
+// literalValuesPattern matches a Literal of string values, whose values are never type names
+var literalValuesPattern = regexp.MustCompile(`Literal\[(?:\s*"(?:[^"\\]|\\.)*"\s*,?)*\]`)
+
+// fieldMetadataPattern matches the Field(...) metadata of an Annotated type, which holds values
+// rather than type names
+var fieldMetadataPattern = regexp.MustCompile(`Field\([^()]*\)`)
+
+// extractAllInnerTypes extracts the distinct type names referenced by a type expression, skipping
+// typing keywords so List[Optional[X]] yields only X
 func extractAllInnerTypes(typeName string) []string {
 	var types []string
 
 	// Remove all brackets and split by comma
-	cleaned := typeName
+	cleaned := literalValuesPattern.ReplaceAllString(typeName, "Literal")
+	cleaned = fieldMetadataPattern.ReplaceAllString(cleaned, "")
 	cleaned = strings.ReplaceAll(cleaned, "[", " ")
 	cleaned = strings.ReplaceAll(cleaned, "]", " ")
 	cleaned = strings.ReplaceAll(cleaned, ",", " ")
@@ -170,7 +327,7 @@ func extractAllInnerTypes(typeName string) []string {
 	parts := strings.Fields(cleaned)
 	for _, part := range parts {
 		part = strings.Trim(part, "'\"")
-		if part != "" && !strings.Contains(part, "|") {
+		if part != "" && !strings.Contains(part, "|") && !typingKeywords[part] && !containsString(types, part) {
 			types = append(types, part)
 		}
 	}
@@ -0,0 +1,108 @@
+package compile_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+func (suite *CompileTestSuite) TestImportTracker_NestedGenericEnum() {
+	imports := compile.NewImportTracker(newStatusRegistry())
+	imports.TrackFieldType("List[Optional[AccountStatus]]")
+
+	cb := formatdef.NewContentBuilder("    ")
+	imports.Generate(cb)
+	content := string(cb.Build())
+
+	suite.Equal(1, strings.Count(content, "from ..enums.account_status import AccountStatus\n"))
+	suite.Contains(content, "from typing import List, Optional\n")
+	suite.NotContains(content, "if TYPE_CHECKING:")
+}
+
+// newEventRegistry builds a registry whose Event model imports from every import section
+func newEventRegistry() *registry.Registry {
+	r := newStatusRegistry()
+	primary := map[string]yaml.ModelIdentifier{"primary": {Fields: []string{"ID"}}}
+	r.SetModel("Venue", yaml.Model{
+		Name:        "Venue",
+		Fields:      map[string]yaml.ModelField{"ID": {Type: yaml.ModelFieldTypeAutoIncrement}},
+		Identifiers: primary,
+	})
+	r.SetModel("Event", yaml.Model{
+		Name: "Event",
+		Fields: map[string]yaml.ModelField{
+			"ID":     {Type: yaml.ModelFieldTypeAutoIncrement},
+			"Day":    {Type: yaml.ModelFieldTypeDate},
+			"Fee":    {Type: yaml.ModelFieldType("Money")},
+			"Note":   {Type: yaml.ModelFieldTypeString, Attributes: []string{"optional", "description:Free text"}},
+			"Status": {Type: "AccountStatus"},
+		},
+		Identifiers: primary,
+		Related:     map[string]yaml.ModelRelation{"Venue": {Type: "ForOne"}},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestImportTracker_IsortSections() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.MorpheConfig.Models.UseUnsetSentinel = true
+	config.MorpheConfig.Models.AnnotatedStyle = true
+	config.FormatConfig.CustomTypeMappings = map[string]compile.CustomType{
+		"Money": {Type: "Money", Import: "from myapp.money import Money"},
+	}
+
+	content := suite.generateSource(config, newEventRegistry(), "models/event.py")
+
+	suite.True(strings.HasPrefix(content, `# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from datetime import date
+from typing import TYPE_CHECKING, Optional, Union
+
+from myapp.money import Money
+from pydantic import BaseModel, Field
+from typing_extensions import Annotated
+
+from .._sentinels import UNSET, Unset
+from ..enums.account_status import AccountStatus
+
+if TYPE_CHECKING:
+    from .venue import Venue
+
+
+class Event(BaseModel):
+`), content)
+	suite.isortCheck(content)
+}
+
+// isortCheck verifies generated source is already sorted the way isort (black profile) sorts
+// it, when isort is installed
+func (suite *CompileTestSuite) isortCheck(content string) {
+	isortPath, err := exec.LookPath("isort")
+	if err != nil {
+		suite.T().Log("isort not installed, skipping isort --check")
+		return
+	}
+	sourcePath := filepath.Join(suite.T().TempDir(), "module.py")
+	suite.Require().NoError(os.WriteFile(sourcePath, []byte(content), 0644))
+
+	output, err := exec.Command(isortPath, "--check-only", "--diff", "--profile", "black", sourcePath).CombinedOutput()
+	suite.NoError(err, string(output))
+}
+
+func (suite *CompileTestSuite) TestGroundTruth_IsortSections() {
+	for _, groundTruth := range []string{"compile-minimal", "compile-polymorphic"} {
+		paths, err := filepath.Glob(filepath.Join(suite.TestDirPath, "ground-truth", groundTruth, "*", "*.py"))
+		suite.Require().NoError(err)
+		for _, path := range paths {
+			content, readErr := os.ReadFile(path)
+			suite.Require().NoError(readErr)
+			suite.isortCheck(string(content))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package compile
+
+import (
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	rcfg "github.com/kalo-build/morphe-go/pkg/registry/cfg"
+)
+
+// LoadMorpheRegistries loads one or more Morphe registry roots into a single merged registry.
+// Each type category is loaded from every root before moving on to the next category, so
+// models in one root may reference enums declared in another. Duplicate type names across
+// roots are reported as errors by the registry itself.
+func LoadMorpheRegistries(configs []rcfg.MorpheLoadRegistryConfig) (*registry.Registry, error) {
+	if len(configs) == 1 {
+		return registry.LoadMorpheRegistry(registry.LoadMorpheRegistryHooks{}, configs[0])
+	}
+
+	r := registry.NewRegistry()
+
+	for _, config := range configs {
+		if err := r.LoadEnumsFromDirectory(config.RegistryEnumsDirPath); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, config := range configs {
+		if err := r.LoadModelsFromDirectory(config.RegistryModelsDirPath); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, config := range configs {
+		if err := r.LoadStructuresFromDirectory(config.RegistryStructuresDirPath); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, config := range configs {
+		if err := r.LoadEntitiesFromDirectory(config.RegistryEntitiesDirPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.ValidateRegistry(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
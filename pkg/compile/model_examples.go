@@ -0,0 +1,113 @@
+package compile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// basicTypeExamples are the synthesized example literals of the Python basic types
+var basicTypeExamples = map[string]string{
+	"str":           `"string"`,
+	"int":           "1",
+	"float":         "1.0",
+	"bool":          "True",
+	"date":          `"2024-01-01"`,
+	"time":          `"12:00:00"`,
+	"datetime":      `"2024-01-01T12:00:00"`,
+	"AwareDatetime": `"2024-01-01T12:00:00Z"`,
+}
+
+// modelSchemaExample synthesizes an example instance of a model as "key": value entries, keyed by
+// the field alias when it has one so the example matches the serialized form. Declared example:
+// attributes win over synthesized values; relationships are None.
+func modelSchemaExample(model *formatdef.Struct, config PydanticConfig, r *registry.Registry) []string {
+	var entries []string
+	for _, field := range model.Fields {
+		if strings.HasPrefix(field.Name, "_nav_") {
+			relName := strings.TrimPrefix(field.Name, "_nav_")
+			if !hasPolymorphicFields(model, relName) {
+				entries = append(entries, fmt.Sprintf("%q: None", config.pythonFieldName(relName)))
+			}
+			continue
+		}
+
+		key := config.fieldKeyAlias(field)
+		if key == "" {
+			key = config.pythonFieldName(field.Name)
+		}
+		value := exampleValue(field.Type, r)
+		if len(field.Examples) > 0 {
+			value = field.Examples[0]
+		} else if strings.HasSuffix(field.Name, "_type") && field.Type.GetName() == "str" {
+			value = "None" // Polymorphic type fields only accept their Literal candidates
+		}
+		entries = append(entries, fmt.Sprintf("%q: %s", key, value))
+	}
+	return entries
+}
+
+// hasPolymorphicFields reports whether a relationship is stored as polymorphic type/id fields
+func hasPolymorphicFields(model *formatdef.Struct, relName string) bool {
+	for _, field := range model.Fields {
+		if field.Name == relName+"_type" || field.Name == relName+"_id" {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleValue synthesizes a Python literal for a field type: a sample of basic types, the first
+// value of enums, empty containers for lists and dicts, and None otherwise
+func exampleValue(fieldType formatdef.Type, r *registry.Registry) string {
+	switch fieldType.(type) {
+	case formatdef.ArrayType:
+		return "[]"
+	case formatdef.DictType:
+		return "{}"
+	}
+
+	typeName := fieldType.GetName()
+	if value, isBasic := basicTypeExamples[typeName]; isBasic {
+		return value
+	}
+	if enum, err := r.GetEnum(typeName); err == nil && len(enum.Entries) > 0 {
+		var entryNames []string
+		for entryName := range enum.Entries {
+			entryNames = append(entryNames, entryName)
+		}
+		sort.Strings(entryNames)
+		switch value := enum.Entries[entryNames[0]].(type) {
+		case string:
+			return fmt.Sprintf("%q", value)
+		case bool:
+			return renderDefaultValue(fmt.Sprint(value), formatdef.TypeBoolean)
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+	return "None"
+}
+
+// writeSchemaExamples writes the {"examples": [...]} schema extra holding a single example,
+// opened by prefix (e.g. `"json_schema_extra": `) and closed by suffix
+func writeSchemaExamples(cb *formatdef.ContentBuilder, entries []string, prefix string, suffix string) {
+	cb.Line("%s{", prefix)
+	cb.Indent()
+	cb.Line(`"examples": [`)
+	cb.Indent()
+	cb.Line("{")
+	cb.Indent()
+	for _, entry := range entries {
+		cb.Line("%s,", entry)
+	}
+	cb.Dedent()
+	cb.Line("},")
+	cb.Dedent()
+	cb.Line("],")
+	cb.Dedent()
+	cb.Line("}%s", suffix)
+}
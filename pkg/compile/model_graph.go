@@ -0,0 +1,90 @@
+package compile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/morphe-go/pkg/yamlops"
+)
+
+// Model graph export formats
+const (
+	GraphFormatDOT  = "dot"
+	GraphFormatJSON = "json"
+)
+
+// ModelGraph is the relationship graph of a registry's models
+type ModelGraph struct {
+	Nodes []string         `json:"nodes"`
+	Edges []ModelGraphEdge `json:"edges"`
+}
+
+// ModelGraphEdge is a relationship from one model to another
+type ModelGraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+	Type     string `json:"type"`
+}
+
+// BuildModelGraph derives the relationship graph from the models. Polymorphic relationships get
+// an edge to each of their "for" models; relationships resolved through another model are skipped.
+func BuildModelGraph(models map[string]yaml.Model) ModelGraph {
+	graph := ModelGraph{Nodes: []string{}, Edges: []ModelGraphEdge{}}
+	for modelName := range models {
+		graph.Nodes = append(graph.Nodes, modelName)
+	}
+	sort.Strings(graph.Nodes)
+
+	for _, modelName := range graph.Nodes {
+		model := models[modelName]
+		var relationNames []string
+		for relationName := range model.Related {
+			relationNames = append(relationNames, relationName)
+		}
+		sort.Strings(relationNames)
+
+		for _, relationName := range relationNames {
+			relation := model.Related[relationName]
+			targets := []string{yamlops.GetRelationTargetName(relationName, relation.Aliased)}
+			if yamlops.IsRelationPoly(string(relation.Type)) {
+				targets = polymorphicCandidates(relation.For)
+			}
+			for _, target := range targets {
+				graph.Edges = append(graph.Edges, ModelGraphEdge{
+					From:     modelName,
+					To:       target,
+					Relation: relationName,
+					Type:     string(relation.Type),
+				})
+			}
+		}
+	}
+	return graph
+}
+
+// DOT renders the graph in Graphviz DOT format
+func (g ModelGraph) DOT() []byte {
+	var b strings.Builder
+	b.WriteString("digraph Models {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "  %q;\n", node)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", edge.From, edge.To, fmt.Sprintf("%s (%s)", edge.Relation, edge.Type))
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// JSON renders the graph as an indented JSON document
+func (g ModelGraph) JSON() ([]byte, error) {
+	content, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
@@ -0,0 +1,40 @@
+package compile_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestBuildModelGraph() {
+	graph := compile.BuildModelGraph(newLibraryRegistry().GetAllModels())
+
+	suite.Equal([]string{"Author", "Book", "Publisher", "Review"}, graph.Nodes)
+	suite.Contains(graph.Edges, compile.ModelGraphEdge{From: "Book", To: "Author", Relation: "Author", Type: "ForOne"})
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_GraphDOT() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.GenerateGraph = compile.GraphFormatDOT
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files["graph.dot"], "digraph Models {\n")
+	suite.Contains(files["graph.dot"], `  "Person" -> "Company" [label="Company (ForOne)"];`+"\n")
+	suite.Contains(files["graph.dot"], `  "Company" -> "Person" [label="Person (HasMany)"];`+"\n")
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_GraphJSON() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.GenerateGraph = compile.GraphFormatJSON
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	var graph compile.ModelGraph
+	suite.Require().NoError(json.Unmarshal([]byte(files["graph.json"]), &graph))
+	suite.Contains(graph.Nodes, "ContactInfo")
+	suite.Contains(graph.Edges, compile.ModelGraphEdge{From: "ContactInfo", To: "Person", Relation: "Person", Type: "ForOne"})
+}
@@ -0,0 +1,52 @@
+package compile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// DefaultModelFileTemplate lays out a model module as the file header, its imports, then any
+// type aliases, followed by the class
+const DefaultModelFileTemplate = `{{.Header}}{{.Imports}}
+
+{{if .Aliases}}{{.Aliases}}
+
+{{end}}{{.Class}}`
+
+// ModelFileData is the data available to model file templates. Only model modules are laid out
+// by the template; enum, structure and entity modules keep the built-in layout.
+type ModelFileData struct {
+	Header  string            // Rendered file header comments followed by a blank line, empty when disabled
+	Name    string            // Model name
+	Model   *formatdef.Struct // Compiled model definition
+	Imports string            // Rendered import block
+	Aliases string            // Rendered module-level TypeAlias declarations, empty when none
+	Class   string            // Rendered class definition
+}
+
+// LoadModelFileTemplate parses the model file template at path, or the default template when
+// path is empty
+func LoadModelFileTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return template.New("model").Parse(DefaultModelFileTemplate)
+	}
+	return template.ParseFiles(path)
+}
+
+// renderModelFile executes the model file template and normalizes the spacing of the result
+func renderModelFile(tmpl *template.Template, data ModelFileData, config PydanticConfig) ([]byte, error) {
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, fmt.Errorf("failed to render model file %s: %w", data.Name, err)
+	}
+
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(config.MaxLineLength)
+	for _, line := range strings.Split(rendered.String(), "\n") {
+		cb.Line("%s", line)
+	}
+	return cb.Build(), nil
+}
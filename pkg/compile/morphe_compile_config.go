@@ -1,10 +1,19 @@
 package compile
 
 import (
+	"fmt"
+	"io"
+	"os"
 	"path"
+	"regexp"
+	"strconv"
+	"strings"
 
 	rcfg "github.com/kalo-build/morphe-go/pkg/registry/cfg"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile/cfg"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/typemap"
 )
 
 // MorpheCompileConfig contains all configuration for compiling Morphe to the target format
@@ -12,6 +21,9 @@ type MorpheCompileConfig struct {
 	// Registry loading configuration
 	rcfg.MorpheLoadRegistryConfig
 
+	// Additional registry roots merged into the primary registry
+	AdditionalRegistries []rcfg.MorpheLoadRegistryConfig
+
 	// Output path for generated files
 	OutputPath string
 
@@ -20,53 +32,377 @@ type MorpheCompileConfig struct {
 
 	// Type-specific configuration
 	MorpheConfig cfg.MorpheConfig
+
+	// Type names to compile together with their dependencies (empty compiles the whole registry)
+	Only []string
+
+	// Report compilation progress and print a summary of the compiled types and written files
+	// once compilation completes
+	Verbose bool
+
+	// Destination of verbose progress and the compilation summary (os.Stdout when nil)
+	Output io.Writer
+
+	// Destination of compiler warnings (os.Stderr when nil)
+	Warnings io.Writer
 }
 
+// Python types of Morphe JSON fields
+const (
+	JSONTypeDict      = "dict"
+	JSONTypeJSONValue = "jsonvalue"
+)
+
+// Treatments of relationships of an unknown type
+const (
+	UnknownRelationsError = "error"
+	UnknownRelationsWarn  = "warn"
+)
+
+// DefaultFileHeader is the banner written atop every generated module
+const DefaultFileHeader = "Auto-generated by plugin-morphe-pydantic-types; do not edit."
+
+// DefaultPolymorphicDiscriminator is the field telling apart the members of a many-polymorphic union
+const DefaultPolymorphicDiscriminator = "Kind"
+
 // PydanticConfig contains Pydantic-specific configuration options
 type PydanticConfig struct {
 	// Pydantic-specific options
-	PydanticV2    bool   `json:"pydanticV2"`    // Use Pydantic v2 syntax (default: true)
-	AddTypeHints  bool   `json:"addTypeHints"`  // Add type hints (default: true)
-	GenerateInit  bool   `json:"generateInit"`  // Generate __init__.py files (default: true)
-	IndentSize    int    `json:"indentSize"`    // Number of spaces for indent (default: 4)
-	PythonVersion string `json:"pythonVersion"` // Target Python version (default: "3.8")
+	PydanticV2        bool   `json:"pydanticV2"`        // Use Pydantic v2 syntax (default: true)
+	AddTypeHints      bool   `json:"addTypeHints"`      // Add type hints (default: true)
+	GenerateInit      bool   `json:"generateInit"`      // Generate __init__.py files (default: true)
+	IndentSize        int    `json:"indentSize"`        // Number of spaces for indent (default: 4)
+	PythonVersion     string `json:"pythonVersion"`     // Target Python version (default: "3.8")
+	MaxLineLength     int    `json:"maxLineLength"`     // Wrap lines longer than this, 0 disables (default: 88)
+	EmitPyTyped       bool   `json:"emitPyTyped"`       // Write a PEP 561 py.typed marker at the package root (default: true)
+	EmitJSONSchema    bool   `json:"emitJSONSchema"`    // Write a schema.json with a JSON Schema per model (default: false)
+	FileTemplatePath  string `json:"fileTemplatePath"`  // text/template file laying out model modules (default: built-in)
+	GenerateGraph     string `json:"generateGraph"`     // Export the model relationship graph as "dot" or "json" (default: none)
+	FileNaming        string `json:"fileNaming"`        // Module file naming: "snake", "pascal" or "as_is" (default: "snake")
+	FieldCase         string `json:"fieldCase"`         // Field attribute naming: "snake", "as_is" or "camel" (default: "snake")
+	SortRequiredFirst bool   `json:"sortRequiredFirst"` // Order required fields before optional ones, keeping their relative order (default: false)
+	StrictTypes       bool   `json:"strictTypes"`       // Fail on field types without a known or custom mapping (default: false)
+	StrictRelations   bool   `json:"strictRelations"`   // Fail on polymorphic relationships that would be typed as Any (default: false)
+	// UnknownRelations controls model relationships of a type the compiler doesn't know: "error"
+	// fails the build, "warn" prints a warning and leaves the relationship out (default: "error")
+	UnknownRelations string `json:"unknownRelations"`
+	DocstringStyle   string `json:"docstringStyle"` // Class docstrings: "plain", or "google"/"numpy" listing attributes (default: "plain")
+	JSONType         string `json:"jsonType"`       // JSON fields as "dict" (Dict[str, Any]) or "jsonvalue" (JsonValue, v2 only) (default: "dict")
+	// AwareDatetimes rejects naive datetimes: datetime fields are typed AwareDatetime in Pydantic
+	// v2, and checked for a tzinfo by a validator in v1 (default: false). No built-in Morphe type
+	// maps to datetime (Time is time, Date is date), so it only affects fields given datetime by
+	// a custom or registered type mapping
+	AwareDatetimes bool `json:"awareDatetimes,omitempty"`
+	// DisableFieldKeyAliases stops aliasing the fields whose Python name differs from their original
+	// Morphe key to that key. Aliased fields are populatable by name and dump by alias to the source
+	// keys; a configured models.validationAlias or models.serializationAlias wins (default: false)
+	DisableFieldKeyAliases bool `json:"disableFieldKeyAliases,omitempty"`
+	// FileHeader is written as comment lines atop every generated module, before any import; it
+	// may span several lines and an empty header writes DefaultFileHeader
+	FileHeader string `json:"fileHeader"`
+	// DisableFileHeader leaves the banner out of the generated modules (default: false)
+	DisableFileHeader bool `json:"disableFileHeader,omitempty"`
+	// PolymorphicDiscriminator names the field added to every candidate model of a many-polymorphic
+	// relationship: a Literal["<Model>"] defaulting to the model name, which Pydantic uses to pick
+	// the model of each union item and which is part of every model_dump(). A candidate may declare
+	// the field itself as a Literal. An empty name writes DefaultPolymorphicDiscriminator.
+	PolymorphicDiscriminator string `json:"polymorphicDiscriminator"`
+	// DisablePolymorphicDiscriminator adds no discriminator field; many-polymorphic relationships
+	// become plain unions Pydantic validates in smart mode (default: false)
+	DisablePolymorphicDiscriminator bool `json:"disablePolymorphicDiscriminator,omitempty"`
+	// RootPackage nests the generated packages under a dotted package path inside the output
+	// directory (e.g. "mycompany.generated.schemas"); imports stay relative (default: none)
+	RootPackage string `json:"rootPackage,omitempty"`
+	// SingleFile bundles the enums, models and structures into a single models.py module in
+	// dependency order instead of a package per category; entities are skipped with a warning (default: false)
+	SingleFile bool `json:"singleFile,omitempty"`
+	// ValidateSyntax compiles every generated Python file with python3 before anything is written,
+	// failing on the first syntax error; skipped with a warning when python3 isn't found
+	ValidateSyntax bool `json:"validateSyntax,omitempty"`
+	// GenerateStubPackage writes a PEP 561 <package>-stubs directory of .pyi files next to the
+	// output directory, named after StubPackageName or the output directory
+	GenerateStubPackage bool   `json:"generateStubPackage"`
+	StubPackageName     string `json:"stubPackageName,omitempty"`
+	// CustomTypeMappings maps Morphe field types (e.g. Money) to Python types, taking precedence
+	// over the built-in mappings
+	CustomTypeMappings map[string]CustomType `json:"customTypeMappings,omitempty"`
+	// TypeResolutionOrder sets the precedence of the field type sources: "custom" mappings,
+	// "registered" types and "builtin" mappings. Omitted sources follow in that default order.
+	TypeResolutionOrder []string `json:"typeResolutionOrder,omitempty"`
+}
+
+// CustomType is a Python type provided by the user for a Morphe field type
+type CustomType struct {
+	Type   string `json:"type"`   // Python type name (e.g. "Money")
+	Import string `json:"import"` // Import statement emitted where the type is used (e.g. "from myapp.money import Money")
+}
+
+// polymorphicDiscriminator returns the Morphe name of the many-polymorphic union discriminator
+// field, or an empty string when discriminators are disabled
+func (config PydanticConfig) polymorphicDiscriminator() string {
+	if config.DisablePolymorphicDiscriminator {
+		return ""
+	}
+	if config.PolymorphicDiscriminator == "" {
+		return DefaultPolymorphicDiscriminator
+	}
+	return config.PolymorphicDiscriminator
+}
+
+// customTypeImports returns the import statements of the custom types used in a type expression
+func (config PydanticConfig) customTypeImports(typeName string) []string {
+	var statements []string
+	for _, innerType := range extractAllInnerTypes(typeName) {
+		for _, customType := range config.CustomTypeMappings {
+			if customType.Type == innerType && customType.Import != "" {
+				statements = append(statements, customType.Import)
+			}
+		}
+	}
+	return statements
+}
+
+// typeResolver returns the field type resolver for the custom mappings and resolution order
+func (config PydanticConfig) typeResolver() typemap.Resolver {
+	custom := make(map[yaml.ModelFieldType]formatdef.Type, len(config.CustomTypeMappings))
+	for morpheType, customType := range config.CustomTypeMappings {
+		custom[yaml.ModelFieldType(morpheType)] = formatdef.BasicType{Name: customType.Type}
+	}
+	return typemap.Resolver{Order: config.TypeResolutionOrder, Custom: custom}
+}
+
+// pydanticFieldType swaps the Dict[str, Any] mapping of JSON fields for Pydantic's JsonValue
+// and datetime for AwareDatetime (v2) when configured, leaving every other type as is
+func (config PydanticConfig) pydanticFieldType(fieldType formatdef.Type) formatdef.Type {
+	if config.JSONType == JSONTypeJSONValue && fieldType == formatdef.TypeJSON {
+		return formatdef.TypeJSONValue
+	}
+	if config.AwareDatetimes && config.PydanticV2 && fieldType == formatdef.TypeDateTime {
+		return formatdef.TypeAwareDatetime
+	}
+	return fieldType
+}
+
+// PythonVersionAtLeast reports whether the target Python version is at least major.minor
+func (config PydanticConfig) PythonVersionAtLeast(major int, minor int) bool {
+	parts := strings.SplitN(config.PythonVersion, ".", 3)
+	targetMajor, majorErr := strconv.Atoi(parts[0])
+	if majorErr != nil {
+		return false
+	}
+	targetMinor := 0
+	if len(parts) > 1 {
+		if parsedMinor, minorErr := strconv.Atoi(parts[1]); minorErr == nil {
+			targetMinor = parsedMinor
+		}
+	}
+	if targetMajor != major {
+		return targetMajor > major
+	}
+	return targetMinor >= minor
 }
 
 // DefaultMorpheCompileConfig creates a default configuration
+// Any additional registry paths are loaded and merged into the primary registry
 func DefaultMorpheCompileConfig(
 	yamlRegistryPath string,
 	baseOutputDirPath string,
+	additionalRegistryPaths ...string,
 ) MorpheCompileConfig {
+	var additionalRegistries []rcfg.MorpheLoadRegistryConfig
+	for _, registryPath := range additionalRegistryPaths {
+		additionalRegistries = append(additionalRegistries, newRegistryLoadConfig(registryPath))
+	}
+
 	return MorpheCompileConfig{
-		MorpheLoadRegistryConfig: rcfg.MorpheLoadRegistryConfig{
-			RegistryEnumsDirPath:      path.Join(yamlRegistryPath, "enums"),
-			RegistryModelsDirPath:     path.Join(yamlRegistryPath, "models"),
-			RegistryStructuresDirPath: path.Join(yamlRegistryPath, "structures"),
-			RegistryEntitiesDirPath:   path.Join(yamlRegistryPath, "entities"),
-		},
-		OutputPath: baseOutputDirPath,
+		MorpheLoadRegistryConfig: newRegistryLoadConfig(yamlRegistryPath),
+		AdditionalRegistries:     additionalRegistries,
+		OutputPath:               baseOutputDirPath,
 		FormatConfig: PydanticConfig{
-			PydanticV2:    true,
-			AddTypeHints:  true,
-			GenerateInit:  true,
-			IndentSize:    4,
-			PythonVersion: "3.8",
+			PydanticV2:               true,
+			AddTypeHints:             true,
+			GenerateInit:             true,
+			IndentSize:               4,
+			PythonVersion:            "3.8",
+			MaxLineLength:            88,
+			EmitPyTyped:              true,
+			FileNaming:               FileNamingSnake,
+			FieldCase:                FieldCaseSnake,
+			UnknownRelations:         UnknownRelationsError,
+			DocstringStyle:           DocstringStylePlain,
+			FileHeader:               DefaultFileHeader,
+			PolymorphicDiscriminator: DefaultPolymorphicDiscriminator,
 		},
 	}
 }
 
+// newRegistryLoadConfig builds the registry loading configuration for a registry root
+func newRegistryLoadConfig(yamlRegistryPath string) rcfg.MorpheLoadRegistryConfig {
+	return rcfg.MorpheLoadRegistryConfig{
+		RegistryEnumsDirPath:      path.Join(yamlRegistryPath, "enums"),
+		RegistryModelsDirPath:     path.Join(yamlRegistryPath, "models"),
+		RegistryStructuresDirPath: path.Join(yamlRegistryPath, "structures"),
+		RegistryEntitiesDirPath:   path.Join(yamlRegistryPath, "entities"),
+	}
+}
+
+// RegistryConfigs returns the primary registry configuration followed by any additional ones
+func (config MorpheCompileConfig) RegistryConfigs() []rcfg.MorpheLoadRegistryConfig {
+	configs := []rcfg.MorpheLoadRegistryConfig{config.MorpheLoadRegistryConfig}
+	return append(configs, config.AdditionalRegistries...)
+}
+
+// output returns the writer verbose progress and the compilation summary are written to
+func (config MorpheCompileConfig) output() io.Writer {
+	if config.Output == nil {
+		return os.Stdout
+	}
+	return config.Output
+}
+
+// logf reports compilation progress in verbose mode
+func (config MorpheCompileConfig) logf(format string, args ...any) {
+	if config.Verbose {
+		fmt.Fprintf(config.output(), format+"\n", args...)
+	}
+}
+
+// warnings returns the writer compiler warnings are reported to
+func (config MorpheCompileConfig) warnings() io.Writer {
+	if config.Warnings == nil {
+		return os.Stderr
+	}
+	return config.Warnings
+}
+
+// warnf reports a compiler warning
+func (config MorpheCompileConfig) warnf(format string, args ...any) {
+	fmt.Fprintf(config.warnings(), "Warning: "+format+"\n", args...)
+}
+
+// packageNamePattern matches a single Python package name
+var packageNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isDottedPackagePath reports whether a path such as "mycompany.generated" is made of valid,
+// non-keyword Python package names
+func isDottedPackagePath(packagePath string) bool {
+	for _, packageName := range strings.Split(packagePath, ".") {
+		if !packageNamePattern.MatchString(packageName) || IsPythonKeyword(packageName) {
+			return false
+		}
+	}
+	return true
+}
+
 // Validate checks if the configuration is valid
 func (config MorpheCompileConfig) Validate() error {
 	// Validate registry paths
-	if err := config.MorpheLoadRegistryConfig.Validate(); err != nil {
-		return err
+	for _, registryConfig := range config.RegistryConfigs() {
+		if err := registryConfig.Validate(); err != nil {
+			return err
+		}
 	}
 
-	// TODO: Add format-specific validation
-	// Examples:
-	// - Check if package prefix is valid
-	// - Verify indent size is positive
-	// - Ensure file extension starts with "."
+	if config.FormatConfig.IndentSize <= 0 {
+		return &ConfigValidationError{Option: "indentSize", Reason: "must be positive"}
+	}
+	if config.FormatConfig.MaxLineLength < 0 {
+		return &ConfigValidationError{Option: "maxLineLength", Reason: "must not be negative"}
+	}
+	for morpheType, customType := range config.FormatConfig.CustomTypeMappings {
+		if customType.Type == "" {
+			return &ConfigValidationError{Option: "customTypeMappings", Reason: fmt.Sprintf("%s has no Python type", morpheType)}
+		}
+	}
+	if config.FormatConfig.GenerateStubPackage && config.stubPackageName() == "" {
+		return &ConfigValidationError{Option: "stubPackageName", Reason: "required when the output path has no directory name"}
+	}
+	switch config.FormatConfig.JSONType {
+	case "", JSONTypeDict:
+	case JSONTypeJSONValue:
+		if !config.FormatConfig.PydanticV2 {
+			return &ConfigValidationError{Option: "jsonType", Reason: "jsonvalue requires Pydantic v2"}
+		}
+	default:
+		return &ConfigValidationError{
+			Option: "jsonType",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.FormatConfig.JSONType, JSONTypeDict, JSONTypeJSONValue),
+		}
+	}
+	switch config.FormatConfig.DocstringStyle {
+	case "", DocstringStylePlain, DocstringStyleGoogle, DocstringStyleNumPy:
+	default:
+		return &ConfigValidationError{
+			Option: "docstringStyle",
+			Reason: fmt.Sprintf("%s (must be '%s', '%s' or '%s')", config.FormatConfig.DocstringStyle, DocstringStylePlain, DocstringStyleGoogle, DocstringStyleNumPy),
+		}
+	}
+	seenSources := make(map[string]bool)
+	for _, source := range config.FormatConfig.TypeResolutionOrder {
+		if !typemap.IsResolutionSource(source) || seenSources[source] {
+			return &ConfigValidationError{
+				Option: "typeResolutionOrder",
+				Reason: fmt.Sprintf("%s (must list '%s', '%s' or '%s' at most once each)", source, typemap.SourceCustom, typemap.SourceRegistered, typemap.SourceBuiltin),
+			}
+		}
+		seenSources[source] = true
+	}
+	switch config.FormatConfig.GenerateGraph {
+	case "", GraphFormatDOT, GraphFormatJSON:
+	default:
+		return &ConfigValidationError{
+			Option: "generateGraph",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.FormatConfig.GenerateGraph, GraphFormatDOT, GraphFormatJSON),
+		}
+	}
+	switch config.FormatConfig.FileNaming {
+	case "", FileNamingSnake, FileNamingPascal, FileNamingAsIs:
+	default:
+		return &ConfigValidationError{
+			Option: "fileNaming",
+			Reason: fmt.Sprintf("%s (must be '%s', '%s' or '%s')", config.FormatConfig.FileNaming, FileNamingSnake, FileNamingPascal, FileNamingAsIs),
+		}
+	}
+	switch config.FormatConfig.UnknownRelations {
+	case "", UnknownRelationsError, UnknownRelationsWarn:
+	default:
+		return &ConfigValidationError{
+			Option: "unknownRelations",
+			Reason: fmt.Sprintf("%s (must be '%s' or '%s')", config.FormatConfig.UnknownRelations, UnknownRelationsError, UnknownRelationsWarn),
+		}
+	}
+	switch config.FormatConfig.FieldCase {
+	case "", FieldCaseSnake, FieldCaseAsIs, FieldCaseCamel:
+	default:
+		return &ConfigValidationError{
+			Option: "fieldCase",
+			Reason: fmt.Sprintf("%s (must be '%s', '%s' or '%s')", config.FormatConfig.FieldCase, FieldCaseSnake, FieldCaseAsIs, FieldCaseCamel),
+		}
+	}
+	if config.FormatConfig.RootPackage != "" && !isDottedPackagePath(config.FormatConfig.RootPackage) {
+		return &ConfigValidationError{
+			Option: "rootPackage",
+			Reason: fmt.Sprintf("%s (must be a dotted path of Python identifiers)", config.FormatConfig.RootPackage),
+		}
+	}
+	if discriminator := config.FormatConfig.polymorphicDiscriminator(); discriminator != "" && !packageNamePattern.MatchString(discriminator) {
+		return &ConfigValidationError{
+			Option: "polymorphicDiscriminator",
+			Reason: fmt.Sprintf("%s (must be an identifier)", discriminator),
+		}
+	}
+	// Pydantic v1 has a single alias used both to read and to write a field
+	models := config.MorpheConfig.Models
+	if !config.FormatConfig.PydanticV2 && models.ValidationAlias != "" && models.SerializationAlias != "" && models.ValidationAlias != models.SerializationAlias {
+		return &ConfigValidationError{Option: "models.serializationAlias", Reason: "distinct validation and serialization aliases require Pydantic v2"}
+	}
+	// The single models module is always rewritten as a whole
+	if len(models.OnlyModels) > 0 && config.FormatConfig.SingleFile {
+		return &ConfigValidationError{Option: "models.onlyModels", Reason: "not supported with singleFile"}
+	}
+	if _, err := LoadModelFileTemplate(config.FormatConfig.FileTemplatePath); err != nil {
+		return &ConfigValidationError{Option: "fileTemplatePath", Reason: err.Error()}
+	}
 
-	return nil
+	return config.MorpheConfig.Validate()
 }
@@ -1,7 +1,9 @@
 package compile
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,10 +15,31 @@ type MorpheWriter struct {
 	OutputPath string
 	// Configuration with sensible defaults
 	FileExtension      string
-	UseMultiFile       bool // Default: true (one file per type)
-	CreateIndexFile    bool // Default: true (create index that imports all)
-	IndentSize         int  // Default: 2 or 4 depending on format
-	AddGeneratedHeader bool // Default: true
+	UseMultiFile       bool   // Default: true (one file per type)
+	CreateIndexFile    bool   // Default: true (create index that imports all)
+	IndentSize         int    // Default: 2 or 4 depending on format
+	AddGeneratedHeader bool   // Default: true
+	FileHeader         string // Banner written as comment lines atop every module (default: DefaultFileHeader)
+	FileNaming         string // Module naming strategy shared with import paths (default: "snake")
+	RootPackage        string // Dotted package the output is nested under (default: the output directory itself)
+	PydanticV2         bool   // Resolve forward references of a single-file bundle with model_rebuild()
+	MaxLineLength      int    // Wrap the merged imports of a single-file bundle longer than this (0 disables)
+
+	// RebuildModels are the models whose forward references the models __init__.py resolves once
+	// every model is imported, in dependency order
+	RebuildModels []string
+	// RebuildEntities are the entities whose forward references the entities __init__.py resolves
+	// the same way
+	RebuildEntities []string
+	// DeferredEnums are the enums models import under TYPE_CHECKING, which the models
+	// __init__.py imports so their forward references resolve
+	DeferredEnums []string
+
+	// bundle collects the type contents per category merged into the single-file module
+	bundle map[string]map[string][]byte
+
+	// files collects output in memory (keyed by relative path) instead of writing to disk
+	files map[string]string
 }
 
 // NewMorpheWriter creates a new MorpheWriter instance with sensible defaults
@@ -28,15 +51,93 @@ func NewMorpheWriter(outputPath string) *MorpheWriter {
 		CreateIndexFile:    true,
 		IndentSize:         4,
 		AddGeneratedHeader: true,
+		FileHeader:         DefaultFileHeader,
+		FileNaming:         FileNamingSnake,
+		PydanticV2:         true,
 	}
 }
 
-// getGeneratedHeader returns a header comment for generated files
-func (w *MorpheWriter) getGeneratedHeader() string {
-	return `# Code generated by Morphe
-# Source: Morphe Registry
+// NewMemoryWriter creates a MorpheWriter that collects output in memory instead of on disk
+func NewMemoryWriter() *MorpheWriter {
+	w := NewMorpheWriter("")
+	w.files = make(map[string]string)
+	return w
+}
+
+// Files returns the collected output keyed by relative path (memory writers only)
+func (w *MorpheWriter) Files() map[string]string {
+	return w.files
+}
+
+// WriteFiles writes already rendered files, keyed by path relative to the output path
+func (w *MorpheWriter) WriteFiles(files map[string]string) error {
+	relPaths := make([]string, 0, len(files))
+	for relPath := range files {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+	for _, relPath := range relPaths {
+		if err := w.persist(filepath.Join(w.OutputPath, filepath.FromSlash(relPath)), []byte(files[relPath])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-`
+// StaleFile is a generated file whose content differs from the file in the output directory
+type StaleFile struct {
+	Path    string // Relative to the output path
+	Missing bool   // The file doesn't exist in the output directory
+}
+
+// String returns the path, marking files missing from the output directory
+func (f StaleFile) String() string {
+	if f.Missing {
+		return f.Path + " (missing)"
+	}
+	return f.Path
+}
+
+// StaleFiles compares already rendered files, keyed by path relative to the output path, with
+// the files on disk byte for byte, returning the differing and missing ones sorted by path
+func (w *MorpheWriter) StaleFiles(files map[string]string) ([]StaleFile, error) {
+	var stale []StaleFile
+	for relPath, content := range files {
+		existing, err := os.ReadFile(filepath.Join(w.OutputPath, filepath.FromSlash(relPath)))
+		if errors.Is(err, fs.ErrNotExist) {
+			stale = append(stale, StaleFile{Path: relPath, Missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		if string(existing) != content {
+			stale = append(stale, StaleFile{Path: relPath})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].Path < stale[j].Path
+	})
+	return stale, nil
+}
+
+// getGeneratedHeader returns the file header as comment lines followed by a blank line, or
+// nothing when the header is empty
+func (w *MorpheWriter) getGeneratedHeader() string {
+	if w.FileHeader == "" {
+		return ""
+	}
+	var header strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(w.FileHeader, "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			header.WriteString("#\n")
+			continue
+		}
+		header.WriteString("# " + line + "\n")
+	}
+	header.WriteString("\n")
+	return header.String()
 }
 
 // ensureDir creates a directory if it doesn't exist
@@ -52,6 +153,20 @@ func (w *MorpheWriter) writeFile(path string, content []byte) error {
 		content = append(header, content...)
 	}
 
+	return w.persist(path, content)
+}
+
+// persist stores final file content, either in memory or on disk
+func (w *MorpheWriter) persist(path string, content []byte) error {
+	if w.files != nil {
+		relPath, err := filepath.Rel(w.OutputPath, path)
+		if err != nil {
+			return err
+		}
+		w.files[filepath.ToSlash(relPath)] = string(content)
+		return nil
+	}
+
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := w.ensureDir(dir); err != nil {
@@ -63,29 +178,64 @@ func (w *MorpheWriter) writeFile(path string, content []byte) error {
 
 // WriteEnum writes a single enum definition to a file
 func (w *MorpheWriter) WriteEnum(enumName string, content []byte) error {
-	fileName := toFileName(enumName) + w.FileExtension
-	filePath := filepath.Join(w.OutputPath, "enums", fileName)
+	fileName := w.fileName(enumName) + w.FileExtension
+	filePath := filepath.Join(w.packageDir(), "enums", fileName)
 	return w.writeFile(filePath, content)
 }
 
-// WriteModel writes a single model definition to a file
+// WriteModel writes a single model definition to a file as is, since the model file template
+// places the header
 func (w *MorpheWriter) WriteModel(modelName string, content []byte) error {
-	fileName := toFileName(modelName) + w.FileExtension
-	filePath := filepath.Join(w.OutputPath, "models", fileName)
+	fileName := w.fileName(modelName) + w.FileExtension
+	filePath := filepath.Join(w.packageDir(), "models", fileName)
+	return w.persist(filePath, content)
+}
+
+// WriteModelStub writes a model's .pyi type stub next to its module
+func (w *MorpheWriter) WriteModelStub(modelName string, content []byte) error {
+	fileName := w.fileName(modelName) + ".pyi"
+	filePath := filepath.Join(w.packageDir(), "models", fileName)
 	return w.writeFile(filePath, content)
 }
 
 // WriteStructure writes a single structure definition to a file
 func (w *MorpheWriter) WriteStructure(structureName string, content []byte) error {
-	fileName := toFileName(structureName) + w.FileExtension
-	filePath := filepath.Join(w.OutputPath, "structures", fileName)
+	fileName := w.fileName(structureName) + w.FileExtension
+	filePath := filepath.Join(w.packageDir(), "structures", fileName)
 	return w.writeFile(filePath, content)
 }
 
 // WriteEntity writes a single entity definition to a file
 func (w *MorpheWriter) WriteEntity(entityName string, content []byte) error {
-	fileName := toFileName(entityName) + w.FileExtension
-	filePath := filepath.Join(w.OutputPath, "entities", fileName)
+	fileName := w.fileName(entityName) + w.FileExtension
+	filePath := filepath.Join(w.packageDir(), "entities", fileName)
+	return w.writeFile(filePath, content)
+}
+
+// WriteSentinels writes the shared sentinel module at the package root
+func (w *MorpheWriter) WriteSentinels(content []byte) error {
+	filePath := filepath.Join(w.packageDir(), sentinelsModuleName+w.FileExtension)
+	return w.writeFile(filePath, content)
+}
+
+// WritePyTyped writes the empty PEP 561 py.typed marker at the package root
+func (w *MorpheWriter) WritePyTyped() error {
+	return w.persist(filepath.Join(w.packageDir(), "py.typed"), []byte{})
+}
+
+// WriteJSONSchema writes the JSON Schema document at the package root
+func (w *MorpheWriter) WriteJSONSchema(content []byte) error {
+	return w.persist(filepath.Join(w.packageDir(), "schema.json"), content)
+}
+
+// WriteGraph writes the model relationship graph export (graph.dot or graph.json) at the package root
+func (w *MorpheWriter) WriteGraph(format string, content []byte) error {
+	return w.persist(filepath.Join(w.packageDir(), "graph."+format), content)
+}
+
+// WriteConstants writes the module-level constants module at the package root
+func (w *MorpheWriter) WriteConstants(content []byte) error {
+	filePath := filepath.Join(w.packageDir(), "constants"+w.FileExtension)
 	return w.writeFile(filePath, content)
 }
 
@@ -93,8 +243,8 @@ func (w *MorpheWriter) WriteEntity(entityName string, content []byte) error {
 func (w *MorpheWriter) WriteAllEnums(enumContents map[string][]byte) error {
 	if w.UseMultiFile {
 		// Write each enum to a separate file
-		for enumName, content := range enumContents {
-			if err := w.WriteEnum(enumName, content); err != nil {
+		for _, enumName := range sortedNames(enumContents) {
+			if err := w.WriteEnum(enumName, enumContents[enumName]); err != nil {
 				return err
 			}
 		}
@@ -113,8 +263,8 @@ func (w *MorpheWriter) WriteAllEnums(enumContents map[string][]byte) error {
 // WriteAllModels writes multiple model definitions
 func (w *MorpheWriter) WriteAllModels(modelContents map[string][]byte) error {
 	if w.UseMultiFile {
-		for modelName, content := range modelContents {
-			if err := w.WriteModel(modelName, content); err != nil {
+		for _, modelName := range sortedNames(modelContents) {
+			if err := w.WriteModel(modelName, modelContents[modelName]); err != nil {
 				return err
 			}
 		}
@@ -128,11 +278,30 @@ func (w *MorpheWriter) WriteAllModels(modelContents map[string][]byte) error {
 	return w.writeSingleFile("models", modelContents)
 }
 
+// WriteModelSubset writes only the given model definitions while keeping the models index
+// listing every model in allModelNames, so previously generated files stay importable
+func (w *MorpheWriter) WriteModelSubset(modelContents map[string][]byte, allModelNames []string) error {
+	for _, modelName := range sortedNames(modelContents) {
+		if err := w.WriteModel(modelName, modelContents[modelName]); err != nil {
+			return err
+		}
+	}
+
+	if w.CreateIndexFile {
+		indexContents := make(map[string][]byte)
+		for _, modelName := range allModelNames {
+			indexContents[modelName] = nil
+		}
+		return w.writeModelIndex(indexContents)
+	}
+	return nil
+}
+
 // WriteAllStructures writes multiple structure definitions
 func (w *MorpheWriter) WriteAllStructures(structureContents map[string][]byte) error {
 	if w.UseMultiFile {
-		for structureName, content := range structureContents {
-			if err := w.WriteStructure(structureName, content); err != nil {
+		for _, structureName := range sortedNames(structureContents) {
+			if err := w.WriteStructure(structureName, structureContents[structureName]); err != nil {
 				return err
 			}
 		}
@@ -149,8 +318,8 @@ func (w *MorpheWriter) WriteAllStructures(structureContents map[string][]byte) e
 // WriteAllEntities writes multiple entity definitions
 func (w *MorpheWriter) WriteAllEntities(entityContents map[string][]byte) error {
 	if w.UseMultiFile {
-		for entityName, content := range entityContents {
-			if err := w.WriteEntity(entityName, content); err != nil {
+		for _, entityName := range sortedNames(entityContents) {
+			if err := w.WriteEntity(entityName, entityContents[entityName]); err != nil {
 				return err
 			}
 		}
@@ -164,105 +333,134 @@ func (w *MorpheWriter) WriteAllEntities(entityContents map[string][]byte) error
 	return w.writeSingleFile("entities", entityContents)
 }
 
-// Index file generators - creates a file that imports/exports all types
+// writeEnumIndex writes the enums package __init__.py importing every enum
 func (w *MorpheWriter) writeEnumIndex(contents map[string][]byte) error {
-	// Python __init__.py file
-	var imports []string
-	for enumName := range contents {
-		fileName := toFileName(enumName)
-		imports = append(imports, fmt.Sprintf("from .%s import %s", fileName, enumName))
-	}
-
-	sort.Strings(imports)
-	content := []byte(strings.Join(imports, "\n"))
-	content = append(content, '\n')
-
-	filePath := filepath.Join(w.OutputPath, "enums", "__init__.py")
-	return w.writeFile(filePath, content)
+	return w.writeIndex("enums", contents, nil)
 }
 
+// writeModelIndex writes the models package __init__.py importing every model and the
+// DeferredEnums, then resolving the forward references of the RebuildModels, which may form
+// import cycles
 func (w *MorpheWriter) writeModelIndex(contents map[string][]byte) error {
-	var imports []string
-	for modelName := range contents {
-		fileName := toFileName(modelName)
-		imports = append(imports, fmt.Sprintf("from .%s import %s", fileName, modelName))
+	var enumImports []string
+	for _, enumName := range w.DeferredEnums {
+		enumImports = append(enumImports, fmt.Sprintf("from ..enums.%s import %s", w.fileName(enumName), enumName))
 	}
-
-	sort.Strings(imports)
-	content := []byte(strings.Join(imports, "\n"))
-	content = append(content, '\n')
-
-	filePath := filepath.Join(w.OutputPath, "models", "__init__.py")
-	return w.writeFile(filePath, content)
+	return w.writeIndex("models", contents, enumImports, w.forwardRefRebuilds(w.RebuildModels)...)
 }
 
-func (w *MorpheWriter) writeStructureIndex(contents map[string][]byte) error {
-	var imports []string
-	for structureName := range contents {
-		fileName := toFileName(structureName)
-		imports = append(imports, fmt.Sprintf("from .%s import %s", fileName, structureName))
+// forwardRefRebuilds returns the statements resolving the forward references of the given
+// classes once their package has imported every class
+func (w *MorpheWriter) forwardRefRebuilds(classNames []string) []string {
+	var rebuilds []string
+	for _, className := range classNames {
+		// Pydantic v1 only resolves against the class's own module unless given a namespace
+		if w.PydanticV2 {
+			rebuilds = append(rebuilds, className+".model_rebuild()")
+		} else {
+			rebuilds = append(rebuilds, className+".update_forward_refs(**globals())")
+		}
 	}
+	return rebuilds
+}
 
-	sort.Strings(imports)
-	content := []byte(strings.Join(imports, "\n"))
-	content = append(content, '\n')
-
-	filePath := filepath.Join(w.OutputPath, "structures", "__init__.py")
-	return w.writeFile(filePath, content)
+// writeStructureIndex writes the structures package __init__.py importing every structure
+func (w *MorpheWriter) writeStructureIndex(contents map[string][]byte) error {
+	return w.writeIndex("structures", contents, nil)
 }
 
+// writeEntityIndex writes the entities package __init__.py importing every entity, then
+// resolving the forward references of the RebuildEntities, which may form import cycles
 func (w *MorpheWriter) writeEntityIndex(contents map[string][]byte) error {
-	var imports []string
-	for entityName := range contents {
-		fileName := toFileName(entityName)
-		imports = append(imports, fmt.Sprintf("from .%s import %s", fileName, entityName))
+	return w.writeIndex("entities", contents, nil, w.forwardRefRebuilds(w.RebuildEntities)...)
+}
+
+// writeIndex writes a package __init__.py importing every type of the content map and the
+// extra imports, one import per line sorted by module, followed by the given statements
+func (w *MorpheWriter) writeIndex(packageDir string, contents map[string][]byte, extraImports []string, statements ...string) error {
+	imports := append([]string{}, extraImports...)
+	for _, typeName := range sortedNames(contents) {
+		imports = append(imports, fmt.Sprintf("from .%s import %s", w.fileName(typeName), typeName))
 	}
 
 	sort.Strings(imports)
 	content := []byte(strings.Join(imports, "\n"))
 	content = append(content, '\n')
+	if len(statements) > 0 {
+		content = append(content, []byte("\n\n"+strings.Join(statements, "\n")+"\n")...)
+	}
 
-	filePath := filepath.Join(w.OutputPath, "entities", "__init__.py")
+	filePath := filepath.Join(w.packageDir(), packageDir, "__init__.py")
 	return w.writeFile(filePath, content)
 }
 
-// writeSingleFile writes all content of a type to a single file
-func (w *MorpheWriter) writeSingleFile(typeName string, contents map[string][]byte) error {
-	var combined []byte
+// sortedNames returns the type names of a content map in sorted order, so files are written,
+// logged and indexed the same way on every run
+func sortedNames(contents map[string][]byte) []string {
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-	// Add file header
-	if w.AddGeneratedHeader {
-		combined = append(combined, []byte(w.getGeneratedHeader())...)
+// writeSingleFile adds the contents of a type category to the single-file bundle and rewrites
+// the bundle module, so it holds every category written so far
+func (w *MorpheWriter) writeSingleFile(category string, contents map[string][]byte) error {
+	if w.bundle == nil {
+		w.bundle = make(map[string]map[string][]byte)
 	}
+	w.bundle[category] = contents
+
+	filePath := filepath.Join(w.packageDir(), bundleModuleName+w.FileExtension)
+	return w.writeFile(filePath, renderBundle(w.bundle, w.PydanticV2, w.MaxLineLength))
+}
 
-	// Combine all contents
-	for name, content := range contents {
-		combined = append(combined, []byte(fmt.Sprintf("\n// --- %s ---\n", name))...)
-		combined = append(combined, content...)
-		combined = append(combined, '\n')
+// packageDir returns the directory of the root package: the output directory, or the nested
+// directory of the dotted root package (e.g. mycompany/generated/schemas)
+func (w *MorpheWriter) packageDir() string {
+	if w.RootPackage == "" {
+		return w.OutputPath
 	}
+	return filepath.Join(w.OutputPath, filepath.Join(strings.Split(w.RootPackage, ".")...))
+}
 
-	// Write to single file
-	fileName := typeName + w.FileExtension
-	filePath := filepath.Join(w.OutputPath, fileName)
-	return os.WriteFile(filePath, combined, 0644)
-}
-
-// Helper function to convert type names to file names
-func toFileName(typeName string) string {
-	// TODO: Adjust for your format's file naming conventions
-	// Examples:
-	// - PascalCase → snake_case for Python
-	// - PascalCase → kebab-case for some formats
-	// - Keep PascalCase for C#/Java
-
-	// Default: convert to lowercase with underscores
-	var result []rune
-	for i, r := range typeName {
-		if i > 0 && 'A' <= r && r <= 'Z' {
-			result = append(result, '_')
+// WriteNamespaceInits writes an __init__.py for every package of the root package path, so
+// each level is importable as a regular package
+func (w *MorpheWriter) WriteNamespaceInits() error {
+	if w.RootPackage == "" {
+		return nil
+	}
+	dir := w.OutputPath
+	for _, packageName := range strings.Split(w.RootPackage, ".") {
+		dir = filepath.Join(dir, packageName)
+		if err := w.writeFile(filepath.Join(dir, "__init__.py"), nil); err != nil {
+			return err
 		}
-		result = append(result, r)
 	}
-	return strings.ToLower(string(result))
+	return nil
+}
+
+// fileName returns the module name of a type under the writer's file naming strategy
+func (w *MorpheWriter) fileName(typeName string) string {
+	return ModuleName(typeName, w.FileNaming)
+}
+
+// useConfig applies the format options that affect file placement and headers
+func (w *MorpheWriter) useConfig(config PydanticConfig) {
+	w.FileHeader = config.FileHeader
+	if w.FileHeader == "" {
+		w.FileHeader = DefaultFileHeader
+	}
+	if config.DisableFileHeader {
+		w.FileHeader = ""
+	}
+	w.RootPackage = config.RootPackage
+	w.UseMultiFile = !config.SingleFile
+	w.PydanticV2 = config.PydanticV2
+	w.MaxLineLength = config.MaxLineLength
+	if config.FileNaming != "" {
+		w.FileNaming = config.FileNaming
+	}
 }
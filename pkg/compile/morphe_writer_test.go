@@ -0,0 +1,36 @@
+package compile_test
+
+import (
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestMorpheWriter_SortedIndex() {
+	writer := compile.NewMemoryWriter()
+	contents := map[string][]byte{"Zone": nil, "Account": nil, "Member": nil, "Billing": nil}
+
+	suite.Require().NoError(writer.WriteAllModels(contents))
+
+	suite.Equal(`# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from .account import Account
+from .billing import Billing
+from .member import Member
+from .zone import Zone
+`, writer.Files()["models/__init__.py"])
+}
+
+func (suite *CompileTestSuite) TestMorpheWriter_SortedSingleFile() {
+	contents := map[string][]byte{"Zone": []byte("zone"), "Account": []byte("account"), "Member": []byte("member")}
+
+	for i := 0; i < 5; i++ {
+		writer := compile.NewMemoryWriter()
+		writer.UseMultiFile = false
+		suite.Require().NoError(writer.WriteAllEnums(contents))
+
+		content := writer.Files()["models.py"]
+		suite.Less(strings.Index(content, "account"), strings.Index(content, "member"))
+		suite.Less(strings.Index(content, "member"), strings.Index(content, "zone"))
+	}
+}
@@ -1,5 +1,10 @@
 package compile
 
+import (
+	"strings"
+	"unicode"
+)
+
 // pythonKeywords contains all Python 3.8+ reserved keywords
 var pythonKeywords = map[string]bool{
 	// Boolean values
@@ -81,8 +86,16 @@ var pythonBuiltins = map[string]bool{
 	"zip":    true,
 }
 
-// SanitizePythonIdentifier ensures a name is safe to use as a Python identifier
+// SanitizePythonIdentifier ensures a name is safe to use as a Python identifier. Characters that
+// are not letters, digits or underscores are replaced with underscores.
 func SanitizePythonIdentifier(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+
 	// Check if it's a keyword
 	if pythonKeywords[name] {
 		return name + "_"
@@ -0,0 +1,138 @@
+package compile
+
+import (
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yamlops"
+)
+
+// Type categories tracked while selecting types
+const (
+	selectEnum      = "enum"
+	selectModel     = "model"
+	selectStructure = "structure"
+	selectEntity    = "entity"
+)
+
+// selectedType is a type name within one category of the registry
+type selectedType struct {
+	Kind string
+	Name string
+}
+
+// SelectTypes returns a registry holding only the named types together with every type they
+// depend on: the enums and structures referenced by their fields, their related models and
+// entities, and the models entity fields are read from. A name matches every enum, model,
+// structure and entity carrying it.
+func SelectTypes(r *registry.Registry, names []string) (*registry.Registry, error) {
+	enums := r.GetAllEnums()
+	models := r.GetAllModels()
+	structures := r.GetAllStructures()
+	entities := r.GetAllEntities()
+
+	visited := make(map[selectedType]bool)
+	queue := []selectedType{}
+	enqueue := func(kind string, name string) {
+		key := selectedType{Kind: kind, Name: name}
+		if !visited[key] {
+			visited[key] = true
+			queue = append(queue, key)
+		}
+	}
+	// Field types may name either an enum or a structure
+	enqueueFieldType := func(fieldType string) {
+		if _, exists := enums[fieldType]; exists {
+			enqueue(selectEnum, fieldType)
+		}
+		if _, exists := structures[fieldType]; exists {
+			enqueue(selectStructure, fieldType)
+		}
+	}
+
+	for _, name := range names {
+		found := false
+		if _, exists := enums[name]; exists {
+			enqueue(selectEnum, name)
+			found = true
+		}
+		if _, exists := models[name]; exists {
+			enqueue(selectModel, name)
+			found = true
+		}
+		if _, exists := structures[name]; exists {
+			enqueue(selectStructure, name)
+			found = true
+		}
+		if _, exists := entities[name]; exists {
+			enqueue(selectEntity, name)
+			found = true
+		}
+		if !found {
+			return nil, ErrTypeNotFound(name)
+		}
+	}
+
+	selected := registry.NewRegistry()
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		switch current.Kind {
+		case selectEnum:
+			if enum, exists := enums[current.Name]; exists {
+				selected.SetEnum(current.Name, enum)
+			}
+		case selectModel:
+			model, exists := models[current.Name]
+			if !exists {
+				continue
+			}
+			selected.SetModel(current.Name, model)
+			for _, field := range model.Fields {
+				enqueueFieldType(string(field.Type))
+			}
+			for relName, relation := range model.Related {
+				for _, target := range relationTargets(relName, relation.Aliased, relation.For) {
+					enqueue(selectModel, target)
+				}
+			}
+		case selectStructure:
+			structure, exists := structures[current.Name]
+			if !exists {
+				continue
+			}
+			selected.SetStructure(current.Name, structure)
+			for _, field := range structure.Fields {
+				enqueueFieldType(string(field.Type))
+			}
+		case selectEntity:
+			entity, exists := entities[current.Name]
+			if !exists {
+				continue
+			}
+			selected.SetEntity(current.Name, entity)
+			for _, field := range entity.Fields {
+				// Models further along the path are reached through the root model's relationships
+				rootModel, _, _ := strings.Cut(string(field.Type), ".")
+				enqueue(selectModel, rootModel)
+			}
+			for relName, relation := range entity.Related {
+				for _, target := range relationTargets(relName, relation.Aliased, relation.For) {
+					enqueue(selectEntity, target)
+				}
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// relationTargets returns the type names a relationship points at: its polymorphic targets, or
+// its (possibly aliased) related type
+func relationTargets(relName string, aliased string, forTypes []string) []string {
+	if len(forTypes) > 0 {
+		return forTypes
+	}
+	return []string{yamlops.GetRelationTargetName(relName, aliased)}
+}
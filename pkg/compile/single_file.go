@@ -0,0 +1,239 @@
+package compile
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// bundleModuleName is the module every type is written to in single-file mode
+const bundleModuleName = "models"
+
+// bundleCategories are the type packages merged into the bundle, in the order they are written
+var bundleCategories = []string{"enums", "models", "structures"}
+
+// bundledModule is a generated type module split into its imports and its body
+type bundledModule struct {
+	Name         string
+	From         map[string][]string // Names imported per module, type module imports excluded
+	Statements   []string            // Plain "import x" statements
+	Dependencies []string            // Types of the bundle imported eagerly, which must be defined first
+	ForwardRefs  bool                // Types of the bundle imported under TYPE_CHECKING
+	Body         string
+}
+
+// parseBundledModule splits the content of a generated type module into its imports and its
+// body. Imports of other type modules are recorded as dependencies instead, since those types
+// live in the bundle itself; other relative imports are rebased onto the package root.
+func parseBundledModule(name string, content string) bundledModule {
+	module := bundledModule{Name: name, From: make(map[string][]string)}
+	lines := strings.Split(content, "\n")
+	typeChecking := false
+	i := 0
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		indented := strings.HasPrefix(lines[i], " ")
+		if trimmed == "" {
+			continue
+		}
+		if indented && !typeChecking {
+			break
+		}
+		if !indented {
+			typeChecking = trimmed == "if TYPE_CHECKING:"
+			if typeChecking {
+				continue
+			}
+		}
+
+		if strings.HasPrefix(trimmed, "import ") {
+			module.Statements = append(module.Statements, trimmed)
+		} else if strings.HasPrefix(trimmed, "from ") {
+			// Wrapped imports list their names on the following lines up to the closing bracket
+			statement := trimmed
+			if strings.HasSuffix(statement, "(") {
+				statement = strings.TrimSuffix(statement, "(")
+				for i+1 < len(lines) {
+					i++
+					next := strings.TrimSpace(lines[i])
+					if strings.HasPrefix(next, ")") {
+						break
+					}
+					statement += " " + next
+				}
+			}
+			module.addImport(statement, typeChecking)
+		} else if !typeChecking {
+			break
+		}
+	}
+	module.Body = strings.TrimRight(strings.Join(lines[i:], "\n"), "\n")
+	return module
+}
+
+// addImport records a from-import statement of the module
+func (module *bundledModule) addImport(statement string, typeChecking bool) {
+	fields := strings.Fields(statement)
+	if len(fields) < 4 || fields[2] != "import" {
+		module.Statements = append(module.Statements, statement)
+		return
+	}
+	source := fields[1]
+	var names []string
+	for _, name := range strings.Split(strings.Join(fields[3:], " "), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if isBundledTypeModule(source) {
+		if typeChecking {
+			module.ForwardRefs = true
+		} else {
+			module.Dependencies = append(module.Dependencies, names...)
+		}
+		return
+	}
+	if strings.HasPrefix(source, "..") {
+		source = strings.TrimPrefix(source, ".")
+	}
+	for _, name := range names {
+		if !containsString(module.From[source], name) {
+			module.From[source] = append(module.From[source], name)
+		}
+	}
+}
+
+// isBundledTypeModule reports whether a relative import refers to a type module merged into the
+// bundle: a sibling module, or a module of one of the type packages
+func isBundledTypeModule(source string) bool {
+	if strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "..") {
+		return true
+	}
+	for _, category := range bundleCategories {
+		if strings.HasPrefix(source, ".."+category+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// renderBundle merges the modules of every category into a single module: the deduplicated
+// imports, the type definitions of each category in dependency order, then the forward
+// reference resolution of the models importing others under TYPE_CHECKING
+func renderBundle(categories map[string]map[string][]byte, pydanticV2 bool, maxLineLength int) []byte {
+	from := make(map[string][]string)
+	var statements []string
+	var bodies []string
+	var rebuilds []string
+	for _, category := range bundleCategories {
+		var modules []bundledModule
+		for _, name := range sortedNames(categories[category]) {
+			modules = append(modules, parseBundledModule(name, string(categories[category][name])))
+		}
+		for _, module := range orderBundledModules(modules) {
+			for source, names := range module.From {
+				for _, name := range names {
+					if !containsString(from[source], name) {
+						from[source] = append(from[source], name)
+					}
+				}
+			}
+			for _, statement := range module.Statements {
+				if !containsString(statements, statement) {
+					statements = append(statements, statement)
+				}
+			}
+			if module.Body != "" {
+				bodies = append(bodies, module.Body)
+			}
+			if module.ForwardRefs {
+				rebuilds = append(rebuilds, forwardRefResolution(module.Name, pydanticV2))
+			}
+		}
+	}
+
+	// Forward references resolve against the bundle itself once every type is defined, so
+	// annotations naming a type defined further down must not be evaluated with the class
+	from["typing"] = removeString(from["typing"], "TYPE_CHECKING")
+	if len(rebuilds) > 0 {
+		from["__future__"] = []string{"annotations"}
+	}
+
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(maxLineLength)
+	writeImportSections(cb, from, statements)
+	content := strings.TrimRight(cb.String(), "\n")
+	if len(bodies) > 0 {
+		content += "\n\n\n" + strings.Join(bodies, "\n\n\n")
+	}
+	if len(rebuilds) > 0 {
+		content += "\n\n\n" + strings.Join(rebuilds, "\n")
+	}
+	return []byte(strings.TrimLeft(content, "\n") + "\n")
+}
+
+// stringLiteralPattern matches Python string literals, including the quotes of docstrings
+var stringLiteralPattern = regexp.MustCompile(`"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'`)
+
+// orderBundledModules orders modules so every module follows the modules it imports eagerly or
+// names outside string literals, keeping the given order among independent modules
+func orderBundledModules(modules []bundledModule) []bundledModule {
+	byName := make(map[string]bundledModule)
+	for _, module := range modules {
+		byName[module.Name] = module
+	}
+	for _, other := range modules {
+		reference := regexp.MustCompile(`\b` + regexp.QuoteMeta(other.Name) + `\b`)
+		for i, module := range modules {
+			code := stringLiteralPattern.ReplaceAllString(module.Body, `""`)
+			if module.Name != other.Name && reference.MatchString(code) {
+				modules[i].Dependencies = append(modules[i].Dependencies, other.Name)
+				byName[module.Name] = modules[i]
+			}
+		}
+	}
+
+	var ordered []bundledModule
+	visited := make(map[string]bool)
+	var visit func(module bundledModule)
+	visit = func(module bundledModule) {
+		if visited[module.Name] {
+			return
+		}
+		visited[module.Name] = true
+		deps := append([]string{}, module.Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if depModule, exists := byName[dep]; exists {
+				visit(depModule)
+			}
+		}
+		ordered = append(ordered, module)
+	}
+	for _, module := range modules {
+		visit(module)
+	}
+	return ordered
+}
+
+// forwardRefResolution renders the call resolving the forward references of a model once every
+// type of the bundle is defined
+func forwardRefResolution(typeName string, pydanticV2 bool) string {
+	if pydanticV2 {
+		return typeName + ".model_rebuild()"
+	}
+	return typeName + ".update_forward_refs()"
+}
+
+// removeString returns the slice without any occurrence of item
+func removeString(slice []string, item string) []string {
+	var result []string
+	for _, s := range slice {
+		if s != item {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package compile_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/kalo-build/morphe-go/pkg/registry"
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestSingleFile_Bundle() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.SingleFile = true
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Len(files, 2)
+	suite.Contains(files, "py.typed")
+	content := files["models.py"]
+	suite.True(strings.HasPrefix(content, `# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from __future__ import annotations
+
+from enum import Enum
+from typing import List, Optional
+
+from pydantic import BaseModel, Field
+
+
+class Nationality(Enum):
+`))
+	suite.NotContains(content, "from .")
+	suite.NotContains(content, "TYPE_CHECKING")
+	suite.Equal(1, strings.Count(content, "# Auto-generated"))
+	suite.Less(strings.Index(content, "class UniversalNumber(Enum):"), strings.Index(content, "class Company(BaseModel):"))
+	suite.Less(strings.Index(content, "class Person(BaseModel):"), strings.Index(content, "class Address(BaseModel):"))
+	suite.True(strings.HasSuffix(content, "\n\n\nCompany.model_rebuild()\nContactInfo.model_rebuild()\nPerson.model_rebuild()\n"))
+}
+
+func (suite *CompileTestSuite) TestSingleFile_WarnsAboutEntities() {
+	var warnings bytes.Buffer
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.SingleFile = true
+	config.Warnings = &warnings
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.NotContains(files, "entities/person.py")
+	suite.Equal("Warning: singleFile does not generate entities; skipped 2\n", warnings.String())
+}
+
+func (suite *CompileTestSuite) TestSingleFile_PydanticV1() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.SingleFile = true
+	config.FormatConfig.PydanticV2 = false
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.True(strings.HasSuffix(files["models.py"], "\nPerson.update_forward_refs()\n"))
+	suite.NotContains(files["models.py"], "model_rebuild")
+}
+
+// newShippingRegistry returns structures referencing each other in reverse alphabetical order
+func newShippingRegistry() *registry.Registry {
+	r := registry.NewRegistry()
+	r.SetStructure("Address", yaml.Structure{
+		Name: "Address",
+		Fields: map[string]yaml.StructureField{
+			"Street": {Type: yaml.StructureFieldTypeString},
+			"Zone":   {Type: yaml.StructureFieldType("Zone")},
+		},
+	})
+	r.SetStructure("Zone", yaml.Structure{
+		Name: "Zone",
+		Fields: map[string]yaml.StructureField{
+			"Code": {Type: yaml.StructureFieldTypeString},
+		},
+	})
+	return r
+}
+
+func (suite *CompileTestSuite) TestSingleFile_StructureDependencyOrder() {
+	config := compile.DefaultMorpheCompileConfig("", "")
+	config.FormatConfig.SingleFile = true
+
+	content := suite.generateSource(config, newShippingRegistry(), "models.py")
+
+	suite.Less(strings.Index(content, "class Zone(BaseModel):"), strings.Index(content, "class Address(BaseModel):"))
+	suite.NotContains(content, "from .zone import Zone")
+	suite.NotContains(content, "from __future__")
+	suite.NotContains(content, "model_rebuild")
+}
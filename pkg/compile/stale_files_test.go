@@ -0,0 +1,39 @@
+package compile_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestStaleOutputFiles_UpToDate() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), suite.T().TempDir())
+	suite.Require().NoError(compile.MorpheToPydantic(config))
+
+	stale, err := compile.StaleOutputFiles(config)
+
+	suite.Require().NoError(err)
+	suite.Empty(stale)
+}
+
+func (suite *CompileTestSuite) TestStaleOutputFiles_DifferingAndMissing() {
+	outputDirPath := suite.T().TempDir()
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), outputDirPath)
+	suite.Require().NoError(compile.MorpheToPydantic(config))
+	suite.Require().NoError(os.WriteFile(filepath.Join(outputDirPath, "models", "person.py"), []byte("# edited\n"), 0644))
+	suite.Require().NoError(os.Remove(filepath.Join(outputDirPath, "enums", "nationality.py")))
+
+	stale, err := compile.StaleOutputFiles(config)
+
+	suite.Require().NoError(err)
+	suite.Equal([]compile.StaleFile{
+		{Path: "enums/nationality.py", Missing: true},
+		{Path: "models/person.py"},
+	}, stale)
+	suite.Equal("enums/nationality.py (missing)", stale[0].String())
+
+	// Nothing is written while checking
+	_, statErr := os.Stat(filepath.Join(outputDirPath, "enums", "nationality.py"))
+	suite.True(os.IsNotExist(statErr))
+}
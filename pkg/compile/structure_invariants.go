@@ -0,0 +1,123 @@
+package compile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// invariantPattern matches simple "<Field> <operator> <Field or literal>" comparisons
+var invariantPattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(<=|>=|==|!=|<|>)\s*(.+?)\s*$`)
+
+// invariantLiteralPattern matches the literal operands an invariant may compare against
+var invariantLiteralPattern = regexp.MustCompile(`^(-?\d+(\.\d+)?|"[^"\\]*"|'[^'\\]*')$`)
+
+// invariantLiterals maps Morphe-style keyword literals to Python
+var invariantLiterals = map[string]string{
+	"true":  "True",
+	"false": "False",
+	"null":  "None",
+}
+
+// translateInvariant translates a cross-field invariant to a Python condition, reading fields from
+// self or, in a Pydantic v1 validator, the values dict. It reports false when the expression
+// can't be translated mechanically.
+func translateInvariant(expression string, structure *formatdef.Struct, config PydanticConfig, fromSelf bool) (string, bool) {
+	match := invariantPattern.FindStringSubmatch(expression)
+	if match == nil {
+		return "", false
+	}
+
+	left, isField := invariantFieldRef(match[1], structure, config, fromSelf)
+	if !isField {
+		return "", false
+	}
+	right, isField := invariantFieldRef(match[3], structure, config, fromSelf)
+	if !isField {
+		if literal, isKeyword := invariantLiterals[match[3]]; isKeyword {
+			right = literal
+		} else if invariantLiteralPattern.MatchString(match[3]) {
+			right = match[3]
+		} else {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%s %s %s", left, match[2], right), true
+}
+
+// invariantFieldRef renders a reference to a structure field, or reports false for non-fields
+func invariantFieldRef(name string, structure *formatdef.Struct, config PydanticConfig, fromSelf bool) (string, bool) {
+	for _, field := range structure.Fields {
+		if field.Name != name || field.IsClassVar {
+			continue
+		}
+		fieldName := config.pythonFieldName(field.Name)
+		if fromSelf {
+			return "self." + fieldName, true
+		}
+		return fmt.Sprintf("values.get(%q)", fieldName), true
+	}
+	return "", false
+}
+
+// generateInvariantValidators adds one validator method per cross-field invariant: a
+// @model_validator(mode="after") in Pydantic v2, or a @root_validator in v1
+func generateInvariantValidators(cb *formatdef.ContentBuilder, structure *formatdef.Struct, invariants []string, config PydanticConfig) {
+	for i, expression := range invariants {
+		cb.Line("")
+		if config.PydanticV2 {
+			cb.Line(`@model_validator(mode="after")`)
+			cb.Line("def check_invariant_%d(self):", i+1)
+		} else {
+			cb.Line("@root_validator(skip_on_failure=True)")
+			cb.Line("def check_invariant_%d(cls, values):", i+1)
+		}
+		cb.Indent()
+		cb.Line(`"""Invariant: %s"""`, strings.Join(docstringLines(expression), " "))
+		writeInvariantCheck(cb, expression, structure, config, config.PydanticV2)
+		if config.PydanticV2 {
+			cb.Line("return self")
+		} else {
+			cb.Line("return values")
+		}
+		cb.Dedent()
+	}
+}
+
+// writeInvariantCheck writes the statement raising ValueError when an invariant is violated, or a
+// TODO comment when the expression can't be translated
+func writeInvariantCheck(cb *formatdef.ContentBuilder, expression string, structure *formatdef.Struct, config PydanticConfig, fromSelf bool) {
+	condition, ok := translateInvariant(expression, structure, config, fromSelf)
+	if !ok {
+		cb.Line("# TODO: translate this invariant to Python and raise ValueError when it is violated")
+		return
+	}
+	cb.Line("if not (%s):", condition)
+	cb.Indent()
+	cb.Line("raise ValueError(%q)", "invariant violated: "+expression)
+	cb.Dedent()
+}
+
+// writeDataclassPostInit adds a __post_init__ method to a dataclass structure checking its
+// cross-field invariants, then its field groups, in the order the validators of a Pydantic
+// structure run
+func writeDataclassPostInit(cb *formatdef.ContentBuilder, structure *formatdef.Struct, invariants []string, groups []fieldGroup, config PydanticConfig) {
+	if len(invariants) == 0 && len(groups) == 0 {
+		return
+	}
+	cb.Line("")
+	cb.Line("def __post_init__(self):")
+	cb.Indent()
+	cb.Line(`"""Check the invariants and field groups of the structure."""`)
+	for _, expression := range invariants {
+		cb.Line("# Invariant: %s", strings.Join(docstringLines(expression), " "))
+		writeInvariantCheck(cb, expression, structure, config, true)
+	}
+	for _, group := range groups {
+		cb.Line("# Field group %s", group.name)
+		writeFieldGroupCheck(cb, group, true)
+	}
+	cb.Dedent()
+}
@@ -0,0 +1,46 @@
+package compile
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// stubPackageMarker is the py.typed content of a PEP 561 stub-only package
+const stubPackageMarker = "partial\n"
+
+// stubPackageName returns the distribution name the stub package is derived from: the
+// configured name, or the output directory name
+func (config MorpheCompileConfig) stubPackageName() string {
+	if config.FormatConfig.StubPackageName != "" {
+		return config.FormatConfig.StubPackageName
+	}
+	name := filepath.Base(config.OutputPath)
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
+
+// addStubPackage adds a PEP 561 stub-only package as a sibling of the output directory. Every
+// generated module gets a .pyi counterpart, preferring the model .pyi stubs when they exist, and
+// the package is marked partial so type checkers fall back to the runtime package.
+func addStubPackage(files map[string]string, packageName string) {
+	root := path.Join("..", packageName+"-stubs")
+	stubs := map[string]string{
+		path.Join(root, "__init__.pyi"): "",
+		path.Join(root, "py.typed"):     stubPackageMarker,
+	}
+	for relPath, content := range files {
+		if !strings.HasSuffix(relPath, ".py") {
+			continue
+		}
+		if stub, hasStub := files[relPath+"i"]; hasStub {
+			content = stub
+		}
+		stubs[path.Join(root, relPath+"i")] = content
+	}
+	for stubPath, content := range stubs {
+		files[stubPath] = content
+	}
+}
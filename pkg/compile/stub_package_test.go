@@ -0,0 +1,81 @@
+package compile_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) TestMorpheToPydantic_StubPackageLayout() {
+	outputDirPath := filepath.Join(suite.T().TempDir(), "people")
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), outputDirPath)
+	config.FormatConfig.GenerateStubPackage = true
+
+	suite.Require().NoError(compile.MorpheToPydantic(config))
+
+	stubDirPath := filepath.Join(filepath.Dir(outputDirPath), "people-stubs")
+	var stubFiles []string
+	suite.Require().NoError(filepath.Walk(stubDirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, relErr := filepath.Rel(stubDirPath, path)
+		stubFiles = append(stubFiles, filepath.ToSlash(relPath))
+		return relErr
+	}))
+	sort.Strings(stubFiles)
+	suite.Equal([]string{
+		"__init__.pyi",
+		"entities/__init__.pyi",
+		"entities/company.pyi",
+		"entities/person.pyi",
+		"enums/__init__.pyi",
+		"enums/nationality.pyi",
+		"enums/universal_number.pyi",
+		"models/__init__.pyi",
+		"models/company.pyi",
+		"models/contact_info.pyi",
+		"models/person.pyi",
+		"py.typed",
+		"structures/__init__.pyi",
+		"structures/address.pyi",
+	}, stubFiles)
+
+	marker, err := os.ReadFile(filepath.Join(stubDirPath, "py.typed"))
+	suite.Require().NoError(err)
+	suite.Equal("partial\n", string(marker))
+
+	module, err := os.ReadFile(filepath.Join(outputDirPath, "enums", "nationality.py"))
+	suite.Require().NoError(err)
+	stub, err := os.ReadFile(filepath.Join(stubDirPath, "enums", "nationality.pyi"))
+	suite.Require().NoError(err)
+	suite.Equal(string(module), string(stub))
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_StubPackagePrefersModelStubs() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.GenerateStubPackage = true
+	config.FormatConfig.StubPackageName = "people"
+	config.MorpheConfig.Models.GenerateStubs = true
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Require().Contains(files, "models/person.pyi")
+	suite.Equal(files["models/person.pyi"], files["../people-stubs/models/person.pyi"])
+	for relPath := range files {
+		if strings.HasPrefix(relPath, "../people-stubs/") {
+			suite.False(strings.HasSuffix(relPath, ".py"), relPath)
+		}
+	}
+}
+
+func (suite *CompileTestSuite) TestValidate_StubPackageNameRequired() {
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "minimal"), "")
+	config.FormatConfig.GenerateStubPackage = true
+
+	suite.EqualError(config.Validate(), "invalid stubPackageName: required when the output path has no directory name")
+}
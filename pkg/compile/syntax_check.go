@@ -0,0 +1,87 @@
+package compile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// pythonInterpreter is the interpreter the generated sources are checked with
+const pythonInterpreter = "python3"
+
+// syntaxCheckScript compiles every source read from stdin as a JSON list of [path, source]
+// pairs and prints the first syntax error of each failing file as path, line and message
+const syntaxCheckScript = `
+import json, sys
+for path, source in json.load(sys.stdin):
+    try:
+        compile(source, path, "exec", dont_inherit=True)
+    except SyntaxError as e:
+        print(json.dumps([path, e.lineno or 0, e.msg]))
+`
+
+// SyntaxCheckError is returned when a generated file isn't valid Python
+type SyntaxCheckError struct {
+	File    string // Path of the file relative to the output directory
+	Line    int
+	Message string
+}
+
+func (e *SyntaxCheckError) Error() string {
+	return fmt.Sprintf("invalid Python syntax in %s line %d: %s", e.File, e.Line, e.Message)
+}
+
+// CheckPythonSyntax compiles the generated Python sources and stubs with python3, returning a
+// SyntaxCheckError for the first failing file in path order. When no python3 interpreter is
+// found the check is skipped with a warning written to warnings.
+func CheckPythonSyntax(files map[string]string, warnings io.Writer) error {
+	interpreter, err := exec.LookPath(pythonInterpreter)
+	if err != nil {
+		fmt.Fprintf(warnings, "Warning: %s not found, skipping the Python syntax check\n", pythonInterpreter)
+		return nil
+	}
+
+	var paths []string
+	for path := range files {
+		if strings.HasSuffix(path, ".py") || strings.HasSuffix(path, ".pyi") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	sources := make([][2]string, 0, len(paths))
+	for _, path := range paths {
+		sources = append(sources, [2]string{path, files[path]})
+	}
+	input, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(interpreter, "-c", syntaxCheckScript)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to run the Python syntax check: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var result []any
+		if err := json.Unmarshal([]byte(line), &result); err != nil || len(result) != 3 {
+			return fmt.Errorf("unexpected Python syntax check output: %s", line)
+		}
+		file, _ := result[0].(string)
+		lineNumber, _ := result[1].(float64)
+		message, _ := result[2].(string)
+		return &SyntaxCheckError{File: file, Line: int(lineNumber), Message: message}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package compile_test
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/compile"
+)
+
+func (suite *CompileTestSuite) requirePython() {
+	if _, err := exec.LookPath("python3"); err != nil {
+		suite.T().Skip("python3 not found")
+	}
+}
+
+func (suite *CompileTestSuite) TestCompileToMemory_ValidateSyntax() {
+	suite.requirePython()
+	config := compile.DefaultMorpheCompileConfig(filepath.Join(suite.TestDirPath, "registry", "polymorphic"), "")
+	config.FormatConfig.ValidateSyntax = true
+	config.MorpheConfig.Models.GenerateStubs = true
+
+	files, err := compile.CompileToMemory(config)
+
+	suite.Require().NoError(err)
+	suite.Contains(files, "models/__init__.py")
+}
+
+func (suite *CompileTestSuite) TestCheckPythonSyntax_Error() {
+	suite.requirePython()
+	files := map[string]string{
+		"models/order.py":  "from ..enums.status import Status\n\n\nclass Order(BaseModel):\n    status: Status =\n",
+		"models/person.py": "class Person(BaseModel:\n    pass\n",
+		"graph.dot":        "digraph {\n",
+	}
+
+	err := compile.CheckPythonSyntax(files, io.Discard)
+
+	var syntaxErr *compile.SyntaxCheckError
+	suite.Require().ErrorAs(err, &syntaxErr)
+	suite.Equal("models/order.py", syntaxErr.File)
+	suite.Equal(5, syntaxErr.Line)
+	suite.ErrorContains(err, "invalid Python syntax in models/order.py line 5")
+}
+
+func (suite *CompileTestSuite) TestCheckPythonSyntax_NoInterpreter() {
+	suite.T().Setenv("PATH", "")
+	var warnings bytes.Buffer
+
+	suite.NoError(compile.CheckPythonSyntax(map[string]string{"models/person.py": "class Person(\n"}, &warnings))
+	suite.Equal("Warning: python3 not found, skipping the Python syntax check\n", warnings.String())
+}
@@ -0,0 +1,97 @@
+package compile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// typeAlias is a module-level TypeAlias declaration hoisted out of the field declarations
+type typeAlias struct {
+	Name       string
+	Expression string
+}
+
+// String renders the alias declaration
+func (alias typeAlias) String() string {
+	return fmt.Sprintf("%s: TypeAlias = %s", alias.Name, alias.Expression)
+}
+
+// hoistTypeAliases replaces the Union and Annotated expressions shared by several declarations
+// of a module with TypeAlias declarations, returned in order of first use. Aliases are named
+// after the first field using them: <Field>Ref for unions and <Field>Type for Annotated types.
+func hoistTypeAliases(decls []modelFieldDecl) []typeAlias {
+	counts := make(map[string]int)
+	for _, decl := range decls {
+		if expression := aliasableExpression(decl); expression != "" {
+			counts[expression]++
+		}
+	}
+
+	var aliases []typeAlias
+	aliasNames := make(map[string]string)
+	for i, decl := range decls {
+		expression := aliasableExpression(decl)
+		if counts[expression] < 2 {
+			continue
+		}
+		aliasName, hoisted := aliasNames[expression]
+		if !hoisted {
+			suffix := "Ref"
+			if len(decl.Metadata) > 0 {
+				suffix = "Type"
+			}
+			aliasName = formatdef.ToPascalCase(decl.Name) + suffix
+			aliasNames[expression] = aliasName
+			aliases = append(aliases, typeAlias{Name: aliasName, Expression: expression})
+		}
+		if len(decl.Metadata) > 0 {
+			decls[i].Annotation = aliasName
+			decls[i].Metadata = nil
+		} else {
+			decls[i].Annotation = strings.Replace(decl.Annotation, expression, aliasName, 1)
+		}
+	}
+	return aliases
+}
+
+// aliasableExpression returns the complex type expression of a declaration that may be hoisted
+// into an alias: its whole Annotated[...] type hint, or the first Union[...] of its annotation
+func aliasableExpression(decl modelFieldDecl) string {
+	if len(decl.Metadata) > 0 {
+		return decl.TypeHint()
+	}
+	return bracketedExpression(decl.Annotation, "Union[")
+}
+
+// bracketedExpression returns the first expression of s opened by prefix, up to its matching
+// closing bracket, or an empty string when there is none
+func bracketedExpression(s string, prefix string) string {
+	start := strings.Index(s, prefix)
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	for i := start + len(prefix) - 1; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return s[start : i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// addTypeAliasImport imports TypeAlias from typing, or from typing_extensions before Python 3.10
+func addTypeAliasImport(imports *ImportTracker, config PydanticConfig) {
+	if config.PythonVersionAtLeast(3, 10) {
+		imports.AddTyping("TypeAlias")
+	} else {
+		imports.AddFrom("typing_extensions", "TypeAlias")
+	}
+}
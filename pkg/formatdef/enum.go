@@ -6,6 +6,8 @@ type Enum struct {
 	Name    string
 	Type    Type // The underlying type (string, int, etc.)
 	Entries []EnumEntry
+	// Description becomes the class docstring (a generic one is used when empty)
+	Description string
 	// TODO: Add format-specific enum properties
 	// Examples:
 	// - IsConstEnum bool (for TypeScript)
@@ -17,13 +19,26 @@ type Enum struct {
 type EnumEntry struct {
 	Name  string
 	Value interface{}
+	// Comment is rendered as an inline comment after the member
+	Comment string
+	// Aliases are additional values that resolve to this member
+	Aliases []string
 	// TODO: Add format-specific entry properties
 	// Examples:
-	// - Comment string (for documentation)
 	// - Deprecated bool
 	// - Metadata map[string]interface{}
 }
 
+// HasAliases reports whether any entry has alias values
+func (e *Enum) HasAliases() bool {
+	for _, entry := range e.Entries {
+		if len(entry.Aliases) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDefinition returns the full enum definition in the target format
 func (e *Enum) GetDefinition() string {
 	// TODO: Implement format-specific enum syntax generation
@@ -7,9 +7,10 @@ import (
 
 // ContentBuilder helps generate formatted code with proper indentation
 type ContentBuilder struct {
-	lines       []string
-	indentLevel int
-	indentStr   string
+	lines         []string
+	indentLevel   int
+	indentStr     string
+	maxLineLength int
 }
 
 // NewContentBuilder creates a new content builder
@@ -35,6 +36,21 @@ func (b *ContentBuilder) Line(format string, args ...interface{}) *ContentBuilde
 	return b
 }
 
+// MaxLineLength sets the line length beyond which lines are wrapped (0 disables wrapping)
+func (b *ContentBuilder) MaxLineLength(length int) *ContentBuilder {
+	b.maxLineLength = length
+	return b
+}
+
+// LineWidth returns the room left for a line at the current indentation before it is wrapped
+// (0 when wrapping is disabled)
+func (b *ContentBuilder) LineWidth() int {
+	if b.maxLineLength <= 0 {
+		return 0
+	}
+	return b.maxLineLength - len(b.indentStr)*b.indentLevel
+}
+
 // Indent increases indentation level
 func (b *ContentBuilder) Indent() *ContentBuilder {
 	b.indentLevel++
@@ -74,14 +90,131 @@ func (b *ContentBuilder) AppendToLastLine(text string) *ContentBuilder {
 	return b
 }
 
-// Build returns the final content as a byte array
+// Build returns the final content as a byte array, formatted following Black's conventions
 func (b *ContentBuilder) Build() []byte {
-	return []byte(strings.Join(b.lines, "\n"))
+	return []byte(b.String())
 }
 
-// String returns the content as a string
+// String returns the content as a string, formatted following Black's conventions
 func (b *ContentBuilder) String() string {
-	return strings.Join(b.lines, "\n")
+	lines := wrapLongLines(normalizeBlankLines(b.lines), b.indentStr, b.maxLineLength)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// normalizeBlankLines applies Black's blank line conventions to generated Python lines:
+// trailing whitespace is stripped, top-level classes and functions are separated by exactly
+// two blank lines, nested ones by exactly one, no blank lines open a block, and other runs of
+// blank lines are capped at two (top level) or one (indented). Triple-quoted strings are kept verbatim.
+func normalizeBlankLines(lines []string) []string {
+	var result []string
+	blankCount := 0
+	inString := false
+	prevIndent := -1
+	prevLine := ""
+	// The first line of the most recent statement at each indent level
+	lastStatement := make(map[int]string)
+
+	for i, line := range lines {
+		if inString {
+			result = append(result, strings.TrimRight(line, " \t"))
+			if strings.Count(line, `"""`)%2 == 1 {
+				inString = false
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			blankCount++
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		required := blankCount
+		maxBlank := 1
+		if indent == 0 {
+			maxBlank = 2
+		}
+		if required > maxBlank {
+			required = maxBlank
+		}
+
+		switch {
+		case prevIndent < 0:
+			// Nothing precedes the first line
+			required = 0
+		case indent > prevIndent && strings.HasSuffix(strings.TrimSpace(prevLine), ":"):
+			// No blank lines at the start of a block
+			required = 0
+		case isDecorator(strings.TrimSpace(prevLine)) && indent == prevIndent:
+			// Decorators stay attached to their definition
+			required = 0
+		case strings.HasPrefix(strings.TrimSpace(prevLine), "#") && indent == prevIndent && blankCount == 0 && isDefinitionStart(lines, i):
+			// Comments stay attached to the definition they describe
+			required = 0
+		case isDefinitionStart(lines, i):
+			required = maxBlank
+		case prevIndent > indent && isDefinition(lastStatement[indent]):
+			// Statement following the body of a class or function
+			required = maxBlank
+		}
+
+		for j := 0; j < required; j++ {
+			result = append(result, "")
+		}
+		result = append(result, strings.TrimRight(line, " \t"))
+
+		// Track the statement started at this indent level and drop deeper ones
+		if !isDecorator(strings.TrimSpace(prevLine)) || indent != prevIndent {
+			lastStatement[indent] = trimmed
+		}
+		for level := range lastStatement {
+			if level > indent {
+				delete(lastStatement, level)
+			}
+		}
+
+		if strings.Count(line, `"""`)%2 == 1 {
+			inString = true
+		}
+		blankCount = 0
+		prevIndent = indent
+		prevLine = line
+	}
+
+	return result
+}
+
+// isDefinition reports whether a stripped line opens a class or function (including decorators)
+func isDefinition(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "class ") ||
+		strings.HasPrefix(trimmed, "def ") ||
+		strings.HasPrefix(trimmed, "async def ") ||
+		isDecorator(trimmed)
+}
+
+// isDecorator reports whether a stripped line is a decorator
+func isDecorator(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "@")
+}
+
+// isDefinitionStart reports whether the line at index i starts a class or function definition,
+// treating comment lines directly attached to the definition as its start
+func isDefinitionStart(lines []string, i int) bool {
+	for j := i; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" {
+			return false
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return isDefinition(trimmed)
+	}
+	return false
 }
 
 // Common formatting helpers
@@ -0,0 +1,165 @@
+package formatdef_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+func TestContentBuilder_BlackBlankLines(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ")
+	cb.Line("from enum import Enum")
+	cb.Line("")
+	cb.Line("class Color(Enum):  ")
+	cb.Indent()
+	cb.Line("")
+	cb.Line(`"""Color enumeration."""`)
+	cb.Line("RED = 1")
+	cb.Line("@classmethod")
+	cb.Line("def first(cls):")
+	cb.Indent()
+	cb.Line("return cls.RED")
+	cb.Dedent()
+	cb.Line("")
+	cb.Line("")
+	cb.Line("")
+	cb.Line("label = None")
+	cb.Dedent()
+	cb.Line("Color.first()")
+
+	expected := `from enum import Enum
+
+
+class Color(Enum):
+    """Color enumeration."""
+    RED = 1
+
+    @classmethod
+    def first(cls):
+        return cls.RED
+
+    label = None
+
+
+Color.first()
+`
+	assert.Equal(t, expected, cb.String())
+}
+
+func TestContentBuilder_KeepsDocstringBlankLines(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ")
+	cb.Line("import os")
+	cb.Line("# Leading comment")
+	cb.Line("class Person:")
+	cb.Indent()
+	cb.BlockComment("Person entity.", "", "Identifiers: 1")
+	cb.Line("# primary identifier")
+	cb.Line("id_: int")
+	cb.Line("# loader")
+	cb.Line("def load(self):")
+	cb.Indent()
+	cb.Line("pass")
+
+	expected := `import os
+
+
+# Leading comment
+class Person:
+    """
+    Person entity.
+
+    Identifiers: 1
+    """
+    # primary identifier
+    id_: int
+
+    # loader
+    def load(self):
+        pass
+`
+	assert.Equal(t, expected, cb.String())
+}
+
+func TestContentBuilder_EmptyBuild(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ")
+	assert.Equal(t, "", cb.String())
+	assert.Empty(t, cb.Build())
+}
+
+func TestContentBuilder_LineWidth(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(88)
+	assert.Equal(t, 88, cb.LineWidth())
+	cb.Indent().Indent()
+	assert.Equal(t, 80, cb.LineWidth())
+	assert.Equal(t, 0, formatdef.NewContentBuilder("    ").LineWidth())
+}
+
+func TestContentBuilder_WrapsLongLineWithHangingIndent(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(40)
+	cb.Line("class Order(BaseModel):")
+	cb.Indent()
+	cb.Line(`item: Union["Product", "Service"] = None`)
+	cb.Line(`owner: Optional[Union["Customer", "Vendor"]]`)
+
+	expected := `class Order(BaseModel):
+    item: Union[
+        "Product", "Service"
+    ] = None
+    owner: Optional[
+        Union["Customer", "Vendor"]
+    ]
+`
+	assert.Equal(t, expected, cb.String())
+}
+
+func TestContentBuilder_WrapsDefaultedAnnotation(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(88)
+	cb.Line("class User(BaseModel):")
+	cb.Indent()
+	cb.Line(`notes: Optional[List[Annotated[Union["Order", "Invoice"], Field(discriminator="kind")]]] = None`)
+
+	expected := `class User(BaseModel):
+    notes: Optional[
+        List[Annotated[Union["Order", "Invoice"], Field(discriminator="kind")]]
+    ] = None
+`
+	assert.Equal(t, expected, cb.String())
+}
+
+func TestContentBuilder_ExplodesLongCallArguments(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(50)
+	cb.Line("class Order(BaseModel):")
+	cb.Indent()
+	cb.Line(`note: str = Field(default="a, b", description="Free text note", max_length=255)`)
+
+	expected := `class Order(BaseModel):
+    note: str = Field(
+        default="a, b",
+        description="Free text note",
+        max_length=255,
+    )
+`
+	assert.Equal(t, expected, cb.String())
+}
+
+func TestContentBuilder_LeavesUnsplittableLines(t *testing.T) {
+	long := `# a comment that is far longer than the configured limit`
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(20)
+	cb.Line(long)
+	cb.Line(`DESCRIPTION = "a string that cannot be split"`)
+
+	assert.Equal(t, long+"\n"+`DESCRIPTION = "a string that cannot be split"`+"\n", cb.String())
+}
+
+func TestContentBuilder_WrapsFunctionSignature(t *testing.T) {
+	cb := formatdef.NewContentBuilder("    ").MaxLineLength(40)
+	cb.Line("def __init__(self, *, name: str, age: int) -> None: ...")
+
+	expected := `def __init__(
+    self, *, name: str, age: int
+) -> None: ...
+`
+	assert.Equal(t, expected, cb.String())
+}
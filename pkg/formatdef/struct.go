@@ -16,9 +16,34 @@ type Struct struct {
 
 // Field represents a field in a structure
 type Field struct {
-	Name       string
-	Type       Type
-	IsOptional bool // When true, generates Optional[T] = None in Python
+	Name        string
+	Type        Type
+	IsOptional  bool              // When true, generates Optional[T] = None in Python
+	IsComputed  bool              // Derived value excluded from the constructor (dataclass field(init=False))
+	IsGenerated bool              // Value assigned by the database (auto-increment, sequence or identity ids)
+	IsIdentity  bool              // Primary key or foreign key field
+	IsClassVar  bool              // When true, generates ClassVar[T] instead of an instance field
+	IsRoot      bool              // Sole field of a collection structure, generated as a RootModel
+	Default     string            // Rendered Python default value expression (empty when none)
+	Examples    []string          // Rendered Python example value expressions for Field(examples=...)
+	Pattern     string            // Regular expression the value must match (string fields only)
+	Bounds      map[string]string // Numeric bound keyword (gt, ge, lt or le) to its rendered limit
+	Description string            // Field description for the generated JSON schema
+	HideRepr    bool              // Left out of the generated __repr__ (Field(repr=False))
+	Exclude     bool              // Left out of serialization (Field(exclude=True))
+	IsFrozen    bool              // Immutable once the model is constructed (Field(frozen=True))
+	Expression  string            // Expression a computed entity field is derived from (empty when none)
+	Together    []string          // Groups whose fields must be provided all together or not at all
+	OneOf       []string          // Groups of which exactly one field must be provided
+	IsImplicit  bool              // Added by the compiler for a relationship rather than declared in Morphe
+	PolyNav     string            // Navigation field whose target a polymorphic type field names (empty when none)
+}
+
+// UseBuiltinGenerics switches every field type to the lowercase builtin generics (Python 3.9+)
+func (s *Struct) UseBuiltinGenerics() {
+	for i := range s.Fields {
+		s.Fields[i].Type = WithBuiltinGenerics(s.Fields[i].Type)
+	}
 }
 
 // GetDefinition returns the full struct definition in the target format
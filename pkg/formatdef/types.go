@@ -1,5 +1,10 @@
 package formatdef
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Type represents a type in the target format
 // TODO: Replace with your target format's type system
 type Type interface {
@@ -27,11 +32,18 @@ func (t BasicType) IsNullable() bool {
 // ArrayType represents an array/list type
 type ArrayType struct {
 	ElementType Type
-	// TODO: Add format-specific array properties
+	Builtin     bool // When true, renders the builtin list[T] generic (Python 3.9+)
+	Sequence    bool // When true, renders the read-only Sequence[T] annotation instead of a list
 }
 
 func (t ArrayType) GetName() string {
 	// Python list syntax
+	if t.Sequence {
+		return "Sequence[" + t.ElementType.GetName() + "]"
+	}
+	if t.Builtin {
+		return "list[" + t.ElementType.GetName() + "]"
+	}
 	return "List[" + t.ElementType.GetName() + "]"
 }
 
@@ -39,13 +51,87 @@ func (t ArrayType) IsNullable() bool {
 	return false
 }
 
+// DictType represents a mapping type
+type DictType struct {
+	KeyType   Type
+	ValueType Type
+	Builtin   bool // When true, renders the builtin dict[K, V] generic (Python 3.9+)
+}
+
+func (t DictType) GetName() string {
+	// Python dict syntax
+	name := "Dict["
+	if t.Builtin {
+		name = "dict["
+	}
+	return name + t.KeyType.GetName() + ", " + t.ValueType.GetName() + "]"
+}
+
+func (t DictType) IsNullable() bool {
+	return false
+}
+
+// LiteralType represents a fixed set of string values
+type LiteralType struct {
+	Values []string
+}
+
+func (t LiteralType) GetName() string {
+	quoted := make([]string, len(t.Values))
+	for i, value := range t.Values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return "Literal[" + strings.Join(quoted, ", ") + "]"
+}
+
+func (t LiteralType) IsNullable() bool {
+	return false
+}
+
+// AnnotatedType represents a type carrying validation metadata, such as a union discriminator
+type AnnotatedType struct {
+	Type     Type
+	Metadata []string // Rendered metadata expressions, e.g. Field(discriminator="kind")
+}
+
+func (t AnnotatedType) GetName() string {
+	return "Annotated[" + t.Type.GetName() + ", " + strings.Join(t.Metadata, ", ") + "]"
+}
+
+func (t AnnotatedType) IsNullable() bool {
+	return t.Type.IsNullable()
+}
+
+// WithBuiltinGenerics returns the type with every list and dict generic, including nested
+// ones, rendered using the lowercase builtins instead of the typing aliases
+func WithBuiltinGenerics(t Type) Type {
+	switch typed := t.(type) {
+	case ArrayType:
+		return ArrayType{ElementType: WithBuiltinGenerics(typed.ElementType), Builtin: true, Sequence: typed.Sequence}
+	case DictType:
+		return DictType{
+			KeyType:   WithBuiltinGenerics(typed.KeyType),
+			ValueType: WithBuiltinGenerics(typed.ValueType),
+			Builtin:   true,
+		}
+	case AnnotatedType:
+		return AnnotatedType{Type: WithBuiltinGenerics(typed.Type), Metadata: typed.Metadata}
+	default:
+		return t
+	}
+}
+
 // Python basic types
 var (
-	TypeString  = BasicType{Name: "str"}
-	TypeInteger = BasicType{Name: "int"}
-	TypeFloat   = BasicType{Name: "float"}
-	TypeBoolean = BasicType{Name: "bool"}
-	TypeDate    = BasicType{Name: "datetime"}
-	TypeJSON    = BasicType{Name: "Dict[str, Any]"}
-	TypeAny     = BasicType{Name: "Any"}
+	TypeString        = BasicType{Name: "str"}
+	TypeInteger       = BasicType{Name: "int"}
+	TypeFloat         = BasicType{Name: "float"}
+	TypeBoolean       = BasicType{Name: "bool"}
+	TypeDate          = BasicType{Name: "date"} // Date only; use TypeDateTime for a timestamp
+	TypeTime          = BasicType{Name: "time"} // Time of day only
+	TypeDateTime      = BasicType{Name: "datetime"}
+	TypeJSON          = DictType{KeyType: TypeString, ValueType: TypeAny}
+	TypeJSONValue     = BasicType{Name: "JsonValue"}     // Pydantic v2's recursive JSON type
+	TypeAwareDatetime = BasicType{Name: "AwareDatetime"} // Pydantic v2's datetime requiring a timezone
+	TypeAny           = BasicType{Name: "Any"}
 )
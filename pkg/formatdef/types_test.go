@@ -0,0 +1,47 @@
+package formatdef_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+func TestTypes_TypingGenerics(t *testing.T) {
+	listType := formatdef.ArrayType{ElementType: formatdef.TypeString}
+
+	assert.Equal(t, "List[str]", listType.GetName())
+	assert.Equal(t, "Dict[str, Any]", formatdef.TypeJSON.GetName())
+}
+
+func TestTypes_BuiltinGenerics(t *testing.T) {
+	nested := formatdef.ArrayType{ElementType: formatdef.TypeJSON}
+
+	assert.Equal(t, "list[dict[str, Any]]", formatdef.WithBuiltinGenerics(nested).GetName())
+	assert.Equal(t, "str", formatdef.WithBuiltinGenerics(formatdef.TypeString).GetName())
+}
+
+func TestTypes_SequenceKeptWithBuiltinGenerics(t *testing.T) {
+	sequence := formatdef.ArrayType{ElementType: formatdef.TypeJSON, Sequence: true}
+
+	assert.Equal(t, "Sequence[Dict[str, Any]]", sequence.GetName())
+	assert.Equal(t, "Sequence[dict[str, Any]]", formatdef.WithBuiltinGenerics(sequence).GetName())
+}
+
+func TestTypes_Literal(t *testing.T) {
+	literal := formatdef.LiteralType{Values: []string{"draft", "sent", `say "hi"`}}
+
+	assert.Equal(t, `Literal["draft", "sent", "say \"hi\""]`, literal.GetName())
+}
+
+func TestTypes_Annotated(t *testing.T) {
+	annotated := formatdef.AnnotatedType{
+		Type:     formatdef.BasicType{Name: `Union["Post", "Photo"]`},
+		Metadata: []string{`Field(discriminator="kind")`},
+	}
+	list := formatdef.ArrayType{ElementType: annotated}
+
+	assert.Equal(t, `Annotated[Union["Post", "Photo"], Field(discriminator="kind")]`, annotated.GetName())
+	assert.Equal(t, `list[Annotated[Union["Post", "Photo"], Field(discriminator="kind")]]`, formatdef.WithBuiltinGenerics(list).GetName())
+}
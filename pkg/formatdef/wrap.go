@@ -0,0 +1,155 @@
+package formatdef
+
+import "strings"
+
+// wrapLongLines splits lines longer than maxLength at their trailing bracket pair, following
+// Black's right-hand split: the bracket contents move to a hanging indent, and if they still
+// do not fit they are exploded one element per line with a trailing comma. Comments and
+// triple-quoted strings are left untouched.
+func wrapLongLines(lines []string, indentStr string, maxLength int) []string {
+	if maxLength <= 0 {
+		return lines
+	}
+
+	var result []string
+	inString := false
+	for _, line := range lines {
+		quoteCount := strings.Count(line, `"""`)
+		if inString || quoteCount > 0 {
+			result = append(result, line)
+			if quoteCount%2 == 1 {
+				inString = !inString
+			}
+			continue
+		}
+		result = append(result, wrapLine(line, indentStr, maxLength)...)
+	}
+	return result
+}
+
+// wrapLine wraps a single line, returning it unchanged when it fits or cannot be split
+func wrapLine(line string, indentStr string, maxLength int) []string {
+	trimmed := strings.TrimSpace(line)
+	if len(line) <= maxLength || strings.HasPrefix(trimmed, "#") {
+		return []string{line}
+	}
+
+	openIdx, closeIdx := trailingBracketPair(line)
+	if openIdx < 0 {
+		return []string{line}
+	}
+	body := strings.TrimSpace(line[openIdx+1 : closeIdx])
+	if body == "" {
+		return []string{line}
+	}
+
+	lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	innerIndent := lineIndent + indentStr
+	head := line[:openIdx+1]
+	tail := lineIndent + line[closeIdx:]
+
+	items := splitTopLevel(body, ',')
+	hasTrailingComma := strings.HasSuffix(body, ",")
+
+	// Contents fit on a single hanging line
+	if !hasTrailingComma && len(innerIndent)+len(body) <= maxLength {
+		return []string{head, innerIndent + body, tail}
+	}
+
+	wrapped := []string{head}
+	if len(items) == 1 && !hasTrailingComma {
+		wrapped = append(wrapped, wrapLine(innerIndent+body, indentStr, maxLength)...)
+	} else {
+		for _, item := range items {
+			wrapped = append(wrapped, wrapLine(innerIndent+item+",", indentStr, maxLength)...)
+		}
+	}
+	return append(wrapped, tail)
+}
+
+// trailingBracketPair finds the last top-level bracket pair of a line when only a closing
+// ":" or ",", a function return annotation or a bracket-free "= <default>" follows it,
+// returning -1 indexes when no such pair exists
+func trailingBracketPair(line string) (int, int) {
+	var stack []int
+	var quote byte
+	openIdx, closeIdx := -1, -1
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#':
+			return -1, -1
+		case ch == '(' || ch == '[' || ch == '{':
+			stack = append(stack, i)
+		case ch == ')' || ch == ']' || ch == '}':
+			if len(stack) == 0 {
+				return -1, -1
+			}
+			start := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				openIdx, closeIdx = start, i
+			}
+		}
+	}
+
+	if closeIdx < 0 || len(stack) != 0 {
+		return -1, -1
+	}
+	tail := strings.TrimSpace(line[closeIdx+1:])
+	switch {
+	case tail == "", tail == ":", tail == ",":
+		return openIdx, closeIdx
+	case strings.HasPrefix(tail, "= "):
+		// Annotated assignment whose default has no brackets, e.g. "x: Optional[...] = None"
+		return openIdx, closeIdx
+	case strings.HasPrefix(tail, "->") && (strings.HasSuffix(tail, ":") || strings.HasSuffix(tail, ": ...")):
+		// Function signature with a return annotation
+		return openIdx, closeIdx
+	}
+	return -1, -1
+}
+
+// splitTopLevel splits s on sep wherever it is outside brackets and string literals,
+// trimming each part and dropping a trailing empty part
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		case ch == sep && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
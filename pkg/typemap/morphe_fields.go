@@ -1,11 +1,22 @@
 package typemap
 
 import (
+	"fmt"
+
 	"github.com/kalo-build/morphe-go/pkg/registry"
 	"github.com/kalo-build/morphe-go/pkg/yaml"
 	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
 )
 
+// ModelFieldTypeJSON is a field type specific to this plugin holding an arbitrary JSON value.
+// Morphe doesn't define it, so rather than being a built-in mapping it is registered like a
+// RegisterFieldType type, and UnregisterFieldType(ModelFieldTypeJSON) drops it.
+const ModelFieldTypeJSON yaml.ModelFieldType = "JSON"
+
+func init() {
+	RegisterFieldType(ModelFieldTypeJSON, formatdef.TypeJSON)
+}
+
 // MorpheModelFieldToFormatType maps Morphe field types to target format types
 // TODO: Rename this variable to match your format (e.g., MorpheModelFieldToPythonType)
 // TODO: Update the type mappings to match your target format's type system
@@ -25,7 +36,7 @@ var MorpheModelFieldToFormatType = map[yaml.ModelFieldType]formatdef.Type{
 	yaml.ModelFieldTypeBoolean: formatdef.TypeBoolean,
 
 	// Date/Time types
-	yaml.ModelFieldTypeTime: formatdef.TypeDate,
+	yaml.ModelFieldTypeTime: formatdef.TypeTime,
 	yaml.ModelFieldTypeDate: formatdef.TypeDate,
 
 	// TODO: Add mappings for any custom field types used in your Morphe schemas
@@ -36,14 +47,47 @@ func GetFieldType(fieldType yaml.ModelFieldType) formatdef.Type {
 	if formatType, exists := MorpheModelFieldToFormatType[fieldType]; exists {
 		return formatType
 	}
+	if formatType, exists := registeredFieldType(fieldType); exists {
+		return formatType
+	}
 	// Check if it's an enum type (custom type not in the predefined list)
 	// In Morphe, enum references are just the enum name
 	// For Python, we'll treat them as the enum type itself
 	return formatdef.BasicType{Name: string(fieldType)}
 }
 
-// MorpheStructureFieldToFormatType maps structure field types to format types
-func MorpheStructureFieldToFormatType(fieldType yaml.StructureFieldType, fieldName string, r *registry.Registry) (formatdef.Type, error) {
+// UnmappedTypeError is returned in strict mode when a field type has no known mapping
+type UnmappedTypeError struct {
+	Type string
+}
+
+func (e *UnmappedTypeError) Error() string {
+	return fmt.Sprintf("unmapped field type: %s", e.Type)
+}
+
+// GetFieldTypeStrict returns the format type for a Morphe field type, accepting only the
+// predefined and registered mappings and the enums and structures of the registry
+func GetFieldTypeStrict(fieldType yaml.ModelFieldType, r *registry.Registry) (formatdef.Type, error) {
+	if formatType, exists := MorpheModelFieldToFormatType[fieldType]; exists {
+		return formatType, nil
+	}
+	if formatType, exists := registeredFieldType(fieldType); exists {
+		return formatType, nil
+	}
+	if r != nil {
+		if _, err := r.GetEnum(string(fieldType)); err == nil {
+			return formatdef.BasicType{Name: string(fieldType)}, nil
+		}
+		if _, exists := r.GetAllStructures()[string(fieldType)]; exists {
+			return formatdef.BasicType{Name: string(fieldType)}, nil
+		}
+	}
+	return nil, &UnmappedTypeError{Type: string(fieldType)}
+}
+
+// MorpheStructureFieldToFormatType maps structure field types to format types. In strict mode
+// unknown types are an error instead of falling back to a same-named type.
+func MorpheStructureFieldToFormatType(fieldType yaml.StructureFieldType, fieldName string, r *registry.Registry, strict bool) (formatdef.Type, error) {
 	// Explicit structure composition: field type references another structure
 	if r != nil {
 		if _, exists := r.GetAllStructures()[string(fieldType)]; exists {
@@ -52,5 +96,8 @@ func MorpheStructureFieldToFormatType(fieldType yaml.StructureFieldType, fieldNa
 	}
 	// Structure fields use the same type mappings as model fields
 	modelFieldType := yaml.ModelFieldType(fieldType)
+	if strict {
+		return GetFieldTypeStrict(modelFieldType, r)
+	}
 	return GetFieldType(modelFieldType), nil
 }
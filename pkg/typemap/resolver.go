@@ -0,0 +1,102 @@
+package typemap
+
+import (
+	"sync"
+
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+)
+
+// Type resolution sources
+const (
+	SourceCustom     = "custom"     // Custom type mappings from the plugin config
+	SourceRegistered = "registered" // Types registered through RegisterFieldType
+	SourceBuiltin    = "builtin"    // The predefined MorpheModelFieldToFormatType mappings
+)
+
+// DefaultResolutionOrder consults custom mappings, then registered types, then the built-ins
+var DefaultResolutionOrder = []string{SourceCustom, SourceRegistered, SourceBuiltin}
+
+var (
+	registeredMu         sync.RWMutex
+	registeredFieldTypes = map[yaml.ModelFieldType]formatdef.Type{}
+)
+
+// RegisterFieldType registers the format type of a Morphe field type for programmatic users of
+// the compiler, replacing any earlier registration
+func RegisterFieldType(fieldType yaml.ModelFieldType, formatType formatdef.Type) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registeredFieldTypes[fieldType] = formatType
+}
+
+// UnregisterFieldType removes a field type registered with RegisterFieldType
+func UnregisterFieldType(fieldType yaml.ModelFieldType) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	delete(registeredFieldTypes, fieldType)
+}
+
+// registeredFieldType returns the registered format type of a Morphe field type
+func registeredFieldType(fieldType yaml.ModelFieldType) (formatdef.Type, bool) {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	formatType, exists := registeredFieldTypes[fieldType]
+	return formatType, exists
+}
+
+// Resolver looks a field type up in the resolution sources in order of precedence
+type Resolver struct {
+	Order  []string                               // Sources by precedence; omitted sources follow in the default order
+	Custom map[yaml.ModelFieldType]formatdef.Type // Custom type mappings
+}
+
+// IsResolutionSource reports whether a name is a known resolution source
+func IsResolutionSource(source string) bool {
+	for _, known := range DefaultResolutionOrder {
+		if source == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Sources returns the full resolution order: the configured sources followed by the omitted ones
+func (res Resolver) Sources() []string {
+	sources := append([]string{}, res.Order...)
+	for _, source := range DefaultResolutionOrder {
+		if !containsSource(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// Resolve returns the format type of a field type from the first source that knows it
+func (res Resolver) Resolve(fieldType yaml.ModelFieldType) (formatdef.Type, bool) {
+	for _, source := range res.Sources() {
+		var formatType formatdef.Type
+		var exists bool
+		switch source {
+		case SourceCustom:
+			formatType, exists = res.Custom[fieldType]
+		case SourceRegistered:
+			formatType, exists = registeredFieldType(fieldType)
+		case SourceBuiltin:
+			formatType, exists = MorpheModelFieldToFormatType[fieldType]
+		}
+		if exists {
+			return formatType, true
+		}
+	}
+	return nil, false
+}
+
+func containsSource(sources []string, source string) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package typemap_test
+
+import (
+	"testing"
+
+	"github.com/kalo-build/morphe-go/pkg/yaml"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/formatdef"
+	"github.com/kalo-build/plugin-morphe-pydantic-types/pkg/typemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_DefaultPrecedence(t *testing.T) {
+	typemap.RegisterFieldType("Money", formatdef.BasicType{Name: "Decimal"})
+	defer typemap.UnregisterFieldType("Money")
+	resolver := typemap.Resolver{Custom: map[yaml.ModelFieldType]formatdef.Type{
+		"Money":                   formatdef.BasicType{Name: "Money"},
+		yaml.ModelFieldTypeString: formatdef.BasicType{Name: "EmailStr"},
+	}}
+
+	money, resolved := resolver.Resolve("Money")
+	assert.True(t, resolved)
+	assert.Equal(t, "Money", money.GetName())
+
+	str, resolved := resolver.Resolve(yaml.ModelFieldTypeString)
+	assert.True(t, resolved)
+	assert.Equal(t, "EmailStr", str.GetName())
+}
+
+func TestResolver_ConfiguredPrecedence(t *testing.T) {
+	typemap.RegisterFieldType("Money", formatdef.BasicType{Name: "Decimal"})
+	defer typemap.UnregisterFieldType("Money")
+	resolver := typemap.Resolver{
+		Order: []string{typemap.SourceBuiltin, typemap.SourceRegistered},
+		Custom: map[yaml.ModelFieldType]formatdef.Type{
+			"Money":                   formatdef.BasicType{Name: "Money"},
+			yaml.ModelFieldTypeString: formatdef.BasicType{Name: "EmailStr"},
+		},
+	}
+
+	assert.Equal(t, []string{typemap.SourceBuiltin, typemap.SourceRegistered, typemap.SourceCustom}, resolver.Sources())
+
+	money, resolved := resolver.Resolve("Money")
+	assert.True(t, resolved)
+	assert.Equal(t, "Decimal", money.GetName())
+
+	str, resolved := resolver.Resolve(yaml.ModelFieldTypeString)
+	assert.True(t, resolved)
+	assert.Equal(t, "str", str.GetName())
+}
+
+func TestResolver_JSONIsRegistered(t *testing.T) {
+	_, builtin := typemap.MorpheModelFieldToFormatType[typemap.ModelFieldTypeJSON]
+	assert.False(t, builtin)
+
+	json, resolved := typemap.Resolver{Order: []string{typemap.SourceRegistered}}.Resolve(typemap.ModelFieldTypeJSON)
+	assert.True(t, resolved)
+	assert.Equal(t, formatdef.TypeJSON, json)
+	assert.Equal(t, formatdef.TypeJSON, typemap.GetFieldType(typemap.ModelFieldTypeJSON))
+}
+
+func TestResolver_Unresolved(t *testing.T) {
+	_, resolved := typemap.Resolver{}.Resolve("Money")
+
+	assert.False(t, resolved)
+}
@@ -1,12 +1,13 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from typing import TYPE_CHECKING, List, Optional
 
 from pydantic import BaseModel, Field
-from typing import List, Optional, TYPE_CHECKING
 
 if TYPE_CHECKING:
     from .person import Person
 
+
 class Company(BaseModel):
     """
     Company entity.
@@ -15,16 +16,16 @@ class Company(BaseModel):
     Relationships: 1
     """
     # primary identifier
-    id_: int
-    name: str
-    tax_id: Optional[str] = None
-    persons: List[Person] = None
+    id_: int = Field(alias="ID")
+    name: str = Field(alias="Name")
+    tax_id: str = Field(alias="TaxID")
+    persons: Optional[List["Person"]] = None
 
     def get_id(self) -> str:
         """Get the primary identifier."""
-        return self.id
+        return self.id_
 
-    async def load_persons(self) -> List['Person']:
+    async def load_persons(self) -> List["Person"]:
         """Load related Person entities."""
         # TODO: Implement lazy loading
         return []
@@ -32,4 +33,5 @@ class Company(BaseModel):
     model_config = {
         "validate_assignment": True,
         "arbitrary_types_allowed": True,
-    }
\ No newline at end of file
+        "populate_by_name": True,
+    }
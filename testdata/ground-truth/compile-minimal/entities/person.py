@@ -1,13 +1,15 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from typing import TYPE_CHECKING, List, Optional
 
 from pydantic import BaseModel, Field
-from typing import List, Optional, TYPE_CHECKING
+
 from ..enums.nationality import Nationality
 
 if TYPE_CHECKING:
     from .company import Company
 
+
 class Person(BaseModel):
     """
     Person entity.
@@ -15,19 +17,19 @@ class Person(BaseModel):
     Identifiers: 1
     Relationships: 1
     """
-    email: str
+    email: str = Field(alias="Email")
     # primary identifier
-    id_: int
-    last_name: str
-    nationality: Nationality
+    id_: int = Field(alias="ID")
+    last_name: str = Field(alias="LastName")
+    nationality: Nationality = Field(alias="Nationality")
     company_id: Optional[str] = None
-    company: Company
+    company: Optional["Company"] = None
 
     def get_id(self) -> str:
         """Get the primary identifier."""
-        return self.id
+        return self.id_
 
-    async def load_company(self) -> Optional['Company']:
+    async def load_company(self) -> Optional["Company"]:
         """Load related Company entity."""
         # TODO: Implement lazy loading
         return None
@@ -35,4 +37,5 @@ class Person(BaseModel):
     model_config = {
         "validate_assignment": True,
         "arbitrary_types_allowed": True,
-    }
\ No newline at end of file
+        "populate_by_name": True,
+    }
@@ -1,15 +1,20 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel
-from typing import List, Optional, TYPE_CHECKING
+from typing import TYPE_CHECKING, List, Optional
+
+from pydantic import BaseModel, Field
 
 if TYPE_CHECKING:
     from .person import Person
 
+
 class Company(BaseModel):
     """Company model."""
-    id_: int
-    name: str
-    tax_id: Optional[str] = None
-    person: Optional[List[Person]] = None
\ No newline at end of file
+    id_: int = Field(alias="ID")
+    name: str = Field(alias="Name")
+    tax_id: str = Field(alias="TaxID")
+    person: Optional[List["Person"]] = None
+
+    model_config = {
+        "populate_by_name": True,
+    }
@@ -1,15 +1,20 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel
-from typing import Optional, TYPE_CHECKING
+from typing import TYPE_CHECKING, Optional
+
+from pydantic import BaseModel, Field
 
 if TYPE_CHECKING:
     from .person import Person
 
+
 class ContactInfo(BaseModel):
     """ContactInfo model."""
-    email: str
-    id_: int
+    email: str = Field(alias="Email")
+    id_: int = Field(alias="ID")
     person_id: Optional[str] = None
-    person: Optional['Person'] = None
\ No newline at end of file
+    person: Optional["Person"] = None
+
+    model_config = {
+        "populate_by_name": True,
+    }
@@ -1,25 +1,28 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
+
+from typing import TYPE_CHECKING, Optional
+
+from pydantic import BaseModel, Field
 
-from pydantic import BaseModel
-from typing import Optional, TYPE_CHECKING
 from ..enums.nationality import Nationality
 
 if TYPE_CHECKING:
     from .company import Company
     from .contact_info import ContactInfo
 
+
 class Person(BaseModel):
     """Person model."""
-    first_name: str
-    id_: int
-    last_name: str
-    nationality: Nationality
+    first_name: str = Field(alias="FirstName")
+    id_: int = Field(alias="ID")
+    last_name: str = Field(alias="LastName")
+    nationality: Nationality = Field(alias="Nationality")
     company_id: Optional[str] = None
-    company: Optional['Company'] = None
-    contact_info: Optional['ContactInfo'] = None
+    company: Optional["Company"] = None
+    contact_info: Optional["ContactInfo"] = None
 
     model_config = {
         "validate_assignment": True,
         "use_enum_values": True,
-    }
\ No newline at end of file
+        "populate_by_name": True,
+    }
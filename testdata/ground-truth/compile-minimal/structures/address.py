@@ -1,13 +1,17 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel, Field
 from typing import Optional
 
+from pydantic import BaseModel, Field
+
 
 class Address(BaseModel):
     """Address data transfer object."""
-    city: str
-    house_nr: str
-    street: str
-    zip_code: str
\ No newline at end of file
+    city: str = Field(alias="City")
+    house_nr: str = Field(alias="HouseNr")
+    street: str = Field(alias="Street")
+    zip_code: str = Field(alias="ZipCode")
+
+    model_config = {
+        "populate_by_name": True,
+    }
@@ -1,17 +1,22 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel
-from typing import Optional, Union, TYPE_CHECKING
+from typing import TYPE_CHECKING, Literal, Optional, Union
+
+from pydantic import BaseModel, Field
 
 if TYPE_CHECKING:
     from .company import Company
     from .person import Person
 
+
 class Comment(BaseModel):
     """Comment model."""
-    content: str
-    id: int
-    commentable_type: Optional[str] = None
+    content: str = Field(alias="Content")
+    id_: int = Field(alias="ID")
+    commentable_type: Optional[Literal["Person", "Company"]] = None
     commentable_id: Optional[str] = None
-    commentable: Optional[Union['Person', 'Company']] = None
\ No newline at end of file
+    commentable: Optional[Union["Person", "Company"]] = None
+
+    model_config = {
+        "populate_by_name": True,
+    }
@@ -1,14 +1,19 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel
-from typing import Optional, List, TYPE_CHECKING
+from typing import TYPE_CHECKING, List, Optional
+
+from pydantic import BaseModel, Field
 
 if TYPE_CHECKING:
     from .comment import Comment
 
+
 class Company(BaseModel):
     """Company model."""
-    id: int
-    name: str
-    comments: Optional[List[Comment]] = None
\ No newline at end of file
+    id_: int = Field(alias="ID")
+    name: str = Field(alias="Name")
+    comments: Optional[List["Comment"]] = None
+
+    model_config = {
+        "populate_by_name": True,
+    }
@@ -1,21 +1,21 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel
-from typing import Optional, TYPE_CHECKING
+from typing import TYPE_CHECKING, Optional
+
+from pydantic import BaseModel, Field
 
 if TYPE_CHECKING:
     from .person import Person
 
+
 class Contact(BaseModel):
     """Contact model."""
-    email: str
-    id: int
-    phone: str
+    email: str = Field(alias="Email")
+    id_: int = Field(alias="ID")
+    phone: str = Field(alias="Phone")
     person_id: Optional[str] = None
-    person: Optional['Person'] = None
+    person: Optional["Person"] = None
 
     model_config = {
-        "validate_assignment": True,
-        "use_enum_values": True,
-    }
\ No newline at end of file
+        "populate_by_name": True,
+    }
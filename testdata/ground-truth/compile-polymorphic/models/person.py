@@ -1,21 +1,21 @@
-# Code generated by Morphe
-# Source: Morphe Registry
+# Auto-generated by plugin-morphe-pydantic-types; do not edit.
 
-from pydantic import BaseModel
-from typing import Optional, List, TYPE_CHECKING
+from typing import TYPE_CHECKING, List, Optional
+
+from pydantic import BaseModel, Field
 
 if TYPE_CHECKING:
     from .comment import Comment
     from .contact import Contact
 
+
 class Person(BaseModel):
     """Person model."""
-    id: int
-    name: str
-    comments: Optional[List[Comment]] = None
-    contact_info: Optional['Contact'] = None
+    id_: int = Field(alias="ID")
+    name: str = Field(alias="Name")
+    comments: Optional[List["Comment"]] = None
+    contact_info: Optional["Contact"] = None
 
     model_config = {
-        "validate_assignment": True,
-        "use_enum_values": True,
-    }
\ No newline at end of file
+        "populate_by_name": True,
+    }
@@ -0,0 +1,14 @@
+name: Company
+fields:
+  ID:
+    type: AutoIncrement
+  Name:
+    type: String
+  TaxID:
+    type: String
+identifiers:
+  primary: ID
+  name: Name
+related:
+  Person:
+    type: HasMany
\ No newline at end of file
@@ -0,0 +1,12 @@
+name: ContactInfo
+fields:
+  ID:
+    type: AutoIncrement
+  Email:
+    type: String
+identifiers:
+  primary: ID
+  email: Email
+related:
+  Person:
+    type: ForOne
\ No newline at end of file
@@ -0,0 +1,20 @@
+name: Person
+fields:
+  ID:
+    type: AutoIncrement
+  FirstName:
+    type: String
+  LastName:
+    type: String
+  Nationality:
+    type: Nationality
+identifiers:
+  primary: ID
+  name:
+    - FirstName
+    - LastName
+related:
+  ContactInfo:
+    type: HasOne
+  Company:
+    type: ForOne
\ No newline at end of file